@@ -1,11 +1,23 @@
 package app
 
 import (
+	auditController "backend/controllers/audit"
 	"backend/controllers/courses"
 	"backend/controllers/users"
 	"backend/dao"
+	"backend/domain"
+	"backend/middleware"
+	"backend/services/audit"
 	coursesService "backend/services/courses"
+	"backend/services/mail"
+	"backend/services/passwords"
+	"backend/services/search"
+	"backend/services/signing"
+	"backend/services/throttle"
 	usersService "backend/services/users"
+	"context"
+	"fmt"
+	"os"
 
 	"github.com/gin-gonic/gin"
 )
@@ -13,20 +25,58 @@ import (
 func MapRoutes(engine *gin.Engine) {
 	//funcion que levanta toda la aplicacion
 
-	// Servir archivos estáticos desde la carpeta uploads
-	engine.Static("/uploads", "./uploads")
-
 	// Crear repositorios
 	userRepo := dao.NewUserRepository()
 	courseRepo := dao.NewCourseRepository()
+	outboxRepo := dao.NewOutboxRepository()
+	auditRepo := dao.NewAuditRepository()
+
+	// Hasher de contraseñas configurable por variables de entorno
+	passwordHasher, err := passwords.NewHasher(passwords.ConfigFromEnv())
+	if err != nil {
+		panic(fmt.Errorf("error configuring password hasher: %v", err))
+	}
+
+	// Claves de firma de JWT (JWT_KEYS_FILE o JWT_SECRET)
+	tokenSigner, err := signing.LoadFromEnv()
+	if err != nil {
+		panic(fmt.Errorf("error loading JWT signing keys: %v", err))
+	}
 
 	// Crear servicios con inyección de dependencias
-	userService := usersService.NewUserService(userRepo)
-	courseService := coursesService.NewCourseService(courseRepo)
+	auditService := audit.NewAuditService(auditRepo)
+	userService := usersService.NewUserService(userRepo,
+		usersService.WithPasswordHasher(passwordHasher),
+		usersService.WithTokenSigner(tokenSigner),
+		usersService.WithTokenConfig(usersService.TokenConfigFromEnv()),
+		usersService.WithMailer(mail.NewMailerFromEnv(outboxRepo)),
+		usersService.WithAppBaseURL(getEnv("APP_BASE_URL", "http://localhost:3000")),
+		usersService.WithAPIBaseURL(getEnv("API_BASE_URL", "http://localhost:8080")),
+		usersService.WithVerificationPolicy(usersService.VerificationPolicyFromEnv()),
+		usersService.WithAdmin2FARequired(usersService.Admin2FARequiredFromEnv()),
+		usersService.WithLoginLimiter(throttle.NewLoginLimiter(throttle.NewMemoryStore(), throttle.ConfigFromEnv())),
+		usersService.WithAuditRecorder(auditService),
+	)
+	courseService := coursesService.NewCourseService(courseRepo,
+		coursesService.WithAuditRecorder(auditService),
+		coursesService.WithSearchIndex(search.NewCourseIndex()),
+	)
+
+	// El índice de búsqueda vive en memoria y se carga desde la base al arrancar
+	if err := courseService.RebuildSearchIndex(); err != nil {
+		panic(fmt.Errorf("error building course search index: %v", err))
+	}
+
+	// Los cursos borrados se purgan definitivamente cuando vence la retención de la papelera
+	courseService.StartTrashRetention(context.Background(), coursesService.TrashRetentionFromEnv(), coursesService.TrashPurgeInterval)
 
 	// Crear controladores con inyección de dependencias
 	userController := users.NewUserController(userService)
 	courseController := courses.NewCourseController(courseService)
+	auditLogController := auditController.NewAuditController(auditService)
+
+	// Middleware de autenticación basado en el JWT emitido por el servicio de usuarios
+	authMiddleware := middleware.NewAuthMiddleware(userService)
 
 	// Health check endpoint
 	engine.GET("/health", func(c *gin.Context) {
@@ -36,25 +86,101 @@ func MapRoutes(engine *gin.Engine) {
 		})
 	})
 
-	// Rutas de usuarios
+	// Claves públicas para que otros servicios verifiquen los tokens de EMARVE
+	engine.GET("/.well-known/jwks.json", userController.JWKS)
+
+	// Rutas públicas de usuarios
 	engine.POST("/users/login", userController.Login)
+	engine.POST("/users/login/2fa", userController.CompleteMFALogin)
+	engine.POST("/users/login/2fa/enroll", userController.BeginMFAEnrollment)
 	engine.POST("/users/register", userController.UserRegister)
-	engine.GET("/users/subscriptions/:id", userController.SubscriptionList)
-	engine.POST("/users/comments", userController.AddComment)
-	engine.POST("/upload", userController.UploadFiles)
+	engine.POST("/users/refresh", userController.RefreshToken)
+	engine.POST("/users/password/forgot", userController.ForgotPassword)
+	engine.POST("/users/password/reset", userController.ResetPassword)
+	engine.GET("/users/verify", userController.VerifyEmail)
+	engine.GET("/users/email/confirm", userController.ConfirmEmailChange)
 	engine.GET("/users/authentication", userController.UserAuthentication)
 	engine.GET("/users/userId", userController.GetUserID)
-	engine.GET("/users/:id", userController.GetUserById)
-
-	// Rutas de cursos
-	engine.GET("/courses/search", courseController.SearchCourse)
-	engine.GET("/courses", courseController.GetAllCourses)
-	engine.GET("/courses/instructor/:instructor", courseController.GetCoursesByInstructor)
-	engine.GET("/courses/comments/:id", courseController.CommentList)
-	engine.GET("/courses/images/:id", courseController.GetCourseImages)
-	engine.GET("/courses/:id", courseController.GetCourse)
-	engine.POST("/subscriptions", courseController.Subscription)
-	engine.POST("/courses/create", courseController.CreateCourse)
-	engine.PUT("/courses/update/:id", courseController.UpdateCourse)
-	engine.DELETE("/courses/delete/:id", courseController.DeleteCourse)
+
+	// Rutas públicas de cursos. Muestran solo cursos publicados, con sus comentarios y su staff,
+	// salvo al staff de cada curso y a los admins, que se identifican con el token si lo mandan.
+	engine.GET("/courses/search", authMiddleware.OptionalAuthenticate(), courseController.SearchCourse)
+	engine.GET("/courses/suggest", authMiddleware.OptionalAuthenticate(), courseController.SuggestCourses)
+	engine.GET("/courses", authMiddleware.OptionalAuthenticate(), courseController.GetAllCourses)
+	engine.GET("/courses/instructor/:instructor", authMiddleware.OptionalAuthenticate(), courseController.GetCoursesByInstructor)
+	engine.GET("/courses/comments/:id", authMiddleware.OptionalAuthenticate(), courseController.CommentList)
+	engine.GET("/courses/:id", authMiddleware.OptionalAuthenticate(), courseController.GetCourse)
+	engine.GET("/courses/:id/staff", authMiddleware.OptionalAuthenticate(), courseController.ListStaff)
+
+	// Rutas para cualquier usuario autenticado
+	authenticated := engine.Group("", authMiddleware.Authenticate())
+	authenticated.POST("/users/logout", userController.Logout)
+	authenticated.GET("/users/sessions", userController.ListSessions)
+	authenticated.DELETE("/users/sessions/:id", userController.RevokeSession)
+	authenticated.POST("/users/2fa/enroll", userController.BeginTOTPEnrollment)
+	authenticated.POST("/users/2fa/confirm", userController.ConfirmTOTPEnrollment)
+	authenticated.DELETE("/users/2fa", userController.DisableTOTP)
+	authenticated.GET("/users/subscriptions/:id", userController.SubscriptionList)
+	authenticated.POST("/users/comments", authMiddleware.RequirePermissions(domain.PermCommentCreate), userController.AddComment)
+	authenticated.POST("/upload", userController.UploadFiles)
+	authenticated.GET("/users/:id", userController.GetUserById)
+	authenticated.PUT("/users/:id", userController.UpdateUser)
+	authenticated.PATCH("/users/:id", userController.UpdateUser)
+	authenticated.DELETE("/users/:id", userController.DeleteUser)
+	authenticated.GET("/users/:id/export", userController.ExportUserData)
+	authenticated.POST("/subscriptions", authMiddleware.RequirePermissions(domain.PermCourseSubscribe), courseController.Subscription)
+
+	// Gestión de cursos. Crear depende del rol global; editar, borrar, subir material
+	// y administrar el staff lo valida el servicio contra el staff de cada curso.
+	authenticated.POST("/courses/create", authMiddleware.RequirePermissions(domain.PermCourseCreate), courseController.CreateCourse)
+	authenticated.PUT("/courses/update/:id", courseController.UpdateCourse)
+	authenticated.PATCH("/courses/:id", courseController.PatchCourse)
+	authenticated.DELETE("/courses/delete/:id", courseController.DeleteCourse)
+	authenticated.POST("/courses/:id/status", courseController.ChangeCourseStatus)
+	authenticated.GET("/courses/:id/revisions", courseController.ListRevisions)
+	authenticated.GET("/courses/:id/revisions/diff", courseController.DiffRevisions)
+	authenticated.POST("/courses/:id/revisions/:rev/restore", courseController.RestoreRevision)
+	authenticated.POST("/courses/:id/staff", courseController.AddStaff)
+	authenticated.DELETE("/courses/:id/staff/:userId", courseController.RemoveStaff)
+
+	// Contenido de los cursos: lo ven los suscriptos y lo editan owners y co-instructores.
+	// Los archivos subidos se descargan por acá y no como estáticos.
+	authenticated.GET("/courses/images/:id", courseController.GetCourseImages)
+	authenticated.GET("/courses/:id/files/:fileId", courseController.DownloadCourseFile)
+	authenticated.GET("/courses/:id/modules", courseController.ListModules)
+	authenticated.POST("/courses/:id/modules", courseController.CreateModule)
+	authenticated.PUT("/courses/:id/modules/:moduleId", courseController.UpdateModule)
+	authenticated.DELETE("/courses/:id/modules/:moduleId", courseController.DeleteModule)
+	authenticated.GET("/courses/:id/lessons", courseController.ListLessons)
+	authenticated.POST("/courses/:id/lessons", courseController.CreateLesson)
+	authenticated.PUT("/courses/:id/lessons/order", courseController.ReorderLessons)
+	authenticated.GET("/courses/:id/lessons/:lessonId", courseController.GetLesson)
+	authenticated.PUT("/courses/:id/lessons/:lessonId", courseController.UpdateLesson)
+	authenticated.DELETE("/courses/:id/lessons/:lessonId", courseController.DeleteLesson)
+
+	// Rutas de administración
+	admin := engine.Group("/admin", authMiddleware.Authenticate())
+	admin.POST("/users/:id/verification/resend", authMiddleware.RequirePermissions(domain.PermUserManage), userController.ResendVerification)
+	admin.POST("/users/:id/verify", authMiddleware.RequirePermissions(domain.PermUserManage), userController.MarkEmailVerified)
+	admin.POST("/users/:id/unlock", authMiddleware.RequirePermissions(domain.PermUserManage), userController.UnlockUser)
+	admin.GET("/users", authMiddleware.RequirePermissions(domain.PermUserManage), userController.ListUsers)
+	admin.GET("/users/export", authMiddleware.RequirePermissions(domain.PermUserManage), userController.ExportUsers)
+	admin.POST("/users/import", authMiddleware.RequirePermissions(domain.PermUserManage), userController.ImportUsers)
+	admin.POST("/users/:id/disable", authMiddleware.RequirePermissions(domain.PermUserManage), userController.DisableUser)
+	admin.POST("/users/:id/enable", authMiddleware.RequirePermissions(domain.PermUserManage), userController.EnableUser)
+	admin.POST("/users/:id/password/force-reset", authMiddleware.RequirePermissions(domain.PermUserManage), userController.ForcePasswordReset)
+	admin.POST("/users/:id/promote", authMiddleware.RequirePermissions(domain.PermRoleAssign), userController.PromoteUser)
+	admin.POST("/users/:id/demote", authMiddleware.RequirePermissions(domain.PermRoleAssign), userController.DemoteUser)
+	admin.PUT("/users/:id/roles", authMiddleware.RequirePermissions(domain.PermRoleAssign), userController.SetUserRoles)
+	admin.GET("/roles", authMiddleware.RequirePermissions(domain.PermRoleAssign), userController.ListRoles)
+	admin.GET("/audit", authMiddleware.RequirePermissions(domain.PermAuditRead), auditLogController.ListEvents)
+	admin.GET("/courses/trash", authMiddleware.RequirePermissions(domain.PermCourseDelete), courseController.ListTrash)
+	admin.POST("/courses/:id/restore", authMiddleware.RequirePermissions(domain.PermCourseDelete), courseController.RestoreCourse)
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
 }
@@ -5,9 +5,12 @@ import (
 	"backend/interfaces"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 const (
@@ -68,13 +71,114 @@ func (dc *DatabaseClient) AutoMigrate() error {
 	var subscription domain.Subscription
 	var comment domain.Comment
 	var file domain.File
+	var session domain.Session
+	var userToken domain.UserToken
+	var outboxEmail domain.OutboxEmail
+	var twoFactor domain.TwoFactor
+	var recoveryCode domain.RecoveryCode
+	var auditEvent domain.AuditEvent
+	var role domain.Role
+	var rolePermission domain.RolePermission
+	var userRole domain.UserRole
+	var courseStaff domain.CourseStaff
+	var module domain.CourseModule
+	var lesson domain.Lesson
+	var courseRevision domain.CourseRevision
 
-	if err := dc.db.AutoMigrate(&user, &course, &subscription, &comment, &file); err != nil {
+	// Las cuentas creadas antes de la verificación de email se consideran verificadas
+	backfillVerified := dc.db.Migrator().HasTable(&user) && !dc.db.Migrator().HasColumn(&user, "EmailVerifiedAt")
+
+	// El booleano users.type se reemplaza por user_roles. Mientras la columna exista la migración
+	// no terminó, así que se vuelve a intentar aunque user_roles ya esté creada.
+	migrateUserType := dc.db.Migrator().HasTable(&user) && dc.db.Migrator().HasColumn(&user, "type")
+
+	if err := dc.db.AutoMigrate(&user, &course, &subscription, &comment, &file, &session, &userToken, &outboxEmail, &twoFactor, &recoveryCode, &auditEvent, &role, &rolePermission, &userRole, &courseStaff, &module, &lesson, &courseRevision); err != nil {
 		return fmt.Errorf("error creating entities: %v", err)
 	}
+
+	if err := dc.dropDeletedUserForeignKeys(); err != nil {
+		return fmt.Errorf("error dropping user foreign keys: %v", err)
+	}
+
+	if err := dc.seedRoles(); err != nil {
+		return fmt.Errorf("error creating default roles: %v", err)
+	}
+
+	if migrateUserType {
+		if err := dc.migrateUserType(); err != nil {
+			return fmt.Errorf("error migrating user types to roles: %v", err)
+		}
+	}
+
+	if backfillVerified {
+		result := dc.db.Model(&domain.User{}).Where("email_verified_at IS NULL").Update("email_verified_at", time.Now())
+		if result.Error != nil {
+			return fmt.Errorf("error marking existing users as verified: %v", result.Error)
+		}
+	}
 	return nil
 }
 
+// dropDeletedUserForeignKeys quita las foreign keys de comments.user_id y files.user_id que
+// crea init.sql. Al eliminar una cuenta esas filas pasan a DeletedUserID, que no es un usuario.
+func (dc *DatabaseClient) dropDeletedUserForeignKeys() error {
+	for _, table := range []string{"comments", "files"} {
+		var constraints []string
+		err := dc.db.Raw(`SELECT CONSTRAINT_NAME FROM information_schema.KEY_COLUMN_USAGE
+			WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND COLUMN_NAME = 'user_id' AND REFERENCED_TABLE_NAME = 'users'`,
+			table).Scan(&constraints).Error
+		if err != nil {
+			return err
+		}
+		for _, constraint := range constraints {
+			if err := dc.db.Exec(fmt.Sprintf("ALTER TABLE `%s` DROP FOREIGN KEY `%s`", table, constraint)).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// seedRoles crea los roles predefinidos que falten, con sus permisos por defecto. Los roles
+// existentes no se modifican para respetar los permisos que se hayan cambiado a mano.
+func (dc *DatabaseClient) seedRoles() error {
+	return dc.db.Transaction(func(tx *gorm.DB) error {
+		for name, permissions := range domain.DefaultRolePermissions {
+			role := domain.Role{Name: name}
+			result := tx.Where("name = ?", name).FirstOrCreate(&role)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				continue
+			}
+			rolePermissions := make([]domain.RolePermission, 0, len(permissions))
+			for _, permission := range permissions {
+				rolePermissions = append(rolePermissions, domain.RolePermission{RoleID: role.Id, Permission: permission})
+			}
+			if err := tx.Create(&rolePermissions).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// migrateUserType asigna admin a los usuarios con type = true y student al resto, y recién
+// después elimina la columna para que no quede un dato desactualizado. Los usuarios que ya
+// tienen roles se saltean, así un intento interrumpido se puede repetir en el próximo arranque.
+func (dc *DatabaseClient) migrateUserType() error {
+	err := dc.db.Exec(`INSERT INTO user_roles (user_id, role_id, created_at)
+		SELECT users.id, roles.id, ? FROM users
+		JOIN roles ON roles.name = CASE WHEN users.type THEN ? ELSE ? END
+		WHERE NOT EXISTS (SELECT 1 FROM user_roles WHERE user_roles.user_id = users.id)`,
+		time.Now(), domain.RoleAdmin, domain.RoleStudent).Error
+	if err != nil {
+		return err
+	}
+	return dc.db.Migrator().DropColumn(&domain.User{}, "type")
+}
+
 // Operaciones de usuarios
 func (dc *DatabaseClient) GetUserByEmail(email string) (*domain.User, error) {
 	var user domain.User
@@ -82,12 +186,20 @@ func (dc *DatabaseClient) GetUserByEmail(email string) (*domain.User, error) {
 	if result.Error != nil {
 		return nil, result.Error
 	}
+	if err := dc.loadRoles(&user); err != nil {
+		return nil, err
+	}
 	return &user, nil
 }
 
+// CreateUser crea el usuario y le asigna sus roles en la misma transacción
 func (dc *DatabaseClient) CreateUser(user domain.User) error {
-	result := dc.db.Create(&user)
-	return result.Error
+	return dc.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		return assignRoles(tx, user.Id, user.Roles)
+	})
 }
 
 func (dc *DatabaseClient) GetUserById(id int64) (*domain.User, error) {
@@ -96,16 +208,275 @@ func (dc *DatabaseClient) GetUserById(id int64) (*domain.User, error) {
 	if result.Error != nil {
 		return nil, result.Error
 	}
+	if err := dc.loadRoles(&user); err != nil {
+		return nil, err
+	}
 	return &user, nil
 }
 
-// Operaciones de cursos
-func (dc *DatabaseClient) GetCoursewithQuery(query string) ([]domain.Course, error) {
-	var courses []domain.Course
-	result := dc.db.Where("title LIKE ? OR description LIKE ?", "%"+query+"%", "%"+query+"%").Find(&courses)
-	return courses, result.Error
+// loadRoles completa los roles del usuario y la unión de sus permisos
+func (dc *DatabaseClient) loadRoles(user *domain.User) error {
+	user.Roles = make([]string, 0)
+	err := dc.db.Model(&domain.Role{}).
+		Joins("JOIN user_roles ON user_roles.role_id = roles.id").
+		Where("user_roles.user_id = ?", user.Id).
+		Order("roles.name").
+		Pluck("roles.name", &user.Roles).Error
+	if err != nil {
+		return err
+	}
+
+	user.Permissions = make([]string, 0)
+	return dc.db.Model(&domain.RolePermission{}).
+		Distinct("role_permissions.permission").
+		Joins("JOIN user_roles ON user_roles.role_id = role_permissions.role_id").
+		Where("user_roles.user_id = ?", user.Id).
+		Order("role_permissions.permission").
+		Pluck("role_permissions.permission", &user.Permissions).Error
+}
+
+// assignRoles inserta los roles indicados por nombre. Falla si alguno no existe.
+func assignRoles(tx *gorm.DB, userID int64, roleNames []string) error {
+	if len(roleNames) == 0 {
+		return nil
+	}
+
+	var roles []domain.Role
+	if err := tx.Where("name IN ?", roleNames).Find(&roles).Error; err != nil {
+		return err
+	}
+	if len(roles) != len(roleNames) {
+		return fmt.Errorf("unknown role in %v", roleNames)
+	}
+
+	now := time.Now()
+	userRoles := make([]domain.UserRole, 0, len(roles))
+	for _, role := range roles {
+		userRoles = append(userRoles, domain.UserRole{UserID: userID, RoleID: role.Id, CreatedAt: now})
+	}
+	return tx.Create(&userRoles).Error
+}
+
+// SetUserRoles reemplaza los roles del usuario
+func (dc *DatabaseClient) SetUserRoles(userID int64, roleNames []string) error {
+	return dc.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.UserRole{}).Error; err != nil {
+			return err
+		}
+		return assignRoles(tx, userID, roleNames)
+	})
+}
+
+// GetRoles devuelve todos los roles con sus permisos
+func (dc *DatabaseClient) GetRoles() ([]domain.Role, error) {
+	var roles []domain.Role
+	if err := dc.db.Order("name").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+
+	var rolePermissions []domain.RolePermission
+	if err := dc.db.Order("permission").Find(&rolePermissions).Error; err != nil {
+		return nil, err
+	}
+
+	byRole := make(map[int64][]string)
+	for _, rolePermission := range rolePermissions {
+		byRole[rolePermission.RoleID] = append(byRole[rolePermission.RoleID], rolePermission.Permission)
+	}
+	for i := range roles {
+		roles[i].Permissions = byRole[roles[i].Id]
+		if roles[i].Permissions == nil {
+			roles[i].Permissions = make([]string, 0)
+		}
+	}
+	return roles, nil
+}
+
+// UpdatePasswordHash guarda la contraseña nueva y cumple un cambio forzado pendiente
+func (dc *DatabaseClient) UpdatePasswordHash(userID int64, passwordHash string) error {
+	result := dc.db.Model(&domain.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"password_hash":           passwordHash,
+		"password_reset_required": false,
+	})
+	return result.Error
+}
+
+// ListUsers devuelve la página pedida y el total de usuarios que cumplen los filtros
+func (dc *DatabaseClient) ListUsers(query domain.UserQuery) ([]domain.User, int64, error) {
+	db := dc.db.Model(&domain.User{})
+	if query.Email != "" {
+		db = db.Where("email LIKE ?", "%"+escapeLike(query.Email)+"%")
+	}
+	if query.Nickname != "" {
+		db = db.Where("nickname LIKE ?", "%"+escapeLike(query.Nickname)+"%")
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	order := "id"
+	field := strings.TrimPrefix(query.Sort, "-")
+	if column, ok := domain.UserSortFields[field]; ok {
+		order = column
+	}
+	if strings.HasPrefix(query.Sort, "-") {
+		order += " DESC"
+	}
+
+	var users []domain.User
+	result := db.Order(order).
+		Offset((query.Page - 1) * query.PageSize).
+		Limit(query.PageSize).
+		Find(&users)
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+
+	for i := range users {
+		if err := dc.loadRoles(&users[i]); err != nil {
+			return nil, 0, err
+		}
+	}
+	return users, total, nil
+}
+
+// escapeLike evita que los % y _ del filtro funcionen como comodines
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+func (dc *DatabaseClient) SetUserDisabled(userID int64, disabledAt *time.Time) error {
+	result := dc.db.Model(&domain.User{}).Where("id = ?", userID).Update("disabled_at", disabledAt)
+	return result.Error
+}
+
+func (dc *DatabaseClient) SetPasswordResetRequired(userID int64, required bool) error {
+	result := dc.db.Model(&domain.User{}).Where("id = ?", userID).Update("password_reset_required", required)
+	return result.Error
+}
+
+func (dc *DatabaseClient) SetEmailVerified(userID int64, verifiedAt time.Time) error {
+	result := dc.db.Model(&domain.User{}).Where("id = ?", userID).Update("email_verified_at", verifiedAt)
+	return result.Error
+}
+
+// IsNicknameTaken indica si otro usuario distinto de excludeUserID ya usa el nickname
+func (dc *DatabaseClient) IsNicknameTaken(nickname string, excludeUserID int64) (bool, error) {
+	var count int64
+	result := dc.db.Model(&domain.User{}).Where("nickname = ? AND id <> ?", nickname, excludeUserID).Count(&count)
+	return count > 0, result.Error
+}
+
+// IsEmailTaken indica si otro usuario distinto de excludeUserID ya usa el email
+func (dc *DatabaseClient) IsEmailTaken(email string, excludeUserID int64) (bool, error) {
+	var count int64
+	result := dc.db.Model(&domain.User{}).Where("email = ? AND id <> ?", email, excludeUserID).Count(&count)
+	return count > 0, result.Error
+}
+
+func (dc *DatabaseClient) UpdateNickname(userID int64, nickname string) error {
+	result := dc.db.Model(&domain.User{}).Where("id = ?", userID).Update("nickname", nickname)
+	return result.Error
+}
+
+func (dc *DatabaseClient) SetPendingEmail(userID int64, email string) error {
+	result := dc.db.Model(&domain.User{}).Where("id = ?", userID).Update("pending_email", email)
+	return result.Error
+}
+
+// ConfirmEmailChange reemplaza el email por el pendiente, que ya quedó verificado
+func (dc *DatabaseClient) ConfirmEmailChange(userID int64, email string, verifiedAt time.Time) error {
+	result := dc.db.Model(&domain.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"email":             email,
+		"pending_email":     nil,
+		"email_verified_at": verifiedAt,
+	})
+	return result.Error
+}
+
+// DeleteUser elimina la cuenta con todos sus datos personales. Los comentarios y los archivos
+// se conservan a nombre de DeletedUserID porque son parte de los cursos. Incluye los datos de
+// cursos que están en la papelera. Los eventos de auditoría sobre la cuenta se conservan sin
+// los snapshots ni los emails, y se borran los emails enviados a sus direcciones.
+func (dc *DatabaseClient) DeleteUser(userID int64) error {
+	return dc.db.Transaction(func(tx *gorm.DB) error {
+		var user domain.User
+		if err := tx.Select("id", "email", "pending_email").First(&user, userID).Error; err != nil {
+			return err
+		}
+
+		if err := scrubUserAudit(tx, user); err != nil {
+			return err
+		}
+
+		addresses := []string{user.Email}
+		if user.PendingEmail != nil {
+			addresses = append(addresses, *user.PendingEmail)
+		}
+		if err := tx.Where("recipient IN ?", addresses).Delete(&domain.OutboxEmail{}).Error; err != nil {
+			return err
+		}
+
+		for _, model := range []interface{}{&domain.Comment{}, &domain.File{}} {
+			if err := tx.Unscoped().Model(model).Where("user_id = ?", userID).Update("user_id", domain.DeletedUserID).Error; err != nil {
+				return err
+			}
+		}
+
+		personalData := []interface{}{
+			&domain.Subscription{}, &domain.Session{}, &domain.UserToken{},
+			&domain.TwoFactor{}, &domain.RecoveryCode{}, &domain.UserRole{}, &domain.CourseStaff{},
+		}
+		for _, model := range personalData {
+			if err := tx.Unscoped().Where("user_id = ?", userID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		result := tx.Delete(&domain.User{}, userID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("user %d not found", userID)
+		}
+		return nil
+	})
+}
+
+// scrubUserAudit quita los datos personales de los eventos de auditoría de una cuenta que se
+// elimina: los snapshots del perfil y los emails de los logins fallidos. Es la única excepción
+// a que la tabla sea de solo inserción; los eventos siguen existiendo con el id de la cuenta.
+func scrubUserAudit(tx *gorm.DB, user domain.User) error {
+	err := tx.Model(&domain.AuditEvent{}).
+		Where("target_type = ? AND target_id = ?", domain.AuditTargetUser, user.Id).
+		Updates(map[string]interface{}{"details": "", "before": nil, "after": nil}).Error
+	if err != nil {
+		return err
+	}
+
+	// Los logins fallidos con un email sin cuenta se registran con target 0
+	return tx.Model(&domain.AuditEvent{}).
+		Where("action = ? AND details LIKE ?", domain.AuditLoginFailed, user.Email+": %").
+		Update("details", "").Error
+}
+
+// GetSoleOwnedCourseIDs devuelve los cursos en los que el usuario es el único owner
+func (dc *DatabaseClient) GetSoleOwnedCourseIDs(userID int64) ([]int64, error) {
+	otherOwners := dc.db.Model(&domain.CourseStaff{}).Select("course_id").
+		Where("role = ? AND user_id <> ?", domain.StaffOwner, userID)
+
+	var ids []int64
+	result := dc.db.Model(&domain.CourseStaff{}).
+		Where("user_id = ? AND role = ? AND course_id NOT IN (?)", userID, domain.StaffOwner, otherOwners).
+		Order("course_id").
+		Pluck("course_id", &ids)
+	return ids, result.Error
 }
 
+// Operaciones de cursos
 func (dc *DatabaseClient) GetCourseById(id int64) (*domain.Course, error) {
 	var course domain.Course
 	result := dc.db.First(&course, id)
@@ -115,19 +486,171 @@ func (dc *DatabaseClient) GetCourseById(id int64) (*domain.Course, error) {
 	return &course, nil
 }
 
-func (dc *DatabaseClient) GetCourses() ([]domain.Course, error) {
+// GetSubscriptionCounts devuelve la cantidad de suscriptos de cada curso. Los cursos sin
+// suscripciones no aparecen en el mapa.
+func (dc *DatabaseClient) GetSubscriptionCounts(courseIDs []int64) (map[int64]int64, error) {
+	counts := make(map[int64]int64)
+	if len(courseIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		CourseID int64
+		Total    int64
+	}
+	result := dc.db.Model(&domain.Subscription{}).
+		Select("course_id, COUNT(*) AS total").
+		Where("course_id IN ?", courseIDs).
+		Group("course_id").
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	for _, row := range rows {
+		counts[row.CourseID] = row.Total
+	}
+	return counts, nil
+}
+
+// GetCourses devuelve la página pedida y el total de cursos que cumplen los filtros
+func (dc *DatabaseClient) GetCourses(query domain.CourseQuery) ([]domain.Course, int64, error) {
+	db := dc.db.Model(&domain.Course{})
+	if len(query.Categories) > 0 {
+		db = db.Where("category IN ?", query.Categories)
+	}
+	if len(query.Instructors) > 0 {
+		db = db.Where("instructor IN ?", query.Instructors)
+	}
+	if len(query.DurationBuckets) > 0 {
+		conditions := make([]string, 0, len(query.DurationBuckets))
+		args := make([]interface{}, 0, 2*len(query.DurationBuckets))
+		for _, key := range query.DurationBuckets {
+			bucket, _ := domain.FindDurationBucket(key)
+			if bucket.Max == 0 {
+				conditions = append(conditions, "duration >= ?")
+				args = append(args, bucket.Min)
+			} else {
+				conditions = append(conditions, "duration BETWEEN ? AND ?")
+				args = append(args, bucket.Min, bucket.Max)
+			}
+		}
+		db = db.Where("("+strings.Join(conditions, " OR ")+")", args...)
+	}
+	if !query.AllStatuses {
+		visible := dc.db.Where("status = ? AND (publish_at IS NULL OR publish_at <= ?)", domain.CoursePublished, time.Now())
+		if query.StaffUserID > 0 {
+			staff := dc.db.Model(&domain.CourseStaff{}).Select("course_id").Where("user_id = ?", query.StaffUserID)
+			visible = visible.Or("id IN (?)", staff)
+		}
+		db = db.Where(visible)
+	}
+	if query.MinDuration > 0 {
+		db = db.Where("duration >= ?", query.MinDuration)
+	}
+	if query.MaxDuration > 0 {
+		db = db.Where("duration <= ?", query.MaxDuration)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	order := "id"
+	field := strings.TrimPrefix(query.Sort, "-")
+	if column, ok := domain.CourseSortFields[field]; ok {
+		order = column
+	}
+	if strings.HasPrefix(query.Sort, "-") {
+		order += " DESC"
+	}
+
+	// El id desempata para que las páginas no repitan ni salteen cursos
 	var courses []domain.Course
-	result := dc.db.Find(&courses)
-	return courses, result.Error
+	result := db.Order(order).
+		Order("id").
+		Offset((query.Page - 1) * query.PageSize).
+		Limit(query.PageSize).
+		Find(&courses)
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+	return courses, total, nil
 }
 
-func (dc *DatabaseClient) CreateCourse(course domain.Course) error {
-	result := dc.db.Create(&course)
-	return result.Error
+// CreateCourse crea el curso con su primera revisión y, si se indica, registra a su owner,
+// todo en la misma transacción
+func (dc *DatabaseClient) CreateCourse(course domain.Course, ownerID int64, revision domain.CourseRevision) (domain.Course, error) {
+	err := dc.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&course).Error; err != nil {
+			return err
+		}
+
+		revision.CourseID = int64(course.Id)
+		revision.Number = 1
+		if err := tx.Create(&revision).Error; err != nil {
+			return err
+		}
+
+		if ownerID == 0 {
+			return nil
+		}
+		return tx.Create(&domain.CourseStaff{
+			CourseID:  int64(course.Id),
+			UserID:    ownerID,
+			Role:      domain.StaffOwner,
+			AddedBy:   ownerID,
+			CreatedAt: time.Now(),
+		}).Error
+	})
+	return course, err
 }
 
-func (dc *DatabaseClient) UpdateCourse(courseID int64, course domain.Course) error {
-	result := dc.db.Model(&domain.Course{}).Where("id = ?", courseID).Updates(course)
+// UpdateCourse escribe los campos editables del curso y su revisión en la misma transacción.
+// Las columnas se nombran para que Updates no saltee los valores en cero. La fila del curso
+// queda bloqueada hasta el commit, así dos ediciones no pueden tomar el mismo número de
+// revisión. Si el curso todavía no tiene historial, primero se guarda baseline.
+func (dc *DatabaseClient) UpdateCourse(courseID int64, course domain.Course, baseline, revision domain.CourseRevision) error {
+	return dc.db.Transaction(func(tx *gorm.DB) error {
+		var locked domain.Course
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Take(&locked, courseID).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(&domain.Course{}).Where("id = ?", courseID).
+			Select("title", "description", "category", "instructor", "duration", "requirement", "last_update").
+			Updates(course)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		var last int
+		if err := tx.Model(&domain.CourseRevision{}).Where("course_id = ?", courseID).Select("COALESCE(MAX(number), 0)").Scan(&last).Error; err != nil {
+			return err
+		}
+		if last == 0 {
+			baseline.CourseID = courseID
+			baseline.Number = 1
+			if err := tx.Create(&baseline).Error; err != nil {
+				return err
+			}
+			last = 1
+		}
+
+		revision.CourseID = courseID
+		revision.Number = last + 1
+		return tx.Create(&revision).Error
+	})
+}
+
+// UpdateCourseStatus escribe el estado y la fecha de publicación aunque sea nil
+func (dc *DatabaseClient) UpdateCourseStatus(courseID int64, status string, publishAt *time.Time) error {
+	result := dc.db.Model(&domain.Course{}).Where("id = ?", courseID).Updates(map[string]interface{}{
+		"status":      status,
+		"publish_at":  publishAt,
+		"last_update": time.Now(),
+	})
 	return result.Error
 }
 
@@ -149,11 +672,233 @@ func (dc *DatabaseClient) GetCoursesByInstructor(instructor string) ([]domain.Co
 	return courses, nil
 }
 
-func (dc *DatabaseClient) DeleteCourseById(courseID int64) error {
-	result := dc.db.Delete(&domain.Course{}, courseID)
+// trashedWithCourse son los datos que van a la papelera y vuelven de ella junto con su curso
+var trashedWithCourse = []interface{}{&domain.Subscription{}, &domain.Comment{}, &domain.File{}}
+
+// TrashCourse manda el curso a la papelera con sus suscripciones, comentarios y archivos.
+// Todos quedan con la misma fecha de borrado para restaurarlos juntos.
+func (dc *DatabaseClient) TrashCourse(courseID int64) error {
+	now := time.Now().Truncate(time.Second)
+	return dc.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.Course{}).Where("id = ?", courseID).Update("deleted_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		for _, model := range trashedWithCourse {
+			if err := tx.Model(model).Where("course_id = ?", courseID).Update("deleted_at", now).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetTrashedCourses devuelve los cursos de la papelera, primero los borrados más recientemente
+func (dc *DatabaseClient) GetTrashedCourses() ([]domain.Course, error) {
+	var courses []domain.Course
+	result := dc.db.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at DESC, id").Find(&courses)
+	return courses, result.Error
+}
+
+// RestoreCourse saca el curso de la papelera junto con los datos que se borraron con él.
+// Lo que ya estaba borrado antes que el curso no se restaura.
+func (dc *DatabaseClient) RestoreCourse(courseID int64) error {
+	return dc.db.Transaction(func(tx *gorm.DB) error {
+		var course domain.Course
+		if err := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", courseID).First(&course).Error; err != nil {
+			return err
+		}
+		for _, model := range trashedWithCourse {
+			err := tx.Unscoped().Model(model).
+				Where("course_id = ? AND deleted_at = ?", courseID, course.DeletedAt.Time).
+				Update("deleted_at", nil).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Unscoped().Model(&course).Update("deleted_at", nil).Error
+	})
+}
+
+// PurgeCourse borra definitivamente un curso de la papelera con todos sus datos y devuelve
+// sus archivos para que se eliminen del disco. Los subidos antes de generar los nombres
+// pueden compartir el archivo con otro curso; esos no se devuelven mientras otro los use.
+func (dc *DatabaseClient) PurgeCourse(courseID int64) ([]domain.File, error) {
+	var files []domain.File
+	err := dc.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", courseID).Delete(&domain.Course{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.Unscoped().Where("course_id = ?", courseID).Find(&files).Error; err != nil {
+			return err
+		}
+		courseData := append([]interface{}{&domain.CourseStaff{}, &domain.Lesson{}, &domain.CourseModule{}, &domain.CourseRevision{}}, trashedWithCourse...)
+		for _, model := range courseData {
+			if err := tx.Unscoped().Where("course_id = ?", courseID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		if len(files) == 0 {
+			return nil
+		}
+		urls := make([]string, 0, len(files))
+		for _, file := range files {
+			urls = append(urls, file.Url)
+		}
+		var shared []string
+		if err := tx.Unscoped().Model(&domain.File{}).Where("url IN ?", urls).Distinct().Pluck("url", &shared).Error; err != nil {
+			return err
+		}
+		inUse := make(map[string]bool, len(shared))
+		for _, url := range shared {
+			inUse[url] = true
+		}
+		unused := files[:0]
+		for _, file := range files {
+			if !inUse[file.Url] {
+				unused = append(unused, file)
+			}
+		}
+		files = unused
+		return nil
+	})
+	return files, err
+}
+
+// Operaciones de revisiones de cursos
+
+// GetCourseRevisions devuelve las revisiones del curso, primero la más reciente
+func (dc *DatabaseClient) GetCourseRevisions(courseID int64) ([]domain.CourseRevision, error) {
+	var revisions []domain.CourseRevision
+	result := dc.db.Where("course_id = ?", courseID).Order("number DESC").Find(&revisions)
+	return revisions, result.Error
+}
+
+// GetCourseRevision devuelve nil, sin error, si el curso no tiene esa revisión
+func (dc *DatabaseClient) GetCourseRevision(courseID int64, number int) (*domain.CourseRevision, error) {
+	var revision domain.CourseRevision
+	result := dc.db.Where("course_id = ? AND number = ?", courseID, number).Limit(1).Find(&revision)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &revision, nil
+}
+
+// Operaciones de módulos y lecciones
+func (dc *DatabaseClient) GetModules(courseID int64) ([]domain.CourseModule, error) {
+	var modules []domain.CourseModule
+	result := dc.db.Where("course_id = ?", courseID).Order("position, id").Find(&modules)
+	return modules, result.Error
+}
+
+func (dc *DatabaseClient) GetModuleById(moduleID int64) (*domain.CourseModule, error) {
+	var module domain.CourseModule
+	result := dc.db.First(&module, moduleID)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &module, nil
+}
+
+// CreateModule agrega el módulo al final del curso
+func (dc *DatabaseClient) CreateModule(module domain.CourseModule) (domain.CourseModule, error) {
+	err := dc.db.Transaction(func(tx *gorm.DB) error {
+		var last int
+		if err := tx.Model(&domain.CourseModule{}).Where("course_id = ?", module.CourseID).Select("COALESCE(MAX(position), 0)").Scan(&last).Error; err != nil {
+			return err
+		}
+		module.Position = last + 1
+		return tx.Create(&module).Error
+	})
+	return module, err
+}
+
+func (dc *DatabaseClient) UpdateModule(module domain.CourseModule) error {
+	result := dc.db.Model(&domain.CourseModule{}).Where("id = ?", module.Id).
+		Select("title", "updated_at").
+		Updates(module)
+	return result.Error
+}
+
+// DeleteModule borra el módulo junto con sus lecciones
+func (dc *DatabaseClient) DeleteModule(moduleID int64) error {
+	return dc.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("module_id = ?", moduleID).Delete(&domain.Lesson{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.CourseModule{}, moduleID).Error
+	})
+}
+
+func (dc *DatabaseClient) GetLessons(courseID int64) ([]domain.Lesson, error) {
+	var lessons []domain.Lesson
+	result := dc.db.Where("course_id = ?", courseID).Order("position, id").Find(&lessons)
+	return lessons, result.Error
+}
+
+func (dc *DatabaseClient) GetLessonById(lessonID int64) (*domain.Lesson, error) {
+	var lesson domain.Lesson
+	result := dc.db.First(&lesson, lessonID)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &lesson, nil
+}
+
+// CreateLesson agrega la lección al final del curso
+func (dc *DatabaseClient) CreateLesson(lesson domain.Lesson) (domain.Lesson, error) {
+	err := dc.db.Transaction(func(tx *gorm.DB) error {
+		var last int
+		if err := tx.Model(&domain.Lesson{}).Where("course_id = ?", lesson.CourseID).Select("COALESCE(MAX(position), 0)").Scan(&last).Error; err != nil {
+			return err
+		}
+		lesson.Position = last + 1
+		return tx.Create(&lesson).Error
+	})
+	return lesson, err
+}
+
+// UpdateLesson guarda todos los campos editables, incluso vacíos: al cambiar el tipo de
+// contenido se limpian los campos que ya no corresponden
+func (dc *DatabaseClient) UpdateLesson(lesson domain.Lesson) error {
+	result := dc.db.Model(&domain.Lesson{}).Where("id = ?", lesson.Id).
+		Select("module_id", "title", "type", "content", "url", "file_id", "updated_at").
+		Updates(lesson)
+	return result.Error
+}
+
+func (dc *DatabaseClient) DeleteLesson(lessonID int64) error {
+	result := dc.db.Delete(&domain.Lesson{}, lessonID)
 	return result.Error
 }
 
+// ReorderLessons numera las lecciones del curso según el orden recibido
+func (dc *DatabaseClient) ReorderLessons(courseID int64, lessonIDs []int64) error {
+	return dc.db.Transaction(func(tx *gorm.DB) error {
+		for index, lessonID := range lessonIDs {
+			result := tx.Model(&domain.Lesson{}).
+				Where("id = ? AND course_id = ?", lessonID, courseID).
+				Update("position", index+1)
+			if result.Error != nil {
+				return result.Error
+			}
+		}
+		return nil
+	})
+}
+
 // Operaciones de suscripciones
 func (dc *DatabaseClient) InsertSubscription(userID, courseID int64) error {
 	// Verificar si ya existe la suscripción
@@ -186,9 +931,11 @@ func (dc *DatabaseClient) GetCourseIdsByUserId(userID int64) ([]int64, error) {
 	return courseIDs, nil
 }
 
-func (dc *DatabaseClient) DeleteSubscriptionById(courseID int64) error {
-	result := dc.db.Where("course_id = ?", courseID).Delete(&domain.Subscription{})
-	return result.Error
+// IsSubscribed indica si el usuario está suscripto al curso
+func (dc *DatabaseClient) IsSubscribed(userID, courseID int64) (bool, error) {
+	var count int64
+	result := dc.db.Model(&domain.Subscription{}).Where("user_id = ? AND course_id = ?", userID, courseID).Count(&count)
+	return count > 0, result.Error
 }
 
 // Operaciones de comentarios
@@ -223,11 +970,310 @@ func (dc *DatabaseClient) GetCommentById(commentID int64) (domain.Comment, error
 }
 
 // Operaciones de archivos
+// GetCommentsByUserId incluye los comentarios de cursos en la papelera, como GetFilesByUserId
+func (dc *DatabaseClient) GetCommentsByUserId(userID int64) ([]domain.Comment, error) {
+	var comments []domain.Comment
+	result := dc.db.Unscoped().Where("user_id = ?", userID).Order("id").Find(&comments)
+	return comments, result.Error
+}
+
+// GetFilesByUserId incluye los archivos de cursos en la papelera, porque siguen siendo del usuario
+func (dc *DatabaseClient) GetFilesByUserId(userID int64) ([]domain.File, error) {
+	var files []domain.File
+	result := dc.db.Unscoped().Where("user_id = ?", userID).Order("id").Find(&files)
+	return files, result.Error
+}
+
+func (dc *DatabaseClient) GetFileById(fileID int64) (*domain.File, error) {
+	var file domain.File
+	result := dc.db.First(&file, fileID)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &file, nil
+}
+
 func (dc *DatabaseClient) SaveFile(file domain.File) error {
 	result := dc.db.Create(&file)
 	return result.Error
 }
 
+// Operaciones de sesiones
+func (dc *DatabaseClient) CreateSession(session domain.Session) (domain.Session, error) {
+	result := dc.db.Create(&session)
+	return session, result.Error
+}
+
+func (dc *DatabaseClient) GetSessionById(id int64) (*domain.Session, error) {
+	var session domain.Session
+	result := dc.db.First(&session, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &session, nil
+}
+
+// GetSessionByRefreshHash busca la sesión por su refresh token vigente o por el anterior,
+// así el servicio puede detectar que se reusó un token ya rotado
+func (dc *DatabaseClient) GetSessionByRefreshHash(refreshTokenHash string) (*domain.Session, error) {
+	var session domain.Session
+	result := dc.db.Where("refresh_token_hash = ? OR previous_refresh_hash = ?", refreshTokenHash, refreshTokenHash).First(&session)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &session, nil
+}
+
+func (dc *DatabaseClient) GetActiveSessionsByUserId(userID int64) ([]domain.Session, error) {
+	var sessions []domain.Session
+	result := dc.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return sessions, nil
+}
+
+// RotateSessionRefresh reemplaza el refresh token solo si el vigente sigue siendo
+// currentHash. Ante dos requests simultáneos con el mismo token, solo uno lo rota.
+func (dc *DatabaseClient) RotateSessionRefresh(id int64, currentHash, newHash string, lastUsedAt time.Time) error {
+	result := dc.db.Model(&domain.Session{}).Where("id = ? AND refresh_token_hash = ?", id, currentHash).Updates(map[string]interface{}{
+		"refresh_token_hash":    newHash,
+		"previous_refresh_hash": currentHash,
+		"last_used_at":          lastUsedAt,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrRefreshTokenReused
+	}
+	return nil
+}
+
+func (dc *DatabaseClient) RevokeSession(id int64, revokedAt time.Time) error {
+	result := dc.db.Model(&domain.Session{}).Where("id = ? AND revoked_at IS NULL", id).Update("revoked_at", revokedAt)
+	return result.Error
+}
+
+func (dc *DatabaseClient) RevokeUserSessions(userID int64, revokedAt time.Time) error {
+	result := dc.db.Model(&domain.Session{}).Where("user_id = ? AND revoked_at IS NULL", userID).Update("revoked_at", revokedAt)
+	return result.Error
+}
+
+// Operaciones de tokens de un solo uso
+func (dc *DatabaseClient) CreateUserToken(token domain.UserToken) error {
+	result := dc.db.Create(&token)
+	return result.Error
+}
+
+func (dc *DatabaseClient) GetUserTokenByHash(tokenHash string) (*domain.UserToken, error) {
+	var token domain.UserToken
+	result := dc.db.Where("token_hash = ?", tokenHash).First(&token)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &token, nil
+}
+
+// ConsumeUserToken marca el token como usado. La condición sobre used_at hace que, ante dos
+// requests simultáneos con el mismo token, solo uno de ellos lo consuma.
+func (dc *DatabaseClient) ConsumeUserToken(id int64, usedAt time.Time) error {
+	result := dc.db.Model(&domain.UserToken{}).Where("id = ? AND used_at IS NULL", id).Update("used_at", usedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("token %d was already used", id)
+	}
+	return nil
+}
+
+func (dc *DatabaseClient) InvalidateUserTokens(userID int64, purpose string, usedAt time.Time) error {
+	result := dc.db.Model(&domain.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", usedAt)
+	return result.Error
+}
+
+// Operaciones de 2FA
+
+// GetTwoFactor devuelve nil, sin error, si el usuario nunca inició la inscripción
+func (dc *DatabaseClient) GetTwoFactor(userID int64) (*domain.TwoFactor, error) {
+	var twoFactor domain.TwoFactor
+	result := dc.db.Where("user_id = ?", userID).Limit(1).Find(&twoFactor)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &twoFactor, nil
+}
+
+func (dc *DatabaseClient) SaveTwoFactor(twoFactor domain.TwoFactor) error {
+	result := dc.db.Save(&twoFactor)
+	return result.Error
+}
+
+func (dc *DatabaseClient) EnableTwoFactor(userID int64, counter int64, enabledAt time.Time) error {
+	result := dc.db.Model(&domain.TwoFactor{}).Where("user_id = ? AND enabled_at IS NULL", userID).Updates(map[string]interface{}{
+		"last_counter": counter,
+		"enabled_at":   enabledAt,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("two-factor authentication is already enabled for user %d", userID)
+	}
+	return nil
+}
+
+// UpdateTwoFactorCounter guarda el último período usado. Si otro request ya usó ese
+// período (o uno posterior) no actualiza nada y devuelve error.
+func (dc *DatabaseClient) UpdateTwoFactorCounter(userID int64, counter int64) error {
+	result := dc.db.Model(&domain.TwoFactor{}).Where("user_id = ? AND last_counter < ?", userID, counter).Update("last_counter", counter)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("code was already used")
+	}
+	return nil
+}
+
+func (dc *DatabaseClient) DeleteTwoFactor(userID int64) error {
+	return dc.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&domain.TwoFactor{}).Error
+	})
+}
+
+func (dc *DatabaseClient) ReplaceRecoveryCodes(userID int64, codeHashes []string) error {
+	return dc.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]domain.RecoveryCode, 0, len(codeHashes))
+		for _, hash := range codeHashes {
+			codes = append(codes, domain.RecoveryCode{UserID: userID, CodeHash: hash})
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (dc *DatabaseClient) UseRecoveryCode(userID int64, codeHash string, usedAt time.Time) error {
+	result := dc.db.Model(&domain.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("invalid recovery code")
+	}
+	return nil
+}
+
+// Operaciones de emails salientes
+func (dc *DatabaseClient) SaveOutboxEmail(email domain.OutboxEmail) error {
+	result := dc.db.Create(&email)
+	return result.Error
+}
+
+// Operaciones de staff de cursos
+
+// GetCourseStaffMember devuelve nil, sin error, si el usuario no es staff del curso
+func (dc *DatabaseClient) GetCourseStaffMember(courseID, userID int64) (*domain.CourseStaff, error) {
+	var staff domain.CourseStaff
+	result := dc.db.Where("course_id = ? AND user_id = ?", courseID, userID).Limit(1).Find(&staff)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &staff, nil
+}
+
+// GetStaffCourseIDs devuelve los cursos de los que el usuario es staff, con cualquier rol
+func (dc *DatabaseClient) GetStaffCourseIDs(userID int64) ([]int64, error) {
+	var ids []int64
+	result := dc.db.Model(&domain.CourseStaff{}).Where("user_id = ?", userID).Pluck("course_id", &ids)
+	return ids, result.Error
+}
+
+func (dc *DatabaseClient) GetCourseStaff(courseID int64) ([]domain.CourseStaff, error) {
+	var staff []domain.CourseStaff
+	result := dc.db.Where("course_id = ?", courseID).Order("created_at").Find(&staff)
+	return staff, result.Error
+}
+
+// SaveCourseStaff agrega al usuario al staff o actualiza su rol si ya estaba
+func (dc *DatabaseClient) SaveCourseStaff(staff domain.CourseStaff) error {
+	result := dc.db.Save(&staff)
+	return result.Error
+}
+
+func (dc *DatabaseClient) RemoveCourseStaff(courseID, userID int64) error {
+	result := dc.db.Where("course_id = ? AND user_id = ?", courseID, userID).Delete(&domain.CourseStaff{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("user %d is not staff of course %d", userID, courseID)
+	}
+	return nil
+}
+
+// Operaciones de auditoría
+func (dc *DatabaseClient) CreateAuditEvent(event domain.AuditEvent) error {
+	result := dc.db.Create(&event)
+	return result.Error
+}
+
+// ListAuditEvents devuelve la página pedida, de la más reciente a la más antigua, y el total de eventos que cumplen los filtros
+func (dc *DatabaseClient) ListAuditEvents(query domain.AuditQuery) ([]domain.AuditEvent, int64, error) {
+	db := dc.db.Model(&domain.AuditEvent{})
+	if query.ActorID != 0 {
+		db = db.Where("actor_id = ?", query.ActorID)
+	}
+	if query.Action != "" {
+		db = db.Where("action = ?", query.Action)
+	}
+	if query.TargetType != "" {
+		db = db.Where("target_type = ?", query.TargetType)
+	}
+	if query.TargetID != 0 {
+		db = db.Where("target_id = ?", query.TargetID)
+	}
+	if query.From != nil {
+		db = db.Where("created_at >= ?", *query.From)
+	}
+	if query.To != nil {
+		db = db.Where("created_at <= ?", *query.To)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var events []domain.AuditEvent
+	result := db.Order("created_at DESC, id DESC").
+		Offset((query.Page - 1) * query.PageSize).
+		Limit(query.PageSize).
+		Find(&events)
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+	return events, total, nil
+}
+
 // StartDB función de compatibilidad para mantener la funcionalidad existente
 func StartDB() {
 	client := NewDatabaseClient()
@@ -0,0 +1,81 @@
+package audit
+
+import (
+	auditDomain "backend/domain"
+	"backend/interfaces"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditController struct {
+	auditService interfaces.AuditServiceInterface
+}
+
+func NewAuditController(auditService interfaces.AuditServiceInterface) *AuditController {
+	return &AuditController{auditService: auditService}
+}
+
+// ListEvents devuelve el log de auditoría. Filtros opcionales: actor_id, action, target_type,
+// target_id, from y to (RFC 3339). Paginación con page y page_size.
+func (ac *AuditController) ListEvents(c *gin.Context) {
+	query, err := parseAuditQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, auditDomain.Result{
+			Message: fmt.Sprintf("invalid query: %s", err.Error()),
+		})
+		return
+	}
+
+	page, err := ac.auditService.List(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, auditDomain.Result{
+			Message: fmt.Sprintf("error in getting audit events: %s", err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func parseAuditQuery(c *gin.Context) (auditDomain.AuditQuery, error) {
+	query := auditDomain.AuditQuery{
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+	}
+
+	for name, target := range map[string]*int64{"actor_id": &query.ActorID, "target_id": &query.TargetID} {
+		if value := c.Query(name); value != "" {
+			parsed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return query, fmt.Errorf("%s must be a number", name)
+			}
+			*target = parsed
+		}
+	}
+
+	for name, target := range map[string]*int{"page": &query.Page, "page_size": &query.PageSize} {
+		if value := c.Query(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 1 {
+				return query, fmt.Errorf("%s must be a positive number", name)
+			}
+			*target = parsed
+		}
+	}
+
+	for name, target := range map[string]**time.Time{"from": &query.From, "to": &query.To} {
+		if value := c.Query(name); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return query, fmt.Errorf("%s must be an RFC 3339 date", name)
+			}
+			*target = &parsed
+		}
+	}
+
+	return query, nil
+}
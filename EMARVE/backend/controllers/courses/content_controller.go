@@ -0,0 +1,264 @@
+package courses
+
+import (
+	courseDomain "backend/domain"
+	"backend/middleware"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (cc *CourseController) ListModules(c *gin.Context) {
+	courseID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	modules, err := cc.courseService.ListModules(courseID, middleware.GetActor(c))
+	if err != nil {
+		respondContentError(c, err, "error getting modules")
+		return
+	}
+
+	c.JSON(http.StatusOK, courseDomain.ModuleList{Result: modules})
+}
+
+func (cc *CourseController) CreateModule(c *gin.Context) {
+	courseID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	var request courseDomain.ModuleRequest
+	if !bindContentRequest(c, &request) {
+		return
+	}
+
+	module, err := cc.courseService.CreateModule(courseID, request, middleware.GetActor(c))
+	if err != nil {
+		respondContentError(c, err, "error creating module")
+		return
+	}
+
+	c.JSON(http.StatusCreated, module)
+}
+
+func (cc *CourseController) UpdateModule(c *gin.Context) {
+	courseID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	moduleID, ok := pathID(c, "moduleId")
+	if !ok {
+		return
+	}
+
+	var request courseDomain.ModuleRequest
+	if !bindContentRequest(c, &request) {
+		return
+	}
+
+	module, err := cc.courseService.UpdateModule(courseID, moduleID, request, middleware.GetActor(c))
+	if err != nil {
+		respondContentError(c, err, "error updating module")
+		return
+	}
+
+	c.JSON(http.StatusOK, module)
+}
+
+func (cc *CourseController) DeleteModule(c *gin.Context) {
+	courseID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	moduleID, ok := pathID(c, "moduleId")
+	if !ok {
+		return
+	}
+
+	if err := cc.courseService.DeleteModule(courseID, moduleID, middleware.GetActor(c)); err != nil {
+		respondContentError(c, err, "error deleting module")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (cc *CourseController) ListLessons(c *gin.Context) {
+	courseID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	lessons, err := cc.courseService.ListLessons(courseID, middleware.GetActor(c))
+	if err != nil {
+		respondContentError(c, err, "error getting lessons")
+		return
+	}
+
+	c.JSON(http.StatusOK, courseDomain.LessonList{Result: lessons})
+}
+
+func (cc *CourseController) GetLesson(c *gin.Context) {
+	courseID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	lessonID, ok := pathID(c, "lessonId")
+	if !ok {
+		return
+	}
+
+	lesson, err := cc.courseService.GetLesson(courseID, lessonID, middleware.GetActor(c))
+	if err != nil {
+		respondContentError(c, err, "error getting lesson")
+		return
+	}
+
+	c.JSON(http.StatusOK, lesson)
+}
+
+// DownloadCourseFile entrega un archivo subido al curso a quien puede ver su contenido
+func (cc *CourseController) DownloadCourseFile(c *gin.Context) {
+	courseID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	fileID, ok := pathID(c, "fileId")
+	if !ok {
+		return
+	}
+
+	file, path, err := cc.courseService.GetCourseFile(courseID, fileID, middleware.GetActor(c))
+	if err != nil {
+		respondContentError(c, err, "error getting file")
+		return
+	}
+
+	c.FileAttachment(path, file.Name)
+}
+
+func (cc *CourseController) CreateLesson(c *gin.Context) {
+	courseID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	var request courseDomain.LessonRequest
+	if !bindContentRequest(c, &request) {
+		return
+	}
+
+	lesson, err := cc.courseService.CreateLesson(courseID, request, middleware.GetActor(c))
+	if err != nil {
+		respondContentError(c, err, "error creating lesson")
+		return
+	}
+
+	c.JSON(http.StatusCreated, lesson)
+}
+
+func (cc *CourseController) UpdateLesson(c *gin.Context) {
+	courseID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	lessonID, ok := pathID(c, "lessonId")
+	if !ok {
+		return
+	}
+
+	var request courseDomain.LessonRequest
+	if !bindContentRequest(c, &request) {
+		return
+	}
+
+	lesson, err := cc.courseService.UpdateLesson(courseID, lessonID, request, middleware.GetActor(c))
+	if err != nil {
+		respondContentError(c, err, "error updating lesson")
+		return
+	}
+
+	c.JSON(http.StatusOK, lesson)
+}
+
+func (cc *CourseController) DeleteLesson(c *gin.Context) {
+	courseID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	lessonID, ok := pathID(c, "lessonId")
+	if !ok {
+		return
+	}
+
+	if err := cc.courseService.DeleteLesson(courseID, lessonID, middleware.GetActor(c)); err != nil {
+		respondContentError(c, err, "error deleting lesson")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (cc *CourseController) ReorderLessons(c *gin.Context) {
+	courseID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	var request courseDomain.LessonOrderRequest
+	if !bindContentRequest(c, &request) {
+		return
+	}
+
+	lessons, err := cc.courseService.ReorderLessons(courseID, request.LessonIDs, middleware.GetActor(c))
+	if err != nil {
+		respondContentError(c, err, "error reordering lessons")
+		return
+	}
+
+	c.JSON(http.StatusOK, courseDomain.LessonList{Result: lessons})
+}
+
+// pathID lee un id de la ruta y responde 400 si no es válido
+func pathID(c *gin.Context, name string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param(name), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, courseDomain.Result{
+			Message: fmt.Sprintf("invalid %s: %s", name, err.Error()),
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+func bindContentRequest(c *gin.Context, request interface{}) bool {
+	if err := c.ShouldBindJSON(request); err != nil {
+		c.JSON(http.StatusBadRequest, courseDomain.Result{
+			Message: fmt.Sprintf("invalid request: %s", err.Error()),
+		})
+		return false
+	}
+	return true
+}
+
+// respondContentError traduce los errores del contenido y del estado de un curso a su código HTTP
+func respondContentError(c *gin.Context, err error, message string) {
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, courseDomain.ErrCourseForbidden), errors.Is(err, courseDomain.ErrNotSubscribed):
+		status = http.StatusForbidden
+	case errors.Is(err, courseDomain.ErrCourseNotFound), errors.Is(err, courseDomain.ErrContentNotFound),
+		errors.Is(err, courseDomain.ErrRevisionNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, courseDomain.ErrCourseArchived), errors.Is(err, courseDomain.ErrInvalidStatusTransition):
+		status = http.StatusConflict
+	}
+	c.JSON(status, courseDomain.Result{
+		Message: fmt.Sprintf("%s: %s", message, err.Error()),
+	})
+}
@@ -0,0 +1,111 @@
+package courses
+
+import (
+	courseDomain "backend/domain"
+	"backend/middleware"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listCourses resuelve el listado y la búsqueda, que solo difieren en la operación del servicio
+func (cc *CourseController) listCourses(c *gin.Context, list func(courseDomain.CourseQuery, courseDomain.Actor) (courseDomain.SearchResponse, error)) {
+	query, err := parseCourseQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, courseDomain.Result{
+			Message: fmt.Sprintf("invalid query: %s", err.Error()),
+		})
+		return
+	}
+
+	page, err := list(query, middleware.GetActor(c))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, courseDomain.ErrInvalidCourseQuery) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, courseDomain.Result{
+			Message: fmt.Sprintf("error in search: %s", err.Error()),
+		})
+		return
+	}
+
+	if int64(page.Page)*int64(page.PageSize) < page.Total {
+		page.Next = pageLink(c, page.Page+1)
+	}
+	if page.Page > 1 {
+		page.Prev = pageLink(c, page.Page-1)
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (cc *CourseController) SuggestCourses(c *gin.Context) {
+	limit := 0
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, courseDomain.Result{
+				Message: "invalid query: limit must be a positive number",
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	suggestions, err := cc.courseService.SuggestCourses(c.Query("prefix"), limit, middleware.GetActor(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, courseDomain.Result{
+			Message: fmt.Sprintf("error in suggest: %s", err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, courseDomain.SuggestResponse{Result: suggestions})
+}
+
+// parseCourseQuery lee los filtros, el orden y la paginación de la query string
+func parseCourseQuery(c *gin.Context) (courseDomain.CourseQuery, error) {
+	query := courseDomain.CourseQuery{
+		Query:           strings.TrimSpace(c.Query("query")),
+		Categories:      c.QueryArray("category"),
+		Instructors:     c.QueryArray("instructor"),
+		DurationBuckets: c.QueryArray("duration"),
+		Sort:            c.Query("sort"),
+	}
+
+	for name, target := range map[string]*int{"page": &query.Page, "page_size": &query.PageSize} {
+		if value := c.Query(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 1 {
+				return query, fmt.Errorf("%s must be a positive number", name)
+			}
+			*target = parsed
+		}
+	}
+
+	for name, target := range map[string]*int64{"min_duration": &query.MinDuration, "max_duration": &query.MaxDuration} {
+		if value := c.Query(name); value != "" {
+			parsed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || parsed < 0 {
+				return query, fmt.Errorf("%s must be a non-negative number", name)
+			}
+			*target = parsed
+		}
+	}
+
+	return query, nil
+}
+
+// pageLink arma el link a otra página conservando el resto de los parámetros
+func pageLink(c *gin.Context, page int) string {
+	link := *c.Request.URL
+	values := link.Query()
+	values.Set("page", strconv.Itoa(page))
+	link.RawQuery = values.Encode()
+	return link.RequestURI()
+}
@@ -3,10 +3,12 @@ package courses
 import (
 	courseDomain "backend/domain"
 	"backend/interfaces"
+	"backend/middleware"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -20,20 +22,7 @@ func NewCourseController(courseService interfaces.CourseServiceInterface) *Cours
 }
 
 func (cc *CourseController) SearchCourse(c *gin.Context) {
-
-	query := strings.TrimSpace(c.Query("query"))
-	results, err := cc.courseService.SearchCourse(query)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, courseDomain.Result{
-			Message: fmt.Sprintf("Error in search: %s", err.Error()),
-		})
-		return
-	}
-
-	c.JSON(http.StatusOK, courseDomain.SearchResponse{
-		Result: results,
-	})
-
+	cc.listCourses(c, cc.courseService.SearchCourse)
 }
 
 func (cc *CourseController) GetCourse(c *gin.Context) {
@@ -46,7 +35,7 @@ func (cc *CourseController) GetCourse(c *gin.Context) {
 		return
 	}
 
-	course, err := cc.courseService.GetCourse(id)
+	course, err := cc.courseService.GetCourse(id, middleware.GetActor(c))
 
 	if err != nil {
 		c.JSON(http.StatusNotFound, courseDomain.Result{
@@ -61,18 +50,7 @@ func (cc *CourseController) GetCourse(c *gin.Context) {
 }
 
 func (cc *CourseController) GetAllCourses(c *gin.Context) {
-
-	results, err := cc.courseService.GetAllCourses()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, courseDomain.Result{
-			Message: fmt.Sprintf("error in search: %s", err.Error()),
-		})
-		return
-	}
-
-	c.JSON(http.StatusOK, courseDomain.SearchResponse{
-		Result: results,
-	})
+	cc.listCourses(c, cc.courseService.GetAllCourses)
 }
 
 func (cc *CourseController) GetCourseImages(c *gin.Context) {
@@ -84,7 +62,13 @@ func (cc *CourseController) GetCourseImages(c *gin.Context) {
 		return
 	}
 
-	images, err := cc.courseService.GetCourseImages(id)
+	images, err := cc.courseService.GetCourseImages(id, middleware.GetActor(c))
+	if errors.Is(err, courseDomain.ErrCourseForbidden) || errors.Is(err, courseDomain.ErrNotSubscribed) {
+		c.JSON(http.StatusForbidden, courseDomain.Result{
+			Message: fmt.Sprintf("forbidden: %s", err.Error()),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusNotFound, courseDomain.Result{
 			Message: fmt.Sprintf("error getting images for course %d: %s", id, err.Error()),
@@ -106,7 +90,7 @@ func (cc *CourseController) GetCoursesByInstructor(c *gin.Context) {
 		return
 	}
 
-	results, err := cc.courseService.GetCoursesByInstructor(instructor)
+	results, err := cc.courseService.GetCoursesByInstructor(instructor, middleware.GetActor(c))
 	if err != nil {
 		c.JSON(http.StatusNotFound, courseDomain.Result{
 			Message: fmt.Sprintf("error getting courses for instructor %s: %s", instructor, err.Error()),
@@ -129,7 +113,15 @@ func (cc *CourseController) Subscription(c *gin.Context) {
 		return
 	}
 
-	if err := cc.courseService.Subscription(subscribeRequest.UserId, subscribeRequest.CourseId); err != nil {
+	userID, err := middleware.ActingUserID(c, subscribeRequest.UserId, subscribeRequest.OnBehalfOf)
+	if err != nil {
+		c.JSON(http.StatusForbidden, courseDomain.Result{
+			Message: fmt.Sprintf("forbidden: %s", err.Error()),
+		})
+		return
+	}
+
+	if err := cc.courseService.Subscription(userID, subscribeRequest.CourseId, middleware.GetActor(c)); err != nil {
 		c.JSON(http.StatusConflict, courseDomain.Result{
 			Message: fmt.Sprintf("error in subscription: %s", err.Error()),
 		})
@@ -137,7 +129,7 @@ func (cc *CourseController) Subscription(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusCreated, courseDomain.Result{
-		Message: fmt.Sprintf("successful subscription of user %d to course %d", subscribeRequest.UserId, subscribeRequest.CourseId),
+		Message: fmt.Sprintf("successful subscription of user %d to course %d", userID, subscribeRequest.CourseId),
 	})
 
 }
@@ -152,7 +144,7 @@ func (cc *CourseController) CreateCourse(c *gin.Context) {
 		return
 	}
 
-	if err := cc.courseService.CreateCourse(courseRequest.Title, courseRequest.Description, courseRequest.Category, courseRequest.Instructor, courseRequest.Duration, courseRequest.Requirement); err != nil {
+	if _, err := cc.courseService.CreateCourse(courseRequest.Title.Value, courseRequest.Description.Value, courseRequest.Category.Value, courseRequest.Instructor.Value, courseRequest.Duration.Value, courseRequest.Requirement.Value, middleware.GetActor(c)); err != nil {
 		c.JSON(http.StatusConflict, courseDomain.Result{
 			Message: fmt.Sprintf("error in creating course: %s", err.Error()),
 		})
@@ -160,7 +152,7 @@ func (cc *CourseController) CreateCourse(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusCreated, courseDomain.Result{
-		Message: fmt.Sprintf("successful creation of course: %s", courseRequest.Title),
+		Message: fmt.Sprintf("successful creation of course: %s", courseRequest.Title.Value),
 	})
 }
 
@@ -182,7 +174,14 @@ func (cc *CourseController) UpdateCourse(c *gin.Context) {
 		return
 	}
 
-	if err := cc.courseService.UpdateCourse(id, updateRequest.Title, updateRequest.Description, updateRequest.Category, updateRequest.Instructor, updateRequest.Duration, updateRequest.Requirement); err != nil {
+	err = cc.courseService.UpdateCourse(id, updateRequest.Title.Value, updateRequest.Description.Value, updateRequest.Category.Value, updateRequest.Instructor.Value, updateRequest.Duration.Value, updateRequest.Requirement.Value, middleware.GetActor(c))
+	if errors.Is(err, courseDomain.ErrCourseForbidden) {
+		c.JSON(http.StatusForbidden, courseDomain.Result{
+			Message: fmt.Sprintf("forbidden: %s", err.Error()),
+		})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusConflict, courseDomain.Result{
 			Message: fmt.Sprintf("error updating: %s", err.Error()),
 		})
@@ -190,11 +189,45 @@ func (cc *CourseController) UpdateCourse(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, courseDomain.Result{
-		Message: fmt.Sprintf("successful update of course %s", updateRequest.Title),
+		Message: fmt.Sprintf("successful update of course %s", updateRequest.Title.Value),
 	})
 
 }
 
+// PatchCourse actualiza solo los campos que vienen en el cuerpo, que es un JSON Merge Patch
+// (RFC 7396). Campos desconocidos o que no se pueden editar se rechazan.
+func (cc *CourseController) PatchCourse(c *gin.Context) {
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	if contentType := c.ContentType(); contentType != "application/merge-patch+json" && contentType != "application/json" {
+		c.JSON(http.StatusUnsupportedMediaType, courseDomain.Result{
+			Message: "content type must be application/merge-patch+json",
+		})
+		return
+	}
+
+	var patch courseDomain.CourseRequest
+	decoder := json.NewDecoder(c.Request.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&patch); err != nil {
+		c.JSON(http.StatusBadRequest, courseDomain.Result{
+			Message: fmt.Sprintf("invalid request: %s", err.Error()),
+		})
+		return
+	}
+
+	course, err := cc.courseService.PatchCourse(id, patch, middleware.GetActor(c))
+	if err != nil {
+		respondContentError(c, err, "error updating course")
+		return
+	}
+
+	c.JSON(http.StatusOK, course)
+}
+
 func (cc *CourseController) DeleteCourse(c *gin.Context) {
 
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
@@ -206,8 +239,14 @@ func (cc *CourseController) DeleteCourse(c *gin.Context) {
 		return
 	}
 
-	err = cc.courseService.DeleteCourse(id)
+	err = cc.courseService.DeleteCourse(id, middleware.GetActor(c))
 
+	if errors.Is(err, courseDomain.ErrCourseForbidden) {
+		c.JSON(http.StatusForbidden, courseDomain.Result{
+			Message: fmt.Sprintf("forbidden: %s", err.Error()),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusNotFound, courseDomain.Result{
 			Message: fmt.Sprintf("error in delete: %s", err.Error()),
@@ -228,7 +267,7 @@ func (cc *CourseController) CommentList(c *gin.Context) {
 		return
 	}
 
-	results, err := cc.courseService.CommentList(id)
+	results, err := cc.courseService.CommentList(id, middleware.GetActor(c))
 	if err != nil {
 		c.JSON(http.StatusNotFound, courseDomain.Result{
 			Message: fmt.Sprintf("error in getting comments: %s", err.Error()),
@@ -241,3 +280,101 @@ func (cc *CourseController) CommentList(c *gin.Context) {
 	})
 
 }
+
+func (cc *CourseController) ListStaff(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, courseDomain.Result{
+			Message: fmt.Sprintf("invalid id: %s", err.Error()),
+		})
+		return
+	}
+
+	staff, err := cc.courseService.ListStaff(id, middleware.GetActor(c))
+	if errors.Is(err, courseDomain.ErrCourseNotFound) {
+		c.JSON(http.StatusNotFound, courseDomain.Result{
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, courseDomain.Result{
+			Message: fmt.Sprintf("error getting staff: %s", err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, courseDomain.StaffList{
+		Result: staff,
+	})
+}
+
+func (cc *CourseController) AddStaff(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, courseDomain.Result{
+			Message: fmt.Sprintf("invalid id: %s", err.Error()),
+		})
+		return
+	}
+
+	var staffRequest courseDomain.StaffRequest
+	if err := c.ShouldBindJSON(&staffRequest); err != nil {
+		c.JSON(http.StatusBadRequest, courseDomain.Result{
+			Message: fmt.Sprintf("invalid request: %s", err.Error()),
+		})
+		return
+	}
+
+	err = cc.courseService.AddStaff(id, staffRequest.UserID, staffRequest.Role, middleware.GetActor(c))
+	if errors.Is(err, courseDomain.ErrCourseForbidden) {
+		c.JSON(http.StatusForbidden, courseDomain.Result{
+			Message: fmt.Sprintf("forbidden: %s", err.Error()),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, courseDomain.Result{
+			Message: fmt.Sprintf("error adding staff: %s", err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, courseDomain.Result{
+		Message: fmt.Sprintf("user %d added to course %d as %s", staffRequest.UserID, id, staffRequest.Role),
+	})
+}
+
+func (cc *CourseController) RemoveStaff(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, courseDomain.Result{
+			Message: fmt.Sprintf("invalid id: %s", err.Error()),
+		})
+		return
+	}
+
+	userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, courseDomain.Result{
+			Message: fmt.Sprintf("invalid user id: %s", err.Error()),
+		})
+		return
+	}
+
+	err = cc.courseService.RemoveStaff(id, userID, middleware.GetActor(c))
+	if errors.Is(err, courseDomain.ErrCourseForbidden) {
+		c.JSON(http.StatusForbidden, courseDomain.Result{
+			Message: fmt.Sprintf("forbidden: %s", err.Error()),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusConflict, courseDomain.Result{
+			Message: fmt.Sprintf("error removing staff: %s", err.Error()),
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
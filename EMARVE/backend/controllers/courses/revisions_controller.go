@@ -0,0 +1,75 @@
+package courses
+
+import (
+	courseDomain "backend/domain"
+	"backend/middleware"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListRevisions devuelve el historial de cambios del curso, primero el más reciente
+func (cc *CourseController) ListRevisions(c *gin.Context) {
+	courseID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	revisions, err := cc.courseService.ListRevisions(courseID, middleware.GetActor(c))
+	if err != nil {
+		respondContentError(c, err, "error getting revisions")
+		return
+	}
+
+	c.JSON(http.StatusOK, courseDomain.RevisionList{Result: revisions})
+}
+
+// DiffRevisions compara las revisiones ?from= y ?to= campo por campo
+func (cc *CourseController) DiffRevisions(c *gin.Context) {
+	courseID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	from, errFrom := strconv.Atoi(c.Query("from"))
+	to, errTo := strconv.Atoi(c.Query("to"))
+	if errFrom != nil || errTo != nil {
+		c.JSON(http.StatusBadRequest, courseDomain.Result{
+			Message: "invalid query: from and to must be revision numbers",
+		})
+		return
+	}
+
+	diff, err := cc.courseService.DiffRevisions(courseID, from, to, middleware.GetActor(c))
+	if err != nil {
+		respondContentError(c, err, "error comparing revisions")
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// RestoreRevision vuelve el curso a la revisión indicada
+func (cc *CourseController) RestoreRevision(c *gin.Context) {
+	courseID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	number, err := strconv.Atoi(c.Param("rev"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, courseDomain.Result{
+			Message: "invalid rev: must be a revision number",
+		})
+		return
+	}
+
+	course, err := cc.courseService.RestoreRevision(courseID, number, middleware.GetActor(c))
+	if err != nil {
+		respondContentError(c, err, "error restoring revision")
+		return
+	}
+
+	c.JSON(http.StatusOK, course)
+}
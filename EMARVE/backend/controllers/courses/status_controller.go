@@ -0,0 +1,30 @@
+package courses
+
+import (
+	courseDomain "backend/domain"
+	"backend/middleware"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ChangeCourseStatus mueve el curso por el ciclo de publicación: draft, review, published y archived
+func (cc *CourseController) ChangeCourseStatus(c *gin.Context) {
+	courseID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	var request courseDomain.CourseStatusRequest
+	if !bindContentRequest(c, &request) {
+		return
+	}
+
+	course, err := cc.courseService.ChangeCourseStatus(courseID, request, middleware.GetActor(c))
+	if err != nil {
+		respondContentError(c, err, "error changing course status")
+		return
+	}
+
+	c.JSON(http.StatusOK, course)
+}
@@ -0,0 +1,38 @@
+package courses
+
+import (
+	courseDomain "backend/domain"
+	"backend/middleware"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListTrash devuelve los cursos borrados que todavía se pueden restaurar
+func (cc *CourseController) ListTrash(c *gin.Context) {
+	courses, err := cc.courseService.ListTrash()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, courseDomain.Result{
+			Message: fmt.Sprintf("error getting trash: %s", err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, courseDomain.ListResponse{Result: courses})
+}
+
+func (cc *CourseController) RestoreCourse(c *gin.Context) {
+	courseID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	course, err := cc.courseService.RestoreCourse(courseID, middleware.GetActor(c))
+	if err != nil {
+		respondContentError(c, err, "error restoring course")
+		return
+	}
+
+	c.JSON(http.StatusOK, course)
+}
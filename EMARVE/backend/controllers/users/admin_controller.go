@@ -0,0 +1,197 @@
+package users
+
+import (
+	userDomain "backend/domain"
+	"backend/middleware"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (uc *UserController) ListUsers(c *gin.Context) {
+	query := userDomain.UserQuery{
+		Email:    c.Query("email"),
+		Nickname: c.Query("nickname"),
+		Sort:     c.Query("sort"),
+	}
+
+	for name, target := range map[string]*int{"page": &query.Page, "page_size": &query.PageSize} {
+		if value := c.Query(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 1 {
+				c.JSON(http.StatusBadRequest, userDomain.Result{
+					Message: fmt.Sprintf("invalid query: %s must be a positive number", name),
+				})
+				return
+			}
+			*target = parsed
+		}
+	}
+
+	page, err := uc.userService.ListUsers(query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, userDomain.Result{
+			Message: fmt.Sprintf("error listing users: %s", err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (uc *UserController) PromoteUser(c *gin.Context) {
+	uc.changeRole(c, uc.userService.PromoteUser, "promoted")
+}
+
+func (uc *UserController) DemoteUser(c *gin.Context) {
+	uc.changeRole(c, uc.userService.DemoteUser, "demoted")
+}
+
+// changeRole resuelve promote y demote, que solo difieren en la operación del servicio
+func (uc *UserController) changeRole(c *gin.Context, change func(int64, string, userDomain.Actor) error, verb string) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, userDomain.Result{
+			Message: fmt.Sprintf("invalid id: %s", err.Error()),
+		})
+		return
+	}
+
+	// Sin body se promueve o degrada el rol admin
+	var roleRequest userDomain.RoleChangeRequest
+	if err := c.ShouldBindJSON(&roleRequest); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, userDomain.Result{
+			Message: fmt.Sprintf("Invalid request: %s", err.Error()),
+		})
+		return
+	}
+
+	if err := change(id, roleRequest.Role, middleware.GetActor(c)); err != nil {
+		c.JSON(http.StatusConflict, userDomain.Result{
+			Message: fmt.Sprintf("error updating roles: %s", err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, userDomain.Result{
+		Message: fmt.Sprintf("User %d %s", id, verb),
+	})
+}
+
+func (uc *UserController) DisableUser(c *gin.Context) {
+	uc.adminAction(c, uc.userService.DisableUser, "disabled")
+}
+
+func (uc *UserController) EnableUser(c *gin.Context) {
+	uc.adminAction(c, uc.userService.EnableUser, "enabled")
+}
+
+func (uc *UserController) ForcePasswordReset(c *gin.Context) {
+	uc.adminAction(c, uc.userService.ForcePasswordReset, "must reset the password")
+}
+
+// adminAction resuelve las acciones de admin sobre un usuario que no reciben body
+func (uc *UserController) adminAction(c *gin.Context, action func(int64, userDomain.Actor) error, result string) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, userDomain.Result{
+			Message: fmt.Sprintf("invalid id: %s", err.Error()),
+		})
+		return
+	}
+
+	if err := action(id, middleware.GetActor(c)); err != nil {
+		c.JSON(http.StatusConflict, userDomain.Result{
+			Message: fmt.Sprintf("error updating user: %s", err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, userDomain.Result{
+		Message: fmt.Sprintf("User %d %s", id, result),
+	})
+}
+
+// ImportUsers recibe el CSV en el campo "file". Por defecto solo valida: con dry_run=false
+// crea los usuarios y course_ids=1,2 los suscribe a esos cursos.
+func (uc *UserController) ImportUsers(c *gin.Context) {
+	options := userDomain.UserImportOptions{DryRun: true}
+
+	if value := c.Query("dry_run"); value != "" {
+		dryRun, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, userDomain.Result{
+				Message: "invalid query: dry_run must be true or false",
+			})
+			return
+		}
+		options.DryRun = dryRun
+	}
+
+	if value := c.Query("course_ids"); value != "" {
+		for _, part := range strings.Split(value, ",") {
+			courseID, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, userDomain.Result{
+					Message: fmt.Sprintf("invalid query: course id %q", part),
+				})
+				return
+			}
+			options.CourseIDs = append(options.CourseIDs, courseID)
+		}
+	}
+
+	file, _, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, userDomain.Result{
+			Message: fmt.Sprintf("error getting CSV file: %s", err.Error()),
+		})
+		return
+	}
+	defer file.Close()
+
+	report, err := uc.userService.ImportUsers(file, options, middleware.GetActor(c))
+	if errors.Is(err, userDomain.ErrImportInvalid) {
+		c.JSON(http.StatusUnprocessableEntity, report)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, userDomain.Result{
+			Message: fmt.Sprintf("error importing users: %s", err.Error()),
+		})
+		return
+	}
+
+	status := http.StatusOK
+	if !report.DryRun {
+		status = http.StatusCreated
+	}
+	c.JSON(status, report)
+}
+
+// ExportUsers descarga en CSV los usuarios, con los mismos filtros que el listado
+func (uc *UserController) ExportUsers(c *gin.Context) {
+	query := userDomain.UserQuery{
+		Email:    c.Query("email"),
+		Nickname: c.Query("nickname"),
+		Sort:     c.Query("sort"),
+	}
+
+	// Se arma en memoria para poder responder un error si falla antes de terminar
+	var buffer bytes.Buffer
+	if err := uc.userService.ExportUsersCSV(query, &buffer); err != nil {
+		c.JSON(http.StatusBadRequest, userDomain.Result{
+			Message: fmt.Sprintf("error exporting users: %s", err.Error()),
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="emarve-users.csv"`)
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buffer.Bytes())
+}
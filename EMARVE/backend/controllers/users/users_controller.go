@@ -3,11 +3,16 @@ package users
 import (
 	userDomain "backend/domain"
 	"backend/interfaces"
+	"backend/middleware"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
 )
 
 type UserController struct {
@@ -29,19 +34,406 @@ func (uc *UserController) Login(c *gin.Context) {
 		return
 	}
 
-	token, err := uc.userService.Login(loginRequest.Email, loginRequest.Password)
+	response, err := uc.userService.Login(loginRequest.Email, loginRequest.Password, clientInfo(c))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, userDomain.Result{
+		respondLoginError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// respondLoginError traduce los errores del login: 429/423 con Retry-After si está
+// bloqueado, 403 si falta verificar el email, la cuenta está deshabilitada o debe
+// cambiar la contraseña, y 401 para el resto
+func respondLoginError(c *gin.Context, err error) {
+	var throttled *userDomain.LoginThrottledError
+	if errors.As(err, &throttled) {
+		status := http.StatusTooManyRequests
+		if throttled.Locked {
+			status = http.StatusLocked
+		}
+		c.Header("Retry-After", strconv.Itoa(throttled.RetryAfterSeconds()))
+		c.JSON(status, userDomain.Result{
 			Message: fmt.Sprintf("Unauthorized login: %s", err.Error()),
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, userDomain.LoginResponse{
-		Token: token,
+	status := http.StatusUnauthorized
+	if errors.Is(err, userDomain.ErrEmailNotVerified) || errors.Is(err, userDomain.ErrAccountDisabled) || errors.Is(err, userDomain.ErrPasswordResetRequired) {
+		status = http.StatusForbidden
+	}
+	c.JSON(status, userDomain.Result{
+		Message: fmt.Sprintf("Unauthorized login: %s", err.Error()),
+	})
+}
+
+// CompleteMFALogin es el segundo paso del login para los usuarios con 2FA
+func (uc *UserController) CompleteMFALogin(c *gin.Context) {
+	var mfaRequest userDomain.MFALoginRequest
+
+	if err := c.ShouldBindJSON(&mfaRequest); err != nil {
+		c.JSON(http.StatusBadRequest, userDomain.Result{
+			Message: fmt.Sprintf(("Invalid request: %s"), err.Error()),
+		})
+		return
+	}
+
+	response, err := uc.userService.CompleteMFALogin(mfaRequest.MFAToken, mfaRequest.Code, clientInfo(c))
+	if err != nil {
+		respondLoginError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// BeginMFAEnrollment inicia la inscripción de 2FA de un admin que todavía no la tiene
+// y la política la exige, usando el mfa_token del primer paso del login
+func (uc *UserController) BeginMFAEnrollment(c *gin.Context) {
+	var mfaRequest userDomain.MFALoginRequest
+
+	if err := c.ShouldBindJSON(&mfaRequest); err != nil {
+		c.JSON(http.StatusBadRequest, userDomain.Result{
+			Message: fmt.Sprintf(("Invalid request: %s"), err.Error()),
+		})
+		return
+	}
+
+	enrollment, err := uc.userService.BeginMFAEnrollment(mfaRequest.MFAToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, userDomain.Result{
+			Message: fmt.Sprintf("error in two-factor enrollment: %s", err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+func (uc *UserController) BeginTOTPEnrollment(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	enrollment, err := uc.userService.BeginTOTPEnrollment(userID)
+	if err != nil {
+		c.JSON(http.StatusConflict, userDomain.Result{
+			Message: fmt.Sprintf("error in two-factor enrollment: %s", err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+func (uc *UserController) ConfirmTOTPEnrollment(c *gin.Context) {
+	var codeRequest userDomain.TOTPCodeRequest
+
+	if err := c.ShouldBindJSON(&codeRequest); err != nil {
+		c.JSON(http.StatusBadRequest, userDomain.Result{
+			Message: fmt.Sprintf(("Invalid request: %s"), err.Error()),
+		})
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+
+	codes, err := uc.userService.ConfirmTOTPEnrollment(userID, codeRequest.Code)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, userDomain.Result{
+			Message: fmt.Sprintf("error confirming two-factor enrollment: %s", err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, userDomain.RecoveryCodesResponse{
+		RecoveryCodes: codes,
+	})
+}
+
+func (uc *UserController) DisableTOTP(c *gin.Context) {
+	var codeRequest userDomain.TOTPCodeRequest
+
+	if err := c.ShouldBindJSON(&codeRequest); err != nil {
+		c.JSON(http.StatusBadRequest, userDomain.Result{
+			Message: fmt.Sprintf(("Invalid request: %s"), err.Error()),
+		})
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+
+	if err := uc.userService.DisableTOTP(userID, codeRequest.Code); err != nil {
+		c.JSON(http.StatusBadRequest, userDomain.Result{
+			Message: fmt.Sprintf("error disabling two-factor authentication: %s", err.Error()),
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (uc *UserController) RefreshToken(c *gin.Context) {
+	var refreshRequest userDomain.RefreshRequest
+
+	if err := c.ShouldBindJSON(&refreshRequest); err != nil {
+		c.JSON(http.StatusBadRequest, userDomain.Result{
+			Message: fmt.Sprintf(("Invalid request: %s"), err.Error()),
+		})
+		return
+	}
+
+	response, err := uc.userService.RefreshToken(refreshRequest.RefreshToken, clientInfo(c))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, userDomain.Result{
+			Message: fmt.Sprintf("Unauthorized refresh: %s", err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (uc *UserController) Logout(c *gin.Context) {
+	sessionID, _ := middleware.GetSessionID(c)
+
+	if err := uc.userService.Logout(sessionID); err != nil {
+		c.JSON(http.StatusInternalServerError, userDomain.Result{
+			Message: fmt.Sprintf("error in logout: %s", err.Error()),
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (uc *UserController) ListSessions(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	sessionID, _ := middleware.GetSessionID(c)
+
+	results, err := uc.userService.ListSessions(userID, sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, userDomain.Result{
+			Message: fmt.Sprintf("error in getting sessions: %s", err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, userDomain.SessionList{
+		Result: results,
 	})
 }
 
+func (uc *UserController) RevokeSession(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, userDomain.Result{
+			Message: fmt.Sprintf("invalid id: %s", err.Error()),
+		})
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+
+	if err := uc.userService.RevokeSession(userID, id); err != nil {
+		c.JSON(http.StatusNotFound, userDomain.Result{
+			Message: fmt.Sprintf("error revoking session: %s", err.Error()),
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// JWKS publica las claves públicas de firma de tokens para otros servicios
+func (uc *UserController) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, uc.userService.JWKS())
+}
+
+// ForgotPassword responde siempre lo mismo, exista o no el email, para no revelar qué cuentas existen
+func (uc *UserController) ForgotPassword(c *gin.Context) {
+	var forgotRequest userDomain.ForgotPasswordRequest
+
+	if err := c.ShouldBindJSON(&forgotRequest); err != nil {
+		c.JSON(http.StatusBadRequest, userDomain.Result{
+			Message: fmt.Sprintf(("Invalid request: %s"), err.Error()),
+		})
+		return
+	}
+
+	if strings.TrimSpace(forgotRequest.Email) == "" {
+		c.JSON(http.StatusBadRequest, userDomain.Result{
+			Message: "Invalid request: email is required",
+		})
+		return
+	}
+
+	if err := uc.userService.ForgotPassword(forgotRequest.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, userDomain.Result{
+			Message: fmt.Sprintf("error in password recovery: %s", err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, userDomain.Result{
+		Message: "If the email is registered, a password reset link has been sent",
+	})
+}
+
+func (uc *UserController) ResetPassword(c *gin.Context) {
+	var resetRequest userDomain.ResetPasswordRequest
+
+	if err := c.ShouldBindJSON(&resetRequest); err != nil {
+		c.JSON(http.StatusBadRequest, userDomain.Result{
+			Message: fmt.Sprintf(("Invalid request: %s"), err.Error()),
+		})
+		return
+	}
+
+	if err := uc.userService.ResetPassword(resetRequest.Token, resetRequest.Password); err != nil {
+		c.JSON(http.StatusBadRequest, userDomain.Result{
+			Message: fmt.Sprintf("error in password reset: %s", err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, userDomain.Result{
+		Message: "Password updated successfully",
+	})
+}
+
+// VerifyEmail activa la cuenta con el token del link enviado al registrarse
+func (uc *UserController) VerifyEmail(c *gin.Context) {
+	if err := uc.userService.VerifyEmail(c.Query("token")); err != nil {
+		c.JSON(http.StatusBadRequest, userDomain.Result{
+			Message: fmt.Sprintf("error in email verification: %s", err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, userDomain.Result{
+		Message: "Email verified successfully",
+	})
+}
+
+func (uc *UserController) ResendVerification(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, userDomain.Result{
+			Message: fmt.Sprintf("invalid id: %s", err.Error()),
+		})
+		return
+	}
+
+	if err := uc.userService.ResendVerification(id); err != nil {
+		c.JSON(http.StatusConflict, userDomain.Result{
+			Message: fmt.Sprintf("error resending verification: %s", err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, userDomain.Result{
+		Message: fmt.Sprintf("Verification email sent to user %d", id),
+	})
+}
+
+func (uc *UserController) MarkEmailVerified(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, userDomain.Result{
+			Message: fmt.Sprintf("invalid id: %s", err.Error()),
+		})
+		return
+	}
+
+	if err := uc.userService.MarkEmailVerified(id, middleware.GetActor(c)); err != nil {
+		c.JSON(http.StatusNotFound, userDomain.Result{
+			Message: fmt.Sprintf("error verifying email: %s", err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, userDomain.Result{
+		Message: fmt.Sprintf("Email of user %d verified", id),
+	})
+}
+
+// UnlockUser quita el bloqueo por intentos fallidos de login de una cuenta
+func (uc *UserController) UnlockUser(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, userDomain.Result{
+			Message: fmt.Sprintf("invalid id: %s", err.Error()),
+		})
+		return
+	}
+
+	if err := uc.userService.UnlockUser(id, middleware.GetActor(c)); err != nil {
+		c.JSON(http.StatusNotFound, userDomain.Result{
+			Message: fmt.Sprintf("error unlocking user: %s", err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, userDomain.Result{
+		Message: fmt.Sprintf("User %d unlocked", id),
+	})
+}
+
+// ListRoles lista los roles con sus permisos
+func (uc *UserController) ListRoles(c *gin.Context) {
+	roles, err := uc.userService.ListRoles()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, userDomain.Result{
+			Message: fmt.Sprintf("error in getting roles: %s", err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, userDomain.RoleList{
+		Result: roles,
+	})
+}
+
+// SetUserRoles reemplaza los roles de un usuario
+func (uc *UserController) SetUserRoles(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, userDomain.Result{
+			Message: fmt.Sprintf("invalid id: %s", err.Error()),
+		})
+		return
+	}
+
+	var rolesRequest userDomain.SetRolesRequest
+	if err := c.ShouldBindJSON(&rolesRequest); err != nil {
+		c.JSON(http.StatusBadRequest, userDomain.Result{
+			Message: fmt.Sprintf("Invalid request: %s", err.Error()),
+		})
+		return
+	}
+
+	if err := uc.userService.SetUserRoles(id, rolesRequest.Roles, middleware.GetActor(c)); err != nil {
+		c.JSON(http.StatusConflict, userDomain.Result{
+			Message: fmt.Sprintf("error updating roles: %s", err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, userDomain.Result{
+		Message: fmt.Sprintf("Roles of user %d updated", id),
+	})
+}
+
+// clientInfo extrae del request los datos del cliente que se guardan en la sesión
+func clientInfo(c *gin.Context) userDomain.ClientInfo {
+	return userDomain.ClientInfo{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
+
 func (uc *UserController) UserRegister(c *gin.Context) {
 	var registrationRequest userDomain.RegistrationRequest
 
@@ -75,6 +467,13 @@ func (uc *UserController) SubscriptionList(c *gin.Context) {
 		return
 	}
 
+	if !middleware.CanAccessUser(c, id) {
+		c.JSON(http.StatusForbidden, userDomain.Result{
+			Message: fmt.Sprintf("forbidden: %s", userDomain.ErrAccountForbidden.Error()),
+		})
+		return
+	}
+
 	results, err := uc.userService.SubscriptionList(id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, userDomain.Result{
@@ -98,7 +497,15 @@ func (uc *UserController) AddComment(c *gin.Context) {
 		return
 	}
 
-	if err := uc.userService.AddComment(commentRequest.UserID, commentRequest.CourseID, commentRequest.Comment); err != nil {
+	userID, err := middleware.ActingUserID(c, commentRequest.UserID, commentRequest.OnBehalfOf)
+	if err != nil {
+		c.JSON(http.StatusForbidden, userDomain.Result{
+			Message: fmt.Sprintf("forbidden: %s", err.Error()),
+		})
+		return
+	}
+
+	if err := uc.userService.AddComment(userID, commentRequest.CourseID, commentRequest.Comment); err != nil {
 		c.JSON(http.StatusConflict, userDomain.Result{
 			Message: fmt.Sprintf("error in course comment: %s", err.Error()),
 		})
@@ -106,7 +513,7 @@ func (uc *UserController) AddComment(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusCreated, userDomain.Result{
-		Message: fmt.Sprintf("successful comment of user %d to course %d", commentRequest.UserID, commentRequest.CourseID),
+		Message: fmt.Sprintf("successful comment of user %d to course %d", userID, commentRequest.CourseID),
 	})
 }
 
@@ -123,11 +530,31 @@ func (uc *UserController) UploadFiles(c *gin.Context) {
 	}
 	defer file.Close()
 
-	userIDStr := c.PostForm("user_id")
-	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	// user_id es opcional y, si viene, debe coincidir con el usuario del token
+	var claimedUserID, onBehalfOf int64
+	if userIDStr := c.PostForm("user_id"); userIDStr != "" {
+		claimedUserID, err = strconv.ParseInt(userIDStr, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, userDomain.Result{
+				Message: fmt.Sprintf("Invalid user ID: %s", err.Error()),
+			})
+			return
+		}
+	}
+	if onBehalfStr := c.PostForm("on_behalf_of"); onBehalfStr != "" {
+		onBehalfOf, err = strconv.ParseInt(onBehalfStr, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, userDomain.Result{
+				Message: fmt.Sprintf("Invalid on_behalf_of: %s", err.Error()),
+			})
+			return
+		}
+	}
+
+	userID, err := middleware.ActingUserID(c, claimedUserID, onBehalfOf)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, userDomain.Result{
-			Message: fmt.Sprintf("Invalid user ID: %s", err.Error()),
+		c.JSON(http.StatusForbidden, userDomain.Result{
+			Message: fmt.Sprintf("forbidden: %s", err.Error()),
 		})
 		return
 	}
@@ -141,7 +568,19 @@ func (uc *UserController) UploadFiles(c *gin.Context) {
 		return
 	}
 
-	err = uc.userService.UploadFiles(file, handler.Filename, userID, courseID)
+	err = uc.userService.UploadFiles(file, handler.Filename, userID, courseID, middleware.GetActor(c))
+	if errors.Is(err, userDomain.ErrCourseNotFound) {
+		c.JSON(http.StatusNotFound, userDomain.Result{
+			Message: err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, userDomain.ErrCourseForbidden) {
+		c.JSON(http.StatusForbidden, userDomain.Result{
+			Message: fmt.Sprintf("forbidden: %s", err.Error()),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, userDomain.Result{
 			Message: fmt.Sprintf("Error al guardar el archivo: %s", err.Error()),
@@ -185,6 +624,13 @@ func (uc *UserController) GetUserById(c *gin.Context) {
 		return
 	}
 
+	if !middleware.CanAccessUser(c, id) {
+		c.JSON(http.StatusForbidden, userDomain.Result{
+			Message: fmt.Sprintf("forbidden: %s", userDomain.ErrAccountForbidden.Error()),
+		})
+		return
+	}
+
 	user, err := uc.userService.GetUserById(id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, userDomain.Result{
@@ -193,14 +639,134 @@ func (uc *UserController) GetUserById(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, userDomain.UserResponse{
-		Id:       user.Id,
-		Nickname: user.Nickname,
-		Email:    user.Email,
-		Type:     user.Type,
+	c.JSON(http.StatusOK, userDomain.NewUserResponse(user))
+}
+
+func (uc *UserController) UpdateUser(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, userDomain.Result{
+			Message: fmt.Sprintf("invalid id: %s", err.Error()),
+		})
+		return
+	}
+
+	var updateRequest userDomain.UpdateUserRequest
+	if err := c.ShouldBindJSON(&updateRequest); err != nil {
+		c.JSON(http.StatusBadRequest, userDomain.Result{
+			Message: fmt.Sprintf("Invalid request: %s", err.Error()),
+		})
+		return
+	}
+
+	user, err := uc.userService.UpdateUser(id, updateRequest, middleware.GetActor(c))
+	if err != nil {
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, userDomain.ErrAccountForbidden), errors.Is(err, userDomain.ErrInvalidCurrentPassword):
+			status = http.StatusForbidden
+		case errors.Is(err, userDomain.ErrNicknameTaken), errors.Is(err, userDomain.ErrEmailTaken):
+			status = http.StatusConflict
+		}
+		c.JSON(status, userDomain.Result{
+			Message: fmt.Sprintf("error updating user: %s", err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, userDomain.NewUserResponse(user))
+}
+
+func (uc *UserController) ConfirmEmailChange(c *gin.Context) {
+	if err := uc.userService.ConfirmEmailChange(c.Query("token")); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, userDomain.ErrEmailTaken) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, userDomain.Result{
+			Message: fmt.Sprintf("error confirming email change: %s", err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, userDomain.Result{
+		Message: "Email updated successfully",
 	})
 }
 
+func (uc *UserController) ExportUserData(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, userDomain.Result{
+			Message: fmt.Sprintf("invalid id: %s", err.Error()),
+		})
+		return
+	}
+
+	export, err := uc.userService.ExportUserData(id, middleware.GetActor(c))
+	if err != nil {
+		status := http.StatusNotFound
+		if errors.Is(err, userDomain.ErrAccountForbidden) {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, userDomain.Result{
+			Message: fmt.Sprintf("error exporting user data: %s", err.Error()),
+		})
+		return
+	}
+
+	// ?format=zip incluye una copia de los archivos subidos además del JSON
+	if c.Query("format") != "zip" {
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="emarve-user-%d.json"`, id))
+		c.JSON(http.StatusOK, export)
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="emarve-user-%d.zip"`, id))
+	c.Status(http.StatusOK)
+	if err := uc.userService.WriteExportArchive(export, c.Writer); err != nil {
+		// Los headers ya se enviaron, así que el error solo puede quedar en el log
+		log.Errorf("error writing export archive of user %d: %v", id, err)
+	}
+}
+
+func (uc *UserController) DeleteUser(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, userDomain.Result{
+			Message: fmt.Sprintf("invalid id: %s", err.Error()),
+		})
+		return
+	}
+
+	// El body es opcional: solo hace falta cuando el usuario borra su propia cuenta
+	var deleteRequest userDomain.DeleteUserRequest
+	if err := c.ShouldBindJSON(&deleteRequest); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, userDomain.Result{
+			Message: fmt.Sprintf("Invalid request: %s", err.Error()),
+		})
+		return
+	}
+
+	err = uc.userService.DeleteUser(id, deleteRequest.CurrentPassword, middleware.GetActor(c))
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, userDomain.ErrAccountForbidden) || errors.Is(err, userDomain.ErrInvalidCurrentPassword) {
+			status = http.StatusForbidden
+		}
+		if errors.Is(err, userDomain.ErrLastCourseOwner) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, userDomain.Result{
+			Message: fmt.Sprintf("error deleting user: %s", err.Error()),
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
 func (uc *UserController) GetUserID(c *gin.Context) {
 	authHeader := c.GetHeader("Authorization")
 	if authHeader == "" {
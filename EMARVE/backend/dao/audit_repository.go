@@ -0,0 +1,26 @@
+package dao
+
+import (
+	"backend/clients"
+	"backend/domain"
+	"backend/interfaces"
+)
+
+// AuditRepository implementa AuditRepositoryInterface
+type AuditRepository struct {
+	dbClient interfaces.DatabaseClientInterface
+}
+
+func NewAuditRepository() interfaces.AuditRepositoryInterface {
+	return &AuditRepository{
+		dbClient: clients.NewDatabaseClient(),
+	}
+}
+
+func (r *AuditRepository) CreateAuditEvent(event domain.AuditEvent) error {
+	return r.dbClient.CreateAuditEvent(event)
+}
+
+func (r *AuditRepository) ListAuditEvents(query domain.AuditQuery) ([]domain.AuditEvent, int64, error) {
+	return r.dbClient.ListAuditEvents(query)
+}
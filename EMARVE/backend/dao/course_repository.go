@@ -4,6 +4,7 @@ import (
 	"backend/clients"
 	"backend/domain"
 	"backend/interfaces"
+	"time"
 )
 
 // CourseRepository implementa CourseRepositoryInterface
@@ -17,16 +18,16 @@ func NewCourseRepository() interfaces.CourseRepositoryInterface {
 	}
 }
 
-func (r *CourseRepository) GetCoursewithQuery(query string) ([]domain.Course, error) {
-	return r.dbClient.GetCoursewithQuery(query)
-}
-
 func (r *CourseRepository) GetCourseById(id int64) (*domain.Course, error) {
 	return r.dbClient.GetCourseById(id)
 }
 
-func (r *CourseRepository) GetCourses() ([]domain.Course, error) {
-	return r.dbClient.GetCourses()
+func (r *CourseRepository) GetCourses(query domain.CourseQuery) ([]domain.Course, int64, error) {
+	return r.dbClient.GetCourses(query)
+}
+
+func (r *CourseRepository) GetSubscriptionCounts(courseIDs []int64) (map[int64]int64, error) {
+	return r.dbClient.GetSubscriptionCounts(courseIDs)
 }
 
 func (r *CourseRepository) GetUserById(userID int64) (*domain.User, error) {
@@ -45,20 +46,28 @@ func (r *CourseRepository) InsertSubscription(userID, courseID int64) error {
 	return r.dbClient.InsertSubscription(userID, courseID)
 }
 
-func (r *CourseRepository) CreateCourse(course domain.Course) error {
-	return r.dbClient.CreateCourse(course)
+func (r *CourseRepository) CreateCourse(course domain.Course, ownerID int64, revision domain.CourseRevision) (domain.Course, error) {
+	return r.dbClient.CreateCourse(course, ownerID, revision)
 }
 
-func (r *CourseRepository) UpdateCourse(courseID int64, course domain.Course) error {
-	return r.dbClient.UpdateCourse(courseID, course)
+func (r *CourseRepository) UpdateCourse(courseID int64, course domain.Course, baseline, revision domain.CourseRevision) error {
+	return r.dbClient.UpdateCourse(courseID, course, baseline, revision)
 }
 
-func (r *CourseRepository) DeleteCourseById(courseID int64) error {
-	return r.dbClient.DeleteCourseById(courseID)
+func (r *CourseRepository) TrashCourse(courseID int64) error {
+	return r.dbClient.TrashCourse(courseID)
 }
 
-func (r *CourseRepository) DeleteSubscriptionById(courseID int64) error {
-	return r.dbClient.DeleteSubscriptionById(courseID)
+func (r *CourseRepository) GetTrashedCourses() ([]domain.Course, error) {
+	return r.dbClient.GetTrashedCourses()
+}
+
+func (r *CourseRepository) RestoreCourse(courseID int64) error {
+	return r.dbClient.RestoreCourse(courseID)
+}
+
+func (r *CourseRepository) PurgeCourse(courseID int64) ([]domain.File, error) {
+	return r.dbClient.PurgeCourse(courseID)
 }
 
 func (r *CourseRepository) GetCommentsByCourseId(courseID int64) ([]int64, error) {
@@ -68,3 +77,87 @@ func (r *CourseRepository) GetCommentsByCourseId(courseID int64) ([]int64, error
 func (r *CourseRepository) GetCommentById(commentID int64) (domain.Comment, error) {
 	return r.dbClient.GetCommentById(commentID)
 }
+
+func (r *CourseRepository) GetCourseRevisions(courseID int64) ([]domain.CourseRevision, error) {
+	return r.dbClient.GetCourseRevisions(courseID)
+}
+
+func (r *CourseRepository) GetCourseRevision(courseID int64, number int) (*domain.CourseRevision, error) {
+	return r.dbClient.GetCourseRevision(courseID, number)
+}
+
+func (r *CourseRepository) UpdateCourseStatus(courseID int64, status string, publishAt *time.Time) error {
+	return r.dbClient.UpdateCourseStatus(courseID, status, publishAt)
+}
+
+func (r *CourseRepository) GetStaffCourseIDs(userID int64) ([]int64, error) {
+	return r.dbClient.GetStaffCourseIDs(userID)
+}
+
+func (r *CourseRepository) GetCourseStaffMember(courseID, userID int64) (*domain.CourseStaff, error) {
+	return r.dbClient.GetCourseStaffMember(courseID, userID)
+}
+
+func (r *CourseRepository) GetCourseStaff(courseID int64) ([]domain.CourseStaff, error) {
+	return r.dbClient.GetCourseStaff(courseID)
+}
+
+func (r *CourseRepository) SaveCourseStaff(staff domain.CourseStaff) error {
+	return r.dbClient.SaveCourseStaff(staff)
+}
+
+func (r *CourseRepository) RemoveCourseStaff(courseID, userID int64) error {
+	return r.dbClient.RemoveCourseStaff(courseID, userID)
+}
+
+func (r *CourseRepository) IsSubscribed(userID, courseID int64) (bool, error) {
+	return r.dbClient.IsSubscribed(userID, courseID)
+}
+
+func (r *CourseRepository) GetFileById(fileID int64) (*domain.File, error) {
+	return r.dbClient.GetFileById(fileID)
+}
+
+func (r *CourseRepository) GetModules(courseID int64) ([]domain.CourseModule, error) {
+	return r.dbClient.GetModules(courseID)
+}
+
+func (r *CourseRepository) GetModuleById(moduleID int64) (*domain.CourseModule, error) {
+	return r.dbClient.GetModuleById(moduleID)
+}
+
+func (r *CourseRepository) CreateModule(module domain.CourseModule) (domain.CourseModule, error) {
+	return r.dbClient.CreateModule(module)
+}
+
+func (r *CourseRepository) UpdateModule(module domain.CourseModule) error {
+	return r.dbClient.UpdateModule(module)
+}
+
+func (r *CourseRepository) DeleteModule(moduleID int64) error {
+	return r.dbClient.DeleteModule(moduleID)
+}
+
+func (r *CourseRepository) GetLessons(courseID int64) ([]domain.Lesson, error) {
+	return r.dbClient.GetLessons(courseID)
+}
+
+func (r *CourseRepository) GetLessonById(lessonID int64) (*domain.Lesson, error) {
+	return r.dbClient.GetLessonById(lessonID)
+}
+
+func (r *CourseRepository) CreateLesson(lesson domain.Lesson) (domain.Lesson, error) {
+	return r.dbClient.CreateLesson(lesson)
+}
+
+func (r *CourseRepository) UpdateLesson(lesson domain.Lesson) error {
+	return r.dbClient.UpdateLesson(lesson)
+}
+
+func (r *CourseRepository) DeleteLesson(lessonID int64) error {
+	return r.dbClient.DeleteLesson(lessonID)
+}
+
+func (r *CourseRepository) ReorderLessons(courseID int64, lessonIDs []int64) error {
+	return r.dbClient.ReorderLessons(courseID, lessonIDs)
+}
@@ -0,0 +1,22 @@
+package dao
+
+import (
+	"backend/clients"
+	"backend/domain"
+	"backend/interfaces"
+)
+
+// OutboxRepository implementa OutboxRepositoryInterface
+type OutboxRepository struct {
+	dbClient interfaces.DatabaseClientInterface
+}
+
+func NewOutboxRepository() interfaces.OutboxRepositoryInterface {
+	return &OutboxRepository{
+		dbClient: clients.NewDatabaseClient(),
+	}
+}
+
+func (r *OutboxRepository) SaveOutboxEmail(email domain.OutboxEmail) error {
+	return r.dbClient.SaveOutboxEmail(email)
+}
@@ -4,6 +4,7 @@ import (
 	"backend/clients"
 	"backend/domain"
 	"backend/interfaces"
+	"time"
 )
 
 // UserRepository implementa UserRepositoryInterface
@@ -29,6 +30,46 @@ func (r *UserRepository) GetUserById(id int64) (*domain.User, error) {
 	return r.dbClient.GetUserById(id)
 }
 
+func (r *UserRepository) UpdatePasswordHash(userID int64, passwordHash string) error {
+	return r.dbClient.UpdatePasswordHash(userID, passwordHash)
+}
+
+func (r *UserRepository) SetEmailVerified(userID int64, verifiedAt time.Time) error {
+	return r.dbClient.SetEmailVerified(userID, verifiedAt)
+}
+
+func (r *UserRepository) IsNicknameTaken(nickname string, excludeUserID int64) (bool, error) {
+	return r.dbClient.IsNicknameTaken(nickname, excludeUserID)
+}
+
+func (r *UserRepository) IsEmailTaken(email string, excludeUserID int64) (bool, error) {
+	return r.dbClient.IsEmailTaken(email, excludeUserID)
+}
+
+func (r *UserRepository) UpdateNickname(userID int64, nickname string) error {
+	return r.dbClient.UpdateNickname(userID, nickname)
+}
+
+func (r *UserRepository) SetPendingEmail(userID int64, email string) error {
+	return r.dbClient.SetPendingEmail(userID, email)
+}
+
+func (r *UserRepository) ConfirmEmailChange(userID int64, email string, verifiedAt time.Time) error {
+	return r.dbClient.ConfirmEmailChange(userID, email, verifiedAt)
+}
+
+func (r *UserRepository) GetRoles() ([]domain.Role, error) {
+	return r.dbClient.GetRoles()
+}
+
+func (r *UserRepository) SetUserRoles(userID int64, roleNames []string) error {
+	return r.dbClient.SetUserRoles(userID, roleNames)
+}
+
+func (r *UserRepository) GetCourseStaffMember(courseID, userID int64) (*domain.CourseStaff, error) {
+	return r.dbClient.GetCourseStaffMember(courseID, userID)
+}
+
 func (r *UserRepository) GetCourseIdsByUserId(userID int64) ([]int64, error) {
 	return r.dbClient.GetCourseIdsByUserId(userID)
 }
@@ -37,6 +78,38 @@ func (r *UserRepository) GetCourseById(courseID int64) (*domain.Course, error) {
 	return r.dbClient.GetCourseById(courseID)
 }
 
+func (r *UserRepository) DeleteUser(userID int64) error {
+	return r.dbClient.DeleteUser(userID)
+}
+
+func (r *UserRepository) GetSoleOwnedCourseIDs(userID int64) ([]int64, error) {
+	return r.dbClient.GetSoleOwnedCourseIDs(userID)
+}
+
+func (r *UserRepository) ListUsers(query domain.UserQuery) ([]domain.User, int64, error) {
+	return r.dbClient.ListUsers(query)
+}
+
+func (r *UserRepository) SetUserDisabled(userID int64, disabledAt *time.Time) error {
+	return r.dbClient.SetUserDisabled(userID, disabledAt)
+}
+
+func (r *UserRepository) SetPasswordResetRequired(userID int64, required bool) error {
+	return r.dbClient.SetPasswordResetRequired(userID, required)
+}
+
+func (r *UserRepository) GetCommentsByUserId(userID int64) ([]domain.Comment, error) {
+	return r.dbClient.GetCommentsByUserId(userID)
+}
+
+func (r *UserRepository) GetFilesByUserId(userID int64) ([]domain.File, error) {
+	return r.dbClient.GetFilesByUserId(userID)
+}
+
+func (r *UserRepository) InsertSubscription(userID, courseID int64) error {
+	return r.dbClient.InsertSubscription(userID, courseID)
+}
+
 func (r *UserRepository) InsertComment(userID, courseID int64, comment string) error {
 	return r.dbClient.InsertComment(userID, courseID, comment)
 }
@@ -44,3 +117,75 @@ func (r *UserRepository) InsertComment(userID, courseID int64, comment string) e
 func (r *UserRepository) SaveFile(file domain.File) error {
 	return r.dbClient.SaveFile(file)
 }
+
+func (r *UserRepository) CreateSession(session domain.Session) (domain.Session, error) {
+	return r.dbClient.CreateSession(session)
+}
+
+func (r *UserRepository) GetSessionById(id int64) (*domain.Session, error) {
+	return r.dbClient.GetSessionById(id)
+}
+
+func (r *UserRepository) GetSessionByRefreshHash(refreshTokenHash string) (*domain.Session, error) {
+	return r.dbClient.GetSessionByRefreshHash(refreshTokenHash)
+}
+
+func (r *UserRepository) GetActiveSessionsByUserId(userID int64) ([]domain.Session, error) {
+	return r.dbClient.GetActiveSessionsByUserId(userID)
+}
+
+func (r *UserRepository) RotateSessionRefresh(id int64, currentHash, newHash string, lastUsedAt time.Time) error {
+	return r.dbClient.RotateSessionRefresh(id, currentHash, newHash, lastUsedAt)
+}
+
+func (r *UserRepository) RevokeSession(id int64, revokedAt time.Time) error {
+	return r.dbClient.RevokeSession(id, revokedAt)
+}
+
+func (r *UserRepository) RevokeUserSessions(userID int64, revokedAt time.Time) error {
+	return r.dbClient.RevokeUserSessions(userID, revokedAt)
+}
+
+func (r *UserRepository) CreateUserToken(token domain.UserToken) error {
+	return r.dbClient.CreateUserToken(token)
+}
+
+func (r *UserRepository) GetUserTokenByHash(tokenHash string) (*domain.UserToken, error) {
+	return r.dbClient.GetUserTokenByHash(tokenHash)
+}
+
+func (r *UserRepository) ConsumeUserToken(id int64, usedAt time.Time) error {
+	return r.dbClient.ConsumeUserToken(id, usedAt)
+}
+
+func (r *UserRepository) InvalidateUserTokens(userID int64, purpose string, usedAt time.Time) error {
+	return r.dbClient.InvalidateUserTokens(userID, purpose, usedAt)
+}
+
+func (r *UserRepository) GetTwoFactor(userID int64) (*domain.TwoFactor, error) {
+	return r.dbClient.GetTwoFactor(userID)
+}
+
+func (r *UserRepository) SaveTwoFactor(twoFactor domain.TwoFactor) error {
+	return r.dbClient.SaveTwoFactor(twoFactor)
+}
+
+func (r *UserRepository) EnableTwoFactor(userID int64, counter int64, enabledAt time.Time) error {
+	return r.dbClient.EnableTwoFactor(userID, counter, enabledAt)
+}
+
+func (r *UserRepository) UpdateTwoFactorCounter(userID int64, counter int64) error {
+	return r.dbClient.UpdateTwoFactorCounter(userID, counter)
+}
+
+func (r *UserRepository) DeleteTwoFactor(userID int64) error {
+	return r.dbClient.DeleteTwoFactor(userID)
+}
+
+func (r *UserRepository) ReplaceRecoveryCodes(userID int64, codeHashes []string) error {
+	return r.dbClient.ReplaceRecoveryCodes(userID, codeHashes)
+}
+
+func (r *UserRepository) UseRecoveryCode(userID int64, codeHash string, usedAt time.Time) error {
+	return r.dbClient.UseRecoveryCode(userID, codeHash, usedAt)
+}
@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Acciones registradas en el log de auditoría
+const (
+	AuditLoginSucceeded      = "auth.login"
+	AuditLoginFailed         = "auth.login_failed"
+	AuditPasswordReset       = "auth.password_reset"
+	AuditTwoFactorEnabled    = "auth.2fa_enabled"
+	AuditTwoFactorDisabled   = "auth.2fa_disabled"
+	AuditUserUnlocked        = "user.unlock"
+	AuditUserEmailVerified   = "user.email_verified"
+	AuditUserRolesChanged    = "user.roles_changed"
+	AuditUserUpdated         = "user.update"
+	AuditUserEmailChanged    = "user.email_changed"
+	AuditUserExported        = "user.export"
+	AuditUserDeleted         = "user.delete"
+	AuditUserDisabled        = "user.disable"
+	AuditUserEnabled         = "user.enable"
+	AuditUserResetForced     = "user.password_reset_forced"
+	AuditUserImported        = "user.import"
+	AuditCourseCreated       = "course.create"
+	AuditCourseUpdated       = "course.update"
+	AuditCourseDeleted       = "course.delete"
+	AuditCourseRestored      = "course.restore"
+	AuditCoursePurged        = "course.purge"
+	AuditCourseStatusChanged = "course.status_changed"
+	AuditRevisionRestored    = "course.revision_restored"
+	AuditCourseStaffAdded    = "course.staff_added"
+	AuditCourseStaffRemoved  = "course.staff_removed"
+	AuditModuleCreated       = "course.module_created"
+	AuditModuleUpdated       = "course.module_updated"
+	AuditModuleDeleted       = "course.module_deleted"
+	AuditLessonCreated       = "course.lesson_created"
+	AuditLessonUpdated       = "course.lesson_updated"
+	AuditLessonDeleted       = "course.lesson_deleted"
+	AuditLessonsReordered    = "course.lessons_reordered"
+	AuditSubscriptionCreated = "subscription.create"
+	AuditFileUploaded        = "file.upload"
+)
+
+// Tipos de entidades sobre las que se registran acciones
+const (
+	AuditTargetUser   = "user"
+	AuditTargetCourse = "course"
+)
+
+// Actor identifica quién ejecuta una acción. Permissions son los permisos de sus roles y
+// OnBehalfOf es el usuario en cuyo nombre actúa un admin, si corresponde.
+type Actor struct {
+	UserID      int64
+	Role        string
+	Permissions []string
+	IP          string
+	OnBehalfOf  int64
+}
+
+// HasPermission indica si alguno de los roles del actor le da el permiso
+func (a Actor) HasPermission(permission string) bool {
+	for _, granted := range a.Permissions {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// AuditEvent es una entrada del log de auditoría. La tabla es de solo inserción: no hay
+// operaciones para modificar ni borrar eventos, salvo quitar los datos personales de una
+// cuenta que se elimina. Before y After son snapshots en JSON.
+type AuditEvent struct {
+	Id         int64           `json:"id"`
+	ActorID    int64           `json:"actor_id" gorm:"index"`
+	ActorRole  string          `json:"actor_role" gorm:"type:varchar(16)"`
+	OnBehalfOf int64           `json:"on_behalf_of,omitempty"`
+	Action     string          `json:"action" gorm:"type:varchar(64);index"`
+	TargetType string          `json:"target_type" gorm:"type:varchar(32);index:idx_audit_target"`
+	TargetID   int64           `json:"target_id" gorm:"index:idx_audit_target"`
+	IP         string          `json:"ip" gorm:"type:varchar(45)"`
+	Details    string          `json:"details,omitempty"`
+	Before     json.RawMessage `json:"before,omitempty" gorm:"type:text"`
+	After      json.RawMessage `json:"after,omitempty" gorm:"type:text"`
+	CreatedAt  time.Time       `json:"created_at" gorm:"index"`
+}
+
+// AuditQuery son los filtros y la paginación para consultar el log. Los campos vacíos no filtran.
+type AuditQuery struct {
+	ActorID    int64
+	Action     string
+	TargetType string
+	TargetID   int64
+	From       *time.Time
+	To         *time.Time
+	Page       int
+	PageSize   int
+}
+
+type AuditPage struct {
+	Result   []AuditEvent `json:"results"`
+	Total    int64        `json:"total"`
+	Page     int          `json:"page"`
+	PageSize int          `json:"page_size"`
+}
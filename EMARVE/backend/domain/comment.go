@@ -1,9 +1,14 @@
 package domain
 
+import "gorm.io/gorm"
+
+// CommentRequest: el autor es el usuario del token. UserID se mantiene por compatibilidad
+// y debe coincidir con él; OnBehalfOf solo lo pueden usar los admins.
 type CommentRequest struct {
-	UserID   int64  `json:"userID"`
-	CourseID int64  `json:"courseID"`
-	Comment  string `json:"comment"`
+	UserID     int64  `json:"userID"`
+	CourseID   int64  `json:"courseID"`
+	Comment    string `json:"comment"`
+	OnBehalfOf int64  `json:"on_behalf_of"`
 }
 
 type CommentResponse struct {
@@ -20,4 +25,6 @@ type Comment struct {
 	UserID   int64  `json:"user_id"`
 	CourseID int64  `json:"course_id"`
 	Comment  string `json:"comment"`
+	// DeletedAt se completa cuando el curso va a la papelera
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 }
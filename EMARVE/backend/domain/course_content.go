@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Tipos de contenido de una lección
+const (
+	LessonText = "text"
+	LessonFile = "file"
+	LessonLink = "link"
+)
+
+// MaxContentTitleLength limita el título de módulos y lecciones
+const MaxContentTitleLength = 200
+
+var (
+	// ErrNotSubscribed indica que el contenido del curso solo lo ven sus suscriptos, su staff y los admins
+	ErrNotSubscribed   = errors.New("course content is only available to subscribers")
+	ErrCourseNotFound  = errors.New("course not found")
+	ErrContentNotFound = errors.New("module or lesson not found")
+)
+
+// CourseModule agrupa lecciones de un curso. Position ordena los módulos dentro del curso.
+type CourseModule struct {
+	Id        int64     `json:"id"`
+	CourseID  int64     `json:"course_id" gorm:"index"`
+	Title     string    `json:"title" gorm:"type:varchar(200)"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Lesson es una unidad de contenido de un módulo. Position ordena las lecciones en todo el curso.
+// Según Type se usa Content (texto), URL (link) o FileID (un archivo subido al curso).
+type Lesson struct {
+	Id        int64     `json:"id"`
+	CourseID  int64     `json:"course_id" gorm:"index"`
+	ModuleID  int64     `json:"module_id" gorm:"index"`
+	Title     string    `json:"title" gorm:"type:varchar(200)"`
+	Type      string    `json:"type" gorm:"type:varchar(16)"`
+	Content   string    `json:"content,omitempty" gorm:"type:text"`
+	URL       string    `json:"url,omitempty" gorm:"type:varchar(2048)"`
+	FileID    *int64    `json:"file_id,omitempty"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ModuleRequest struct {
+	Title string `json:"title"`
+}
+
+type LessonRequest struct {
+	ModuleID int64  `json:"module_id"`
+	Title    string `json:"title"`
+	Type     string `json:"type"`
+	Content  string `json:"content"`
+	URL      string `json:"url"`
+	FileID   *int64 `json:"file_id"`
+}
+
+// LessonOrderRequest tiene todas las lecciones del curso en el orden nuevo
+type LessonOrderRequest struct {
+	LessonIDs []int64 `json:"lesson_ids"`
+}
+
+// ModuleOutline es un módulo con sus lecciones en orden
+type ModuleOutline struct {
+	CourseModule
+	Lessons []Lesson `json:"lessons"`
+}
+
+type ModuleList struct {
+	Result []ModuleOutline `json:"results"`
+}
+
+type LessonList struct {
+	Result []Lesson `json:"results"`
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// CourseRevision guarda cómo quedaron los campos editables del curso después de un cambio.
+// Number es correlativo dentro del curso y RestoredFrom indica la revisión que se restauró,
+// si el cambio fue una restauración.
+type CourseRevision struct {
+	Id           int64     `json:"id"`
+	CourseID     int64     `json:"course_id" gorm:"uniqueIndex:idx_course_revision"`
+	Number       int       `json:"number" gorm:"uniqueIndex:idx_course_revision"`
+	Title        string    `json:"title"`
+	Description  string    `json:"description"`
+	Category     string    `json:"category"`
+	Instructor   string    `json:"instructor"`
+	Duration     int64     `json:"duration"`
+	Requirement  string    `json:"requirement"`
+	AuthorID     int64     `json:"author_id"`
+	RestoredFrom int       `json:"restored_from,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type RevisionList struct {
+	Result []CourseRevision `json:"results"`
+}
+
+// FieldChange es un campo que cambió entre dos revisiones
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+type RevisionDiff struct {
+	CourseID int64         `json:"course_id"`
+	From     int           `json:"from"`
+	To       int           `json:"to"`
+	Changes  []FieldChange `json:"changes"`
+}
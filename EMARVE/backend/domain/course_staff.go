@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Roles del staff de un curso. El owner es quien lo creó y el único, junto con los admins,
+// que puede borrarlo y administrar su staff.
+const (
+	StaffOwner             = "owner"
+	StaffCoInstructor      = "co_instructor"
+	StaffTeachingAssistant = "teaching_assistant"
+)
+
+// ErrCourseForbidden indica que el usuario no es staff del curso con el rol necesario ni admin
+var ErrCourseForbidden = errors.New("not allowed to manage this course")
+
+// CourseStaff vincula un usuario con un curso que administra
+type CourseStaff struct {
+	CourseID  int64     `json:"course_id" gorm:"primaryKey"`
+	UserID    int64     `json:"user_id" gorm:"primaryKey;index"`
+	Role      string    `json:"role" gorm:"type:varchar(32)"`
+	AddedBy   int64     `json:"added_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type StaffRequest struct {
+	UserID int64  `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+type StaffList struct {
+	Result []CourseStaff `json:"results"`
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Estados del ciclo de publicación de un curso. Los cursos nuevos empiezan en borrador; los
+// cursos anteriores a este ciclo quedan publicados.
+const (
+	CourseDraft     = "draft"
+	CourseInReview  = "review"
+	CoursePublished = "published"
+	CourseArchived  = "archived"
+)
+
+// CourseStatusTransitions son los estados a los que se puede pasar desde cada estado.
+// Un curso en revisión puede volver a borrador si se rechaza o se retira.
+var CourseStatusTransitions = map[string][]string{
+	CourseDraft:     {CourseInReview},
+	CourseInReview:  {CourseDraft, CoursePublished},
+	CoursePublished: {CourseArchived},
+	CourseArchived:  {},
+}
+
+var (
+	ErrInvalidStatusTransition = errors.New("invalid course status transition")
+	// ErrCourseArchived indica que el curso archivado no acepta suscripciones ni cambios de contenido
+	ErrCourseArchived     = errors.New("course is archived")
+	ErrCourseNotPublished = errors.New("course is not published")
+)
+
+// CourseStatusRequest pide pasar el curso a Status. PublishAt solo se usa al publicar y
+// programa la publicación para esa fecha.
+type CourseStatusRequest struct {
+	Status    string     `json:"status"`
+	PublishAt *time.Time `json:"publish_at"`
+}
+
+// IsVisible indica si el curso ya está publicado para los alumnos
+func (c Course) IsVisible(now time.Time) bool {
+	if c.Status != CoursePublished && c.Status != "" {
+		return false
+	}
+	return c.PublishAt == nil || !c.PublishAt.After(now)
+}
+
+// CanMoveTo indica si el ciclo de publicación permite pasar del estado actual a status
+func (c Course) CanMoveTo(status string) bool {
+	current := c.Status
+	if current == "" {
+		current = CoursePublished
+	}
+	for _, next := range CourseStatusTransitions[current] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,13 @@
 package domain
 
-import "time"
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Course struct {
 	Id           int       `json:"id"`
@@ -12,30 +19,139 @@ type Course struct {
 	Requirement  string    `json:"requirement"`
 	CreationDate time.Time `json:"creation_date"`
 	LastUpdate   time.Time `json:"last_update"`
+	// Status es el estado de publicación y PublishAt la fecha programada de publicación
+	Status    string     `json:"status" gorm:"type:varchar(16);default:published;index"`
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+	// DeletedAt marca los cursos en la papelera, que quedan fuera de todas las consultas
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 }
 
 type SearchRequest struct {
 	Query string `json:"query"`
 }
 
+// ErrInvalidCourseQuery indica filtros, orden o paginación inválidos en el listado de cursos
+var ErrInvalidCourseQuery = errors.New("invalid course query")
+
+// Campos por los que se puede ordenar el listado de cursos. Con el prefijo "-" el orden es
+// descendente. popularity ordena por cantidad de suscripciones.
+var CourseSortFields = map[string]string{
+	"title":         "title",
+	"creation_date": "creation_date",
+	"duration":      "duration",
+	"popularity":    "(SELECT COUNT(*) FROM subscriptions WHERE subscriptions.course_id = courses.id)",
+}
+
+// CourseQuery son los filtros del listado y la búsqueda de cursos. Query busca en el título y
+// la descripción y la duración se filtra por rango (0 = sin límite). Categories, Instructors y
+// DurationBuckets son selecciones de facetas: un curso debe coincidir con alguno de los valores
+// de cada faceta seleccionada. Salvo con AllStatuses solo se incluyen los cursos publicados
+// y, si StaffUserID no es 0, los cursos de los que ese usuario es staff.
+type CourseQuery struct {
+	Query           string
+	Categories      []string
+	Instructors     []string
+	DurationBuckets []string
+	MinDuration     int64
+	MaxDuration     int64
+	Sort            string
+	Page            int
+	PageSize        int
+	AllStatuses     bool
+	StaffUserID     int64
+}
+
+// SearchResponse es una página de cursos. Next y Prev son los links a las páginas vecinas, si existen.
 type SearchResponse struct {
-	Result []Course `json:"results"`
+	Result   []Course `json:"results"`
+	Total    int64    `json:"total"`
+	Page     int      `json:"page"`
+	PageSize int      `json:"page_size"`
+	Next     string   `json:"next,omitempty"`
+	Prev     string   `json:"prev,omitempty"`
+
+	// Highlights tiene los fragmentos resaltados de cada resultado cuando se busca texto
+	Highlights []CourseHighlight `json:"highlights,omitempty"`
+	// DidYouMean propone una búsqueda corregida cuando la búsqueda no encontró nada
+	DidYouMean string `json:"did_you_mean,omitempty"`
+	// Facets tiene los conteos para los filtros de la búsqueda
+	Facets *SearchFacets `json:"facets,omitempty"`
 }
 
 type FileListResponse struct {
 	Result []File `json:"results"`
 }
 
+// SubscribeRequest: el usuario suscripto es el del token. UserId se mantiene por compatibilidad
+// y debe coincidir con él; OnBehalfOf solo lo pueden usar los admins.
 type SubscribeRequest struct {
-	UserId   int64 `json:"user_id"`
-	CourseId int64 `json:"course_id"`
+	UserId     int64 `json:"user_id"`
+	CourseId   int64 `json:"course_id"`
+	OnBehalfOf int64 `json:"on_behalf_of"`
 }
 
+// CourseRequest son los campos editables de un curso. Al crear y con PUT deben venir todos;
+// con PATCH se aplica como JSON Merge Patch (RFC 7396) y los campos que no vienen quedan
+// como están.
 type CourseRequest struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Category    string `json:"category"`
-	Instructor  string `json:"instructor"`
-	Duration    int64  `json:"duration"`
-	Requirement string `json:"requirement"`
+	Title       Optional[string] `json:"title"`
+	Description Optional[string] `json:"description"`
+	Category    Optional[string] `json:"category"`
+	Instructor  Optional[string] `json:"instructor"`
+	Duration    Optional[int64]  `json:"duration"`
+	Requirement Optional[string] `json:"requirement"`
+}
+
+// IsEmpty indica que el patch no trae ningún campo
+func (r CourseRequest) IsEmpty() bool {
+	return !r.Title.Set && !r.Description.Set && !r.Category.Set &&
+		!r.Instructor.Set && !r.Duration.Set && !r.Requirement.Set
+}
+
+// Apply devuelve el curso con los campos que trae el request
+func (r CourseRequest) Apply(course Course) Course {
+	r.Title.apply(&course.Title)
+	r.Description.apply(&course.Description)
+	r.Category.apply(&course.Category)
+	r.Instructor.apply(&course.Instructor)
+	r.Duration.apply(&course.Duration)
+	r.Requirement.apply(&course.Requirement)
+	return course
+}
+
+// Optional es un campo que puede faltar en el JSON. Set indica que vino en el cuerpo y Null
+// que vino en null, que en un merge patch significa borrar el campo.
+type Optional[T any] struct {
+	Value T
+	Set   bool
+	Null  bool
+}
+
+// Some arma un Optional con valor
+func Some[T any](value T) Optional[T] {
+	return Optional[T]{Value: value, Set: true}
+}
+
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		var zero T
+		o.Value, o.Null = zero, true
+		return nil
+	}
+	o.Null = false
+	return json.Unmarshal(data, &o.Value)
+}
+
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if o.Null {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.Value)
+}
+
+func (o Optional[T]) apply(field *T) {
+	if o.Set && !o.Null {
+		*field = o.Value
+	}
 }
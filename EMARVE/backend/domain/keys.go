@@ -0,0 +1,18 @@
+package domain
+
+// JWK es la representación pública de una clave de firma (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet es el documento publicado en /.well-known/jwks.json
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// LoginAttempt son los intentos fallidos de login acumulados para una cuenta o una IP
+type LoginAttempt struct {
+	Failures     int
+	LastFailure  time.Time
+	BlockedUntil time.Time
+	Locked       bool
+}
+
+// LoginThrottledError indica que el login está bloqueado temporalmente. Locked distingue
+// el bloqueo de la cuenta (423) de la espera entre intentos (429).
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+	Locked     bool
+}
+
+func (e *LoginThrottledError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("account is temporarily locked, retry in %d seconds", e.RetryAfterSeconds())
+	}
+	return fmt.Sprintf("too many failed login attempts, retry in %d seconds", e.RetryAfterSeconds())
+}
+
+// RetryAfterSeconds redondea hacia arriba, para el header Retry-After
+func (e *LoginThrottledError) RetryAfterSeconds() int {
+	return int(math.Ceil(e.RetryAfter.Seconds()))
+}
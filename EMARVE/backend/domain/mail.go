@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// Email es un mensaje saliente
+type Email struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// OutboxEmail es un email guardado en la tabla outbox para que lo despache un proceso externo
+type OutboxEmail struct {
+	Id        int64      `json:"id"`
+	To        string     `json:"to" gorm:"column:recipient"`
+	Subject   string     `json:"subject"`
+	Body      string     `json:"body" gorm:"type:text"`
+	CreatedAt time.Time  `json:"created_at"`
+	SentAt    *time.Time `json:"sent_at"`
+}
@@ -0,0 +1,92 @@
+package domain
+
+import "time"
+
+// Roles predefinidos. Se crean en la migración junto con sus permisos por defecto.
+const (
+	RoleStudent           = "student"
+	RoleTeachingAssistant = "teaching_assistant"
+	RoleContentReviewer   = "content_reviewer"
+	RoleInstructor        = "instructor"
+	RoleAdmin             = "admin"
+)
+
+// Permisos con nombre que se pueden asignar a los roles
+const (
+	PermCourseCreate    = "course:create"
+	PermCourseUpdate    = "course:update"
+	PermCourseDelete    = "course:delete"
+	PermCourseSubscribe = "course:subscribe"
+	PermCommentCreate   = "comment:create"
+	PermCommentModerate = "comment:moderate"
+	PermFileUpload      = "file:upload"
+	PermUserManage      = "user:manage"
+	PermRoleAssign      = "role:assign"
+	PermAuditRead       = "audit:read"
+)
+
+// DefaultRolePermissions son los permisos con los que se crea cada rol predefinido. Una vez
+// creado el rol, sus permisos se administran en la tabla role_permissions.
+var DefaultRolePermissions = map[string][]string{
+	RoleStudent: {
+		PermCourseSubscribe, PermCommentCreate, PermFileUpload,
+	},
+	RoleTeachingAssistant: {
+		PermCourseSubscribe, PermCommentCreate, PermCommentModerate, PermFileUpload,
+	},
+	RoleContentReviewer: {
+		PermCourseUpdate, PermCommentCreate, PermCommentModerate,
+	},
+	RoleInstructor: {
+		PermCourseCreate, PermCourseUpdate, PermCourseSubscribe, PermCommentCreate, PermCommentModerate, PermFileUpload,
+	},
+	RoleAdmin: {
+		PermCourseCreate, PermCourseUpdate, PermCourseDelete, PermCourseSubscribe, PermCommentCreate,
+		PermCommentModerate, PermFileUpload, PermUserManage, PermRoleAssign, PermAuditRead,
+	},
+}
+
+// rolePrecedence ordena los roles de mayor a menor para elegir el rol principal
+var rolePrecedence = []string{RoleAdmin, RoleInstructor, RoleContentReviewer, RoleTeachingAssistant, RoleStudent}
+
+// PrimaryRole devuelve el rol de mayor jerarquía entre los asignados. Es el que se informa a
+// los clientes que esperan un único rol (por ejemplo /users/authentication).
+func PrimaryRole(roles []string) string {
+	for _, candidate := range rolePrecedence {
+		for _, role := range roles {
+			if role == candidate {
+				return role
+			}
+		}
+	}
+	if len(roles) > 0 {
+		return roles[0]
+	}
+	return ""
+}
+
+type Role struct {
+	Id          int64    `json:"id"`
+	Name        string   `json:"name" gorm:"type:varchar(32);uniqueIndex"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions" gorm:"-"`
+}
+
+type RolePermission struct {
+	RoleID     int64  `gorm:"primaryKey"`
+	Permission string `gorm:"type:varchar(64);primaryKey"`
+}
+
+type UserRole struct {
+	UserID    int64 `gorm:"primaryKey"`
+	RoleID    int64 `gorm:"primaryKey;index"`
+	CreatedAt time.Time
+}
+
+type RoleList struct {
+	Result []Role `json:"results"`
+}
+
+type SetRolesRequest struct {
+	Roles []string `json:"roles"`
+}
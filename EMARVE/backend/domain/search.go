@@ -0,0 +1,85 @@
+package domain
+
+// Campos de los cursos que indexa la búsqueda
+const (
+	SearchFieldTitle       = "title"
+	SearchFieldDescription = "description"
+	SearchFieldRequirement = "requirement"
+	SearchFieldCategory    = "category"
+	SearchFieldInstructor  = "instructor"
+)
+
+// SortRelevance ordena los resultados de una búsqueda por puntaje. Es el orden por defecto con texto.
+const SortRelevance = "relevance"
+
+// SearchHit es un curso encontrado por el índice con su puntaje y los fragmentos resaltados por campo
+type SearchHit struct {
+	Course     Course
+	Score      float64
+	Highlights map[string]string
+}
+
+// CourseHighlight acompaña a un resultado de búsqueda. Los fragmentos vienen escapados como HTML
+// y marcan los términos encontrados con <em>.
+type CourseHighlight struct {
+	CourseID int               `json:"course_id"`
+	Score    float64           `json:"score"`
+	Fields   map[string]string `json:"fields"`
+}
+
+// Suggestion es una sugerencia del autocompletado. Para los títulos indica el curso y para las
+// categorías e instructores la cantidad de cursos que tienen ese valor.
+type Suggestion struct {
+	Text     string `json:"text"`
+	Field    string `json:"field"`
+	CourseID int    `json:"course_id,omitempty"`
+	Count    int    `json:"count,omitempty"`
+}
+
+type SuggestResponse struct {
+	Result []Suggestion `json:"results"`
+}
+
+// DurationBucket es un rango de duración de la faceta duration. Max 0 indica que no tiene límite.
+type DurationBucket struct {
+	Key string
+	Min int64
+	Max int64
+}
+
+// DurationBuckets son los rangos de la faceta duration, en el orden en que se muestran
+var DurationBuckets = []DurationBucket{
+	{Key: "0-9", Min: 0, Max: 9},
+	{Key: "10-29", Min: 10, Max: 29},
+	{Key: "30-59", Min: 30, Max: 59},
+	{Key: "60+", Min: 60},
+}
+
+// FindDurationBucket busca un rango por su clave
+func FindDurationBucket(key string) (DurationBucket, bool) {
+	for _, bucket := range DurationBuckets {
+		if bucket.Key == key {
+			return bucket, true
+		}
+	}
+	return DurationBucket{}, false
+}
+
+func (b DurationBucket) Contains(duration int64) bool {
+	return duration >= b.Min && (b.Max == 0 || duration <= b.Max)
+}
+
+// FacetValue es un valor de una faceta con la cantidad de cursos que lo tienen
+type FacetValue struct {
+	Value    string `json:"value"`
+	Count    int    `json:"count"`
+	Selected bool   `json:"selected"`
+}
+
+// SearchFacets son los conteos de cada faceta. Cada una respeta los demás filtros activos pero
+// no su propia selección, para que se pueda sumar otro valor de la misma faceta.
+type SearchFacets struct {
+	Category   []FacetValue `json:"category"`
+	Instructor []FacetValue `json:"instructor"`
+	Duration   []FacetValue `json:"duration"`
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrRefreshTokenReused indica que se presentó un refresh token que ya fue rotado
+var ErrRefreshTokenReused = errors.New("refresh token was already used")
+
+// Session es una sesión de login. Guarda el hash del refresh token vigente y permite
+// revocar en el servidor los access tokens emitidos para ella. El hash anterior se
+// conserva para detectar si alguien vuelve a usar un refresh token ya rotado.
+type Session struct {
+	Id                  int64      `json:"id"`
+	UserID              int64      `json:"user_id" gorm:"index"`
+	RefreshTokenHash    string     `json:"-" gorm:"type:varchar(64);uniqueIndex"`
+	PreviousRefreshHash string     `json:"-" gorm:"type:varchar(64);index"`
+	IP                  string     `json:"ip"`
+	UserAgent           string     `json:"user_agent"`
+	CreatedAt           time.Time  `json:"created_at"`
+	LastUsedAt          time.Time  `json:"last_used_at"`
+	ExpiresAt           time.Time  `json:"expires_at"`
+	RevokedAt           *time.Time `json:"revoked_at"`
+}
+
+// ClientInfo identifica al cliente que origina un request
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type SessionResponse struct {
+	Id         int64     `json:"id"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+type SessionList struct {
+	Result []SessionResponse `json:"results"`
+}
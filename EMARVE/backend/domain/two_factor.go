@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+// TwoFactor es la configuración TOTP de un usuario. Mientras EnabledAt es nil la
+// inscripción está pendiente de confirmar con un primer código.
+type TwoFactor struct {
+	UserID      int64      `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	Secret      string     `json:"-" gorm:"type:varchar(64)"`
+	LastCounter int64      `json:"-"`
+	CreatedAt   time.Time  `json:"created_at"`
+	EnabledAt   *time.Time `json:"enabled_at"`
+}
+
+// RecoveryCode es un código de recuperación de un solo uso, guardado como hash
+type RecoveryCode struct {
+	Id       int64      `json:"id"`
+	UserID   int64      `json:"user_id" gorm:"index"`
+	CodeHash string     `json:"-" gorm:"type:varchar(64);uniqueIndex"`
+	UsedAt   *time.Time `json:"used_at"`
+}
+
+// TOTPEnrollment es lo que necesita el usuario para cargar el secreto en su app
+type TOTPEnrollment struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauth_uri"`
+}
+
+type TOTPCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token"`
+	Code     string `json:"code"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
@@ -0,0 +1,29 @@
+package domain
+
+// Campos por los que se puede ordenar el listado de usuarios. Con el prefijo "-" el orden es descendente.
+var UserSortFields = map[string]string{
+	"id":       "id",
+	"nickname": "nickname",
+	"email":    "email",
+}
+
+// UserQuery son los filtros del listado de usuarios. Email y Nickname buscan coincidencias parciales.
+type UserQuery struct {
+	Email    string
+	Nickname string
+	Sort     string
+	Page     int
+	PageSize int
+}
+
+type UserPage struct {
+	Result   []UserResponse `json:"results"`
+	Total    int64          `json:"total"`
+	Page     int            `json:"page"`
+	PageSize int            `json:"page_size"`
+}
+
+// RoleChangeRequest indica el rol a agregar o quitar. Vacío equivale a admin.
+type RoleChangeRequest struct {
+	Role string `json:"role"`
+}
@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+// DeletedUserID es el autor que queda en los comentarios de las cuentas eliminadas
+const DeletedUserID int64 = 0
+
+// UserExport reúne los datos personales de un usuario para los pedidos de acceso
+type UserExport struct {
+	ExportedAt    time.Time         `json:"exported_at"`
+	Profile       UserExportProfile `json:"profile"`
+	Subscriptions []Course          `json:"subscriptions"`
+	Comments      []Comment         `json:"comments"`
+	Files         []File            `json:"files"`
+	Sessions      []Session         `json:"sessions"`
+}
+
+type UserExportProfile struct {
+	Id               int64      `json:"id"`
+	Nickname         string     `json:"nickname"`
+	Email            string     `json:"email"`
+	PendingEmail     *string    `json:"pending_email,omitempty"`
+	EmailVerifiedAt  *time.Time `json:"email_verified_at"`
+	Roles            []string   `json:"roles"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled"`
+}
+
+// DeleteUserRequest: quien borra su propia cuenta debe confirmar con su contraseña
+type DeleteUserRequest struct {
+	CurrentPassword string `json:"current_password"`
+}
@@ -0,0 +1,40 @@
+package domain
+
+import "errors"
+
+// ErrImportInvalid se devuelve al aplicar una importación con filas inválidas: no se crea ningún usuario
+var ErrImportInvalid = errors.New("the file has invalid rows, no user was imported")
+
+// Estados de cada fila de una importación de usuarios
+const (
+	ImportRowValid   = "valid"
+	ImportRowCreated = "created"
+	ImportRowError   = "error"
+)
+
+// UserImportOptions: por defecto la importación solo valida (DryRun). CourseIDs son los
+// cursos a los que se suscribe a cada usuario creado.
+type UserImportOptions struct {
+	DryRun    bool
+	CourseIDs []int64
+}
+
+// UserImportRow es el resultado de una fila del CSV. Line es la línea del archivo,
+// contando el encabezado como la línea 1.
+type UserImportRow struct {
+	Line     int      `json:"line"`
+	Nickname string   `json:"nickname"`
+	Email    string   `json:"email"`
+	Roles    []string `json:"roles"`
+	Status   string   `json:"status"`
+	Errors   []string `json:"errors,omitempty"`
+	UserID   int64    `json:"user_id,omitempty"`
+}
+
+type UserImportReport struct {
+	DryRun  bool            `json:"dry_run"`
+	Total   int             `json:"total"`
+	Valid   int             `json:"valid"`
+	Created int             `json:"created"`
+	Rows    []UserImportRow `json:"rows"`
+}
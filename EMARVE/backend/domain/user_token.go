@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+// Propósitos de los tokens de un solo uso que se envían por email
+const (
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailVerification = "email_verification"
+	TokenPurposeEmailChange       = "email_change"
+)
+
+// UserToken es un token de un solo uso asociado a un usuario. Solo se guarda el hash
+// del token; el valor en claro viaja únicamente en el email.
+type UserToken struct {
+	Id        int64      `json:"id"`
+	UserID    int64      `json:"user_id" gorm:"index"`
+	Purpose   string     `json:"purpose" gorm:"type:varchar(32);index"`
+	TokenHash string     `json:"-" gorm:"type:varchar(64);uniqueIndex"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
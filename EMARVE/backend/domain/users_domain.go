@@ -1,6 +1,29 @@
 package domain
 
-import "time"
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrEmailNotVerified se devuelve en el login de una cuenta sin verificar cuando la política lo exige
+var ErrEmailNotVerified = errors.New("email address is not verified")
+
+// Errores de la gestión de la cuenta
+var (
+	ErrAccountForbidden       = errors.New("not allowed to manage this account")
+	ErrNicknameTaken          = errors.New("nickname is already in use")
+	ErrEmailTaken             = errors.New("email is already in use")
+	ErrInvalidCurrentPassword = errors.New("current password is incorrect")
+	ErrLastCourseOwner        = errors.New("user is the only owner of a course")
+)
+
+// ErrAccountDisabled se devuelve al intentar usar una cuenta deshabilitada por un admin
+var ErrAccountDisabled = errors.New("account is disabled")
+
+// ErrPasswordResetRequired se devuelve en el login cuando un admin forzó el cambio de contraseña
+var ErrPasswordResetRequired = errors.New("password must be reset before logging in")
 
 type LoginRequest struct {
 	Email    string `json:"email"`
@@ -8,7 +31,18 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	// EmailVerified es false solo con la política "warn", que permite el login sin verificar
+	EmailVerified bool `json:"email_verified"`
+
+	// Con 2FA el login devuelve solo un mfa_token, que se canjea por los tokens en /users/login/2fa
+	MFARequired           bool     `json:"mfa_required,omitempty"`
+	MFAEnrollmentRequired bool     `json:"mfa_enrollment_required,omitempty"`
+	MFAToken              string   `json:"mfa_token,omitempty"`
+	RecoveryCodes         []string `json:"recovery_codes,omitempty"`
 }
 
 type RegistrationRequest struct {
@@ -18,29 +52,84 @@ type RegistrationRequest struct {
 	Type     bool   `json:"type"`
 }
 
+// UpdateUserRequest actualiza solo los campos que vienen con valor. Cambiar la contraseña
+// requiere current_password y un email nuevo queda pendiente hasta que se confirme.
 type UpdateUserRequest struct {
-	Nickname string `json:"nickname"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Nickname        string `json:"nickname"`
+	Email           string `json:"email"`
+	Password        string `json:"password"`
+	CurrentPassword string `json:"current_password"`
 }
 
 type ListResponse struct {
 	Result []Course `json:"results"`
 }
 
+// User es la cuenta de un usuario. Roles y Permissions no son columnas: se cargan desde
+// user_roles y role_permissions al leer el usuario.
 type User struct {
-	Id           int64  `json:"id"`
-	Nickname     string `json:"nickname"`
-	Email        string `json:"email"`
-	PasswordHash string `json:"password_hash"`
-	Type         bool   `json:"type"`
+	Id              int64      `json:"id"`
+	Nickname        string     `json:"nickname"`
+	Email           string     `json:"email"`
+	PasswordHash    string     `json:"-"`
+	Roles           []string   `json:"roles" gorm:"-"`
+	Permissions     []string   `json:"permissions" gorm:"-"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	// PendingEmail es el email nuevo que todavía no se confirmó con el link enviado
+	PendingEmail *string `json:"pending_email" gorm:"type:varchar(255)"`
+	// DisabledAt bloquea el login y la renovación de tokens mientras no sea nil
+	DisabledAt            *time.Time `json:"disabled_at"`
+	PasswordResetRequired bool       `json:"password_reset_required"`
 }
 
+// HasRole indica si el usuario tiene asignado el rol
+func (u *User) HasRole(role string) bool {
+	for _, assigned := range u.Roles {
+		if assigned == role {
+			return true
+		}
+	}
+	return false
+}
+
+// UserResponse mantiene el campo type (true para los admins) para los clientes anteriores a los roles
 type UserResponse struct {
-	Id       int64  `json:"id"`
-	Nickname string `json:"nickname"`
-	Email    string `json:"email"`
-	Type     bool   `json:"type"`
+	Id       int64    `json:"id"`
+	Nickname string   `json:"nickname"`
+	Email    string   `json:"email"`
+	Type     bool     `json:"type"`
+	Roles    []string `json:"roles"`
+
+	PendingEmail          *string    `json:"pending_email,omitempty"`
+	EmailVerifiedAt       *time.Time `json:"email_verified_at,omitempty"`
+	DisabledAt            *time.Time `json:"disabled_at,omitempty"`
+	PasswordResetRequired bool       `json:"password_reset_required,omitempty"`
+}
+
+// NewUserResponse arma la respuesta pública del usuario, sin el hash de la contraseña
+func NewUserResponse(user *User) UserResponse {
+	return UserResponse{
+		Id:                    user.Id,
+		Nickname:              user.Nickname,
+		Email:                 user.Email,
+		Type:                  user.HasRole(RoleAdmin),
+		Roles:                 user.Roles,
+		PendingEmail:          user.PendingEmail,
+		EmailVerifiedAt:       user.EmailVerifiedAt,
+		DisabledAt:            user.DisabledAt,
+		PasswordResetRequired: user.PasswordResetRequired,
+	}
+}
+
+// TokenClaims es la identidad del usuario extraída de un JWT válido. Role es el rol
+// principal (ver PrimaryRole) y Roles y Permissions los asignados al emitir el token.
+type TokenClaims struct {
+	UserID      int64    `json:"user_id"`
+	Role        string   `json:"role"`
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
+	SessionID   int64    `json:"session_id"`
+	TokenID     string   `json:"token_id"`
 }
 
 type File struct {
@@ -50,10 +139,14 @@ type File struct {
 	Name       string    `json:"name"`
 	Url        string    `json:"url"`
 	UploadDate time.Time `json:"upload_date"`
+	// DeletedAt se completa cuando el curso del archivo va a la papelera
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
 type Subscription struct {
 	Id       int64 `json:"id"`
 	UserID   int64 `json:"user_id"`
 	CourseID int64 `json:"course_id"`
+	// DeletedAt se completa cuando el curso va a la papelera
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 }
@@ -8,12 +8,14 @@ CREATE DATABASE IF NOT EXISTS emarve_db;
 USE emarve_db;
 
 -- Crear tabla de usuarios
+-- La columna type (admin o estudiante) se migra a la tabla user_roles y se elimina al iniciar el backend
 CREATE TABLE IF NOT EXISTS users (
     id BIGINT AUTO_INCREMENT PRIMARY KEY,
     nickname VARCHAR(255) NOT NULL UNIQUE,
     email VARCHAR(255) NOT NULL UNIQUE,
-    password VARCHAR(255) NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
     type BOOLEAN NOT NULL DEFAULT FALSE,
+    email_verified_at TIMESTAMP NULL,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
 );
@@ -43,13 +45,14 @@ CREATE TABLE IF NOT EXISTS subscriptions (
 );
 
 -- Crear tabla de comentarios
+-- user_id no tiene foreign key: al eliminar una cuenta sus comentarios y archivos quedan a nombre del usuario 0
 CREATE TABLE IF NOT EXISTS comments (
     id BIGINT AUTO_INCREMENT PRIMARY KEY,
     user_id BIGINT NOT NULL,
     course_id BIGINT NOT NULL,
     comment TEXT NOT NULL,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX (user_id),
     FOREIGN KEY (course_id) REFERENCES courses(id) ON DELETE CASCADE
 );
 
@@ -61,14 +64,16 @@ CREATE TABLE IF NOT EXISTS files (
     name VARCHAR(255) NOT NULL,
     url VARCHAR(500) NOT NULL,
     upload_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    FOREIGN KEY (course_id) REFERENCES courses(id) ON DELETE CASCADE,
-    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+    INDEX (user_id),
+    FOREIGN KEY (course_id) REFERENCES courses(id) ON DELETE CASCADE
 );
 
 -- Insertar datos de ejemplo
-INSERT IGNORE INTO users (nickname, email, password, type) VALUES 
-('admin', 'admin@emarve.com', '$2a$10$92IXUNpkjO0rOQ5byMi.Ye4oKoEa3Ro9llC/.og/at2.uheWG/igi', TRUE),
-('estudiante1', 'estudiante1@emarve.com', '$2a$10$92IXUNpkjO0rOQ5byMi.Ye4oKoEa3Ro9llC/.og/at2.uheWG/igi', FALSE);
+-- Hashes bcrypt (algoritmo identificado por el prefijo $2a$); los hashes MD5 heredados se migran en el login
+-- Los usuarios de ejemplo se crean con el email ya verificado
+INSERT IGNORE INTO users (nickname, email, password_hash, type, email_verified_at) VALUES 
+('admin', 'admin@emarve.com', '$2a$10$92IXUNpkjO0rOQ5byMi.Ye4oKoEa3Ro9llC/.og/at2.uheWG/igi', TRUE, CURRENT_TIMESTAMP),
+('estudiante1', 'estudiante1@emarve.com', '$2a$10$92IXUNpkjO0rOQ5byMi.Ye4oKoEa3Ro9llC/.og/at2.uheWG/igi', FALSE, CURRENT_TIMESTAMP);
 
 INSERT IGNORE INTO courses (title, description, category, instructor, duration, requirement) VALUES 
 ('Curso de Programación', 'Aprende los fundamentos de la programación', 'Programación', 'admin', 40, 'Conocimientos básicos de computación'),
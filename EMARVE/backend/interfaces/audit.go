@@ -0,0 +1,20 @@
+package interfaces
+
+import "backend/domain"
+
+// AuditRecorderInterface registra eventos en el log de auditoría
+type AuditRecorderInterface interface {
+	Record(event domain.AuditEvent)
+}
+
+// AuditServiceInterface define las operaciones del servicio de auditoría
+type AuditServiceInterface interface {
+	AuditRecorderInterface
+	List(query domain.AuditQuery) (domain.AuditPage, error)
+}
+
+// AuditRepositoryInterface define el acceso a la tabla de auditoría, que es de solo inserción
+type AuditRepositoryInterface interface {
+	CreateAuditEvent(event domain.AuditEvent) error
+	ListAuditEvents(query domain.AuditQuery) ([]domain.AuditEvent, int64, error)
+}
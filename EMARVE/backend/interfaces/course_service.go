@@ -2,35 +2,81 @@ package interfaces
 
 import (
 	"backend/domain"
+	"time"
 )
 
 // CourseServiceInterface define las operaciones del servicio de cursos
 type CourseServiceInterface interface {
-	SearchCourse(query string) ([]domain.Course, error)
-	GetCourse(id int64) (domain.Course, error)
-	GetAllCourses() ([]domain.Course, error)
-	GetCoursesByInstructor(instructor string) ([]domain.Course, error)
-	GetCourseImages(courseID int64) ([]domain.File, error)
-	Subscription(userID, courseID int64) error
-	CreateCourse(title, description, category, instructor string, duration int64, requirement string) error
-	UpdateCourse(courseID int64, title, description, category, instructor string, duration int64, requirement string) error
-	DeleteCourse(courseID int64) error
-	CommentList(courseID int64) ([]domain.CommentResponse, error)
+	SearchCourse(query domain.CourseQuery, actor domain.Actor) (domain.SearchResponse, error)
+	SuggestCourses(prefix string, limit int, actor domain.Actor) ([]domain.Suggestion, error)
+	GetCourse(id int64, actor domain.Actor) (domain.Course, error)
+	GetAllCourses(query domain.CourseQuery, actor domain.Actor) (domain.SearchResponse, error)
+	GetCoursesByInstructor(instructor string, actor domain.Actor) ([]domain.Course, error)
+	GetCourseImages(courseID int64, actor domain.Actor) ([]domain.File, error)
+	GetCourseFile(courseID, fileID int64, actor domain.Actor) (domain.File, string, error)
+	Subscription(userID, courseID int64, actor domain.Actor) error
+	CreateCourse(title, description, category, instructor string, duration int64, requirement string, actor domain.Actor) (domain.Course, error)
+	UpdateCourse(courseID int64, title, description, category, instructor string, duration int64, requirement string, actor domain.Actor) error
+	PatchCourse(courseID int64, patch domain.CourseRequest, actor domain.Actor) (domain.Course, error)
+	DeleteCourse(courseID int64, actor domain.Actor) error
+	ListTrash() ([]domain.Course, error)
+	RestoreCourse(courseID int64, actor domain.Actor) (domain.Course, error)
+	ListRevisions(courseID int64, actor domain.Actor) ([]domain.CourseRevision, error)
+	DiffRevisions(courseID int64, from, to int, actor domain.Actor) (domain.RevisionDiff, error)
+	RestoreRevision(courseID int64, number int, actor domain.Actor) (domain.Course, error)
+	ChangeCourseStatus(courseID int64, request domain.CourseStatusRequest, actor domain.Actor) (domain.Course, error)
+	ListStaff(courseID int64, actor domain.Actor) ([]domain.CourseStaff, error)
+	AddStaff(courseID, userID int64, role string, actor domain.Actor) error
+	RemoveStaff(courseID, userID int64, actor domain.Actor) error
+	CommentList(courseID int64, actor domain.Actor) ([]domain.CommentResponse, error)
+	ListModules(courseID int64, actor domain.Actor) ([]domain.ModuleOutline, error)
+	CreateModule(courseID int64, request domain.ModuleRequest, actor domain.Actor) (domain.CourseModule, error)
+	UpdateModule(courseID, moduleID int64, request domain.ModuleRequest, actor domain.Actor) (domain.CourseModule, error)
+	DeleteModule(courseID, moduleID int64, actor domain.Actor) error
+	ListLessons(courseID int64, actor domain.Actor) ([]domain.Lesson, error)
+	GetLesson(courseID, lessonID int64, actor domain.Actor) (domain.Lesson, error)
+	CreateLesson(courseID int64, request domain.LessonRequest, actor domain.Actor) (domain.Lesson, error)
+	UpdateLesson(courseID, lessonID int64, request domain.LessonRequest, actor domain.Actor) (domain.Lesson, error)
+	DeleteLesson(courseID, lessonID int64, actor domain.Actor) error
+	ReorderLessons(courseID int64, lessonIDs []int64, actor domain.Actor) ([]domain.Lesson, error)
 }
 
 // CourseRepositoryInterface define las operaciones de acceso a datos de cursos
 type CourseRepositoryInterface interface {
-	GetCoursewithQuery(query string) ([]domain.Course, error)
 	GetCourseById(id int64) (*domain.Course, error)
-	GetCourses() ([]domain.Course, error)
+	GetCourses(query domain.CourseQuery) ([]domain.Course, int64, error)
+	GetSubscriptionCounts(courseIDs []int64) (map[int64]int64, error)
 	GetCoursesByInstructor(instructor string) ([]domain.Course, error)
 	GetCourseImages(courseID int64) ([]domain.File, error)
 	GetUserById(userID int64) (*domain.User, error)
 	InsertSubscription(userID, courseID int64) error
-	CreateCourse(course domain.Course) error
-	UpdateCourse(courseID int64, course domain.Course) error
-	DeleteCourseById(courseID int64) error
-	DeleteSubscriptionById(courseID int64) error
+	CreateCourse(course domain.Course, ownerID int64, revision domain.CourseRevision) (domain.Course, error)
+	UpdateCourse(courseID int64, course domain.Course, baseline, revision domain.CourseRevision) error
+	UpdateCourseStatus(courseID int64, status string, publishAt *time.Time) error
+	TrashCourse(courseID int64) error
+	GetTrashedCourses() ([]domain.Course, error)
+	RestoreCourse(courseID int64) error
+	PurgeCourse(courseID int64) ([]domain.File, error)
+	GetCourseRevisions(courseID int64) ([]domain.CourseRevision, error)
+	GetCourseRevision(courseID int64, number int) (*domain.CourseRevision, error)
 	GetCommentsByCourseId(courseID int64) ([]int64, error)
 	GetCommentById(commentID int64) (domain.Comment, error)
+	GetCourseStaffMember(courseID, userID int64) (*domain.CourseStaff, error)
+	GetStaffCourseIDs(userID int64) ([]int64, error)
+	GetCourseStaff(courseID int64) ([]domain.CourseStaff, error)
+	SaveCourseStaff(staff domain.CourseStaff) error
+	RemoveCourseStaff(courseID, userID int64) error
+	IsSubscribed(userID, courseID int64) (bool, error)
+	GetFileById(fileID int64) (*domain.File, error)
+	GetModules(courseID int64) ([]domain.CourseModule, error)
+	GetModuleById(moduleID int64) (*domain.CourseModule, error)
+	CreateModule(module domain.CourseModule) (domain.CourseModule, error)
+	UpdateModule(module domain.CourseModule) error
+	DeleteModule(moduleID int64) error
+	GetLessons(courseID int64) ([]domain.Lesson, error)
+	GetLessonById(lessonID int64) (*domain.Lesson, error)
+	CreateLesson(lesson domain.Lesson) (domain.Lesson, error)
+	UpdateLesson(lesson domain.Lesson) error
+	DeleteLesson(lessonID int64) error
+	ReorderLessons(courseID int64, lessonIDs []int64) error
 }
@@ -2,6 +2,7 @@ package interfaces
 
 import (
 	"backend/domain"
+	"time"
 )
 
 // DatabaseClientInterface define las operaciones del cliente de base de datos
@@ -10,30 +11,106 @@ type DatabaseClientInterface interface {
 	GetUserByEmail(email string) (*domain.User, error)
 	CreateUser(user domain.User) error
 	GetUserById(id int64) (*domain.User, error)
+	UpdatePasswordHash(userID int64, passwordHash string) error
+	SetEmailVerified(userID int64, verifiedAt time.Time) error
+	IsNicknameTaken(nickname string, excludeUserID int64) (bool, error)
+	IsEmailTaken(email string, excludeUserID int64) (bool, error)
+	UpdateNickname(userID int64, nickname string) error
+	SetPendingEmail(userID int64, email string) error
+	ConfirmEmailChange(userID int64, email string, verifiedAt time.Time) error
+	DeleteUser(userID int64) error
+	GetSoleOwnedCourseIDs(userID int64) ([]int64, error)
+	ListUsers(query domain.UserQuery) ([]domain.User, int64, error)
+	SetUserDisabled(userID int64, disabledAt *time.Time) error
+	SetPasswordResetRequired(userID int64, required bool) error
+
+	// Operaciones de roles
+	GetRoles() ([]domain.Role, error)
+	SetUserRoles(userID int64, roleNames []string) error
 
 	// Operaciones de cursos
-	GetCoursewithQuery(query string) ([]domain.Course, error)
 	GetCourseById(id int64) (*domain.Course, error)
-	GetCourses() ([]domain.Course, error)
+	GetCourses(query domain.CourseQuery) ([]domain.Course, int64, error)
+	GetSubscriptionCounts(courseIDs []int64) (map[int64]int64, error)
 	GetCoursesByInstructor(instructor string) ([]domain.Course, error)
-	CreateCourse(course domain.Course) error
-	UpdateCourse(courseID int64, course domain.Course) error
-	DeleteCourseById(courseID int64) error
+	CreateCourse(course domain.Course, ownerID int64, revision domain.CourseRevision) (domain.Course, error)
+	UpdateCourse(courseID int64, course domain.Course, baseline, revision domain.CourseRevision) error
+	UpdateCourseStatus(courseID int64, status string, publishAt *time.Time) error
+	TrashCourse(courseID int64) error
+	GetTrashedCourses() ([]domain.Course, error)
+	RestoreCourse(courseID int64) error
+	PurgeCourse(courseID int64) ([]domain.File, error)
+	GetCourseRevisions(courseID int64) ([]domain.CourseRevision, error)
+	GetCourseRevision(courseID int64, number int) (*domain.CourseRevision, error)
+
+	// Operaciones de staff de cursos
+	GetCourseStaffMember(courseID, userID int64) (*domain.CourseStaff, error)
+	GetStaffCourseIDs(userID int64) ([]int64, error)
+	GetCourseStaff(courseID int64) ([]domain.CourseStaff, error)
+	SaveCourseStaff(staff domain.CourseStaff) error
+	RemoveCourseStaff(courseID, userID int64) error
+
+	// Operaciones de módulos y lecciones
+	GetModules(courseID int64) ([]domain.CourseModule, error)
+	GetModuleById(moduleID int64) (*domain.CourseModule, error)
+	CreateModule(module domain.CourseModule) (domain.CourseModule, error)
+	UpdateModule(module domain.CourseModule) error
+	DeleteModule(moduleID int64) error
+	GetLessons(courseID int64) ([]domain.Lesson, error)
+	GetLessonById(lessonID int64) (*domain.Lesson, error)
+	CreateLesson(lesson domain.Lesson) (domain.Lesson, error)
+	UpdateLesson(lesson domain.Lesson) error
+	DeleteLesson(lessonID int64) error
+	ReorderLessons(courseID int64, lessonIDs []int64) error
 
 	// Operaciones de suscripciones
 	InsertSubscription(userID, courseID int64) error
 	GetCourseIdsByUserId(userID int64) ([]int64, error)
-	DeleteSubscriptionById(courseID int64) error
+	IsSubscribed(userID, courseID int64) (bool, error)
 
 	// Operaciones de comentarios
 	InsertComment(userID, courseID int64, comment string) error
+	GetCommentsByUserId(userID int64) ([]domain.Comment, error)
 	GetCommentsByCourseId(courseID int64) ([]int64, error)
 	GetCommentById(commentID int64) (domain.Comment, error)
 
 	// Operaciones de archivos
 	SaveFile(file domain.File) error
+	GetFileById(fileID int64) (*domain.File, error)
+	GetFilesByUserId(userID int64) ([]domain.File, error)
 	GetCourseImages(courseID int64) ([]domain.File, error)
 
+	// Operaciones de sesiones
+	CreateSession(session domain.Session) (domain.Session, error)
+	GetSessionById(id int64) (*domain.Session, error)
+	GetSessionByRefreshHash(refreshTokenHash string) (*domain.Session, error)
+	GetActiveSessionsByUserId(userID int64) ([]domain.Session, error)
+	RotateSessionRefresh(id int64, currentHash, newHash string, lastUsedAt time.Time) error
+	RevokeSession(id int64, revokedAt time.Time) error
+	RevokeUserSessions(userID int64, revokedAt time.Time) error
+
+	// Operaciones de tokens de un solo uso
+	CreateUserToken(token domain.UserToken) error
+	GetUserTokenByHash(tokenHash string) (*domain.UserToken, error)
+	ConsumeUserToken(id int64, usedAt time.Time) error
+	InvalidateUserTokens(userID int64, purpose string, usedAt time.Time) error
+
+	// Operaciones de 2FA
+	GetTwoFactor(userID int64) (*domain.TwoFactor, error)
+	SaveTwoFactor(twoFactor domain.TwoFactor) error
+	EnableTwoFactor(userID int64, counter int64, enabledAt time.Time) error
+	UpdateTwoFactorCounter(userID int64, counter int64) error
+	DeleteTwoFactor(userID int64) error
+	ReplaceRecoveryCodes(userID int64, codeHashes []string) error
+	UseRecoveryCode(userID int64, codeHash string, usedAt time.Time) error
+
+	// Operaciones de emails salientes
+	SaveOutboxEmail(email domain.OutboxEmail) error
+
+	// Operaciones de auditoría
+	CreateAuditEvent(event domain.AuditEvent) error
+	ListAuditEvents(query domain.AuditQuery) ([]domain.AuditEvent, int64, error)
+
 	// Operaciones de migración
 	AutoMigrate() error
 }
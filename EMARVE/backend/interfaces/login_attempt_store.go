@@ -0,0 +1,14 @@
+package interfaces
+
+import (
+	"backend/domain"
+	"time"
+)
+
+// LoginAttemptStoreInterface guarda los contadores de intentos fallidos de login.
+// Las entradas vencen solas pasado el ttl indicado al guardarlas.
+type LoginAttemptStoreInterface interface {
+	Get(key string) (domain.LoginAttempt, bool)
+	Set(key string, attempt domain.LoginAttempt, ttl time.Duration)
+	Delete(key string)
+}
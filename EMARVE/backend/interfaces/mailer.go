@@ -0,0 +1,13 @@
+package interfaces
+
+import "backend/domain"
+
+// MailerInterface envía emails a los usuarios
+type MailerInterface interface {
+	Send(email domain.Email) error
+}
+
+// OutboxRepositoryInterface guarda los emails salientes en la tabla outbox
+type OutboxRepositoryInterface interface {
+	SaveOutboxEmail(email domain.OutboxEmail) error
+}
@@ -0,0 +1,8 @@
+package interfaces
+
+// PasswordHasherInterface define las operaciones de hash y verificación de contraseñas
+type PasswordHasherInterface interface {
+	Hash(password string) (string, error)
+	Verify(password, encodedHash string) (bool, error)
+	NeedsRehash(encodedHash string) bool
+}
@@ -0,0 +1,15 @@
+package interfaces
+
+import "backend/domain"
+
+// CourseSearchIndexInterface es el índice de texto de los cursos. El servicio de cursos lo
+// mantiene actualizado en cada alta, cambio y baja.
+type CourseSearchIndexInterface interface {
+	Index(course domain.Course)
+	Remove(courseID int64)
+	Search(query string) []domain.SearchHit
+	Courses() []domain.Course
+	Suggest(prefix string, limit int, visible func(domain.Course) bool) []domain.Suggestion
+	Correct(query string, visible func(domain.Course) bool) string
+	Len() int
+}
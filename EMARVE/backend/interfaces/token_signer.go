@@ -0,0 +1,10 @@
+package interfaces
+
+import "backend/domain"
+
+// TokenSignerInterface firma y verifica JWT con el conjunto de claves configurado
+type TokenSignerInterface interface {
+	Sign(claims map[string]interface{}) (string, error)
+	Verify(tokenString string) (map[string]interface{}, error)
+	JWKS() domain.JWKSet
+}
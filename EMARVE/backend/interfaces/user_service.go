@@ -3,18 +3,56 @@ package interfaces
 import (
 	"backend/domain"
 	"io"
+	"time"
 )
 
+// TokenValidatorInterface valida un Bearer token y devuelve sus claims
+type TokenValidatorInterface interface {
+	ValidateToken(tokenString string) (domain.TokenClaims, error)
+}
+
 // UserServiceInterface define las operaciones del servicio de usuarios
 type UserServiceInterface interface {
-	Login(email, password string) (string, error)
+	TokenValidatorInterface
+	Login(email, password string, client domain.ClientInfo) (domain.LoginResponse, error)
+	RefreshToken(refreshToken string, client domain.ClientInfo) (domain.LoginResponse, error)
+	Logout(sessionID int64) error
+	ListSessions(userID, currentSessionID int64) ([]domain.SessionResponse, error)
+	RevokeSession(userID, sessionID int64) error
+	JWKS() domain.JWKSet
+	ForgotPassword(email string) error
+	ResetPassword(token, newPassword string) error
+	VerifyEmail(token string) error
+	ResendVerification(userID int64) error
+	MarkEmailVerified(userID int64, actor domain.Actor) error
+	UnlockUser(userID int64, actor domain.Actor) error
+	ListRoles() ([]domain.Role, error)
+	SetUserRoles(userID int64, roles []string, actor domain.Actor) error
+	CompleteMFALogin(mfaToken, code string, client domain.ClientInfo) (domain.LoginResponse, error)
+	BeginMFAEnrollment(mfaToken string) (domain.TOTPEnrollment, error)
+	BeginTOTPEnrollment(userID int64) (domain.TOTPEnrollment, error)
+	ConfirmTOTPEnrollment(userID int64, code string) ([]string, error)
+	DisableTOTP(userID int64, code string) error
 	UserRegister(nickname, email, password string, typeUser bool) (bool, error)
 	SubscriptionList(userID int64) ([]domain.Course, error)
 	AddComment(userID, courseID int64, comment string) error
-	UploadFiles(file io.Reader, filename string, userID, courseID int64) error
+	UploadFiles(file io.Reader, filename string, userID, courseID int64, actor domain.Actor) error
 	UserAuthentication(tokenString string) (string, error)
 	GetUserID(tokenString string) (int, error)
 	GetUserById(userID int64) (*domain.User, error)
+	UpdateUser(userID int64, request domain.UpdateUserRequest, actor domain.Actor) (*domain.User, error)
+	ConfirmEmailChange(token string) error
+	ExportUserData(userID int64, actor domain.Actor) (domain.UserExport, error)
+	WriteExportArchive(export domain.UserExport, w io.Writer) error
+	DeleteUser(userID int64, currentPassword string, actor domain.Actor) error
+	ListUsers(query domain.UserQuery) (domain.UserPage, error)
+	PromoteUser(userID int64, role string, actor domain.Actor) error
+	DemoteUser(userID int64, role string, actor domain.Actor) error
+	DisableUser(userID int64, actor domain.Actor) error
+	EnableUser(userID int64, actor domain.Actor) error
+	ForcePasswordReset(userID int64, actor domain.Actor) error
+	ImportUsers(r io.Reader, options domain.UserImportOptions, actor domain.Actor) (domain.UserImportReport, error)
+	ExportUsersCSV(query domain.UserQuery, w io.Writer) error
 }
 
 // UserRepositoryInterface define las operaciones de acceso a datos de usuarios
@@ -22,8 +60,44 @@ type UserRepositoryInterface interface {
 	GetUserByEmail(email string) (*domain.User, error)
 	CreateUser(user domain.User) error
 	GetUserById(id int64) (*domain.User, error)
+	UpdatePasswordHash(userID int64, passwordHash string) error
+	SetEmailVerified(userID int64, verifiedAt time.Time) error
+	IsNicknameTaken(nickname string, excludeUserID int64) (bool, error)
+	IsEmailTaken(email string, excludeUserID int64) (bool, error)
+	UpdateNickname(userID int64, nickname string) error
+	SetPendingEmail(userID int64, email string) error
+	ConfirmEmailChange(userID int64, email string, verifiedAt time.Time) error
+	DeleteUser(userID int64) error
+	GetSoleOwnedCourseIDs(userID int64) ([]int64, error)
+	ListUsers(query domain.UserQuery) ([]domain.User, int64, error)
+	SetUserDisabled(userID int64, disabledAt *time.Time) error
+	SetPasswordResetRequired(userID int64, required bool) error
+	GetRoles() ([]domain.Role, error)
+	SetUserRoles(userID int64, roleNames []string) error
 	GetCourseIdsByUserId(userID int64) ([]int64, error)
 	GetCourseById(courseID int64) (*domain.Course, error)
+	InsertSubscription(userID, courseID int64) error
 	InsertComment(userID, courseID int64, comment string) error
+	GetCommentsByUserId(userID int64) ([]domain.Comment, error)
 	SaveFile(file domain.File) error
+	GetFilesByUserId(userID int64) ([]domain.File, error)
+	GetCourseStaffMember(courseID, userID int64) (*domain.CourseStaff, error)
+	CreateSession(session domain.Session) (domain.Session, error)
+	GetSessionById(id int64) (*domain.Session, error)
+	GetSessionByRefreshHash(refreshTokenHash string) (*domain.Session, error)
+	GetActiveSessionsByUserId(userID int64) ([]domain.Session, error)
+	RotateSessionRefresh(id int64, currentHash, newHash string, lastUsedAt time.Time) error
+	RevokeSession(id int64, revokedAt time.Time) error
+	RevokeUserSessions(userID int64, revokedAt time.Time) error
+	CreateUserToken(token domain.UserToken) error
+	GetUserTokenByHash(tokenHash string) (*domain.UserToken, error)
+	ConsumeUserToken(id int64, usedAt time.Time) error
+	InvalidateUserTokens(userID int64, purpose string, usedAt time.Time) error
+	GetTwoFactor(userID int64) (*domain.TwoFactor, error)
+	SaveTwoFactor(twoFactor domain.TwoFactor) error
+	EnableTwoFactor(userID int64, counter int64, enabledAt time.Time) error
+	UpdateTwoFactorCounter(userID int64, counter int64) error
+	DeleteTwoFactor(userID int64) error
+	ReplaceRecoveryCodes(userID int64, codeHashes []string) error
+	UseRecoveryCode(userID int64, codeHash string, usedAt time.Time) error
 }
@@ -2,6 +2,9 @@ package main
 
 import (
 	"backend/app"
+	"fmt"
+	"os"
+	"strings"
 	"time"
 
 	"backend/clients"
@@ -14,12 +17,18 @@ func main() {
 	clients.StartDB()
 	engine := gin.New()
 
+	// Solo se confía en X-Forwarded-For si el request viene de un proxy de TRUSTED_PROXIES
+	// (IPs o CIDRs separados por coma). Sin proxies ClientIP es la IP de la conexión.
+	if err := engine.SetTrustedProxies(trustedProxies()); err != nil {
+		panic(fmt.Errorf("error configuring trusted proxies: %v", err))
+	}
+
 	// Configuración CORS para permitir solicitudes desde el frontend
 	config := cors.Config{
 		AllowOrigins:     []string{"http://localhost:3000", "http://localhost:3001", "http://localhost:3002", "http://localhost:3003"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "X-Auth-Token"},
-		ExposeHeaders:    []string{"Content-Length"},
+		ExposeHeaders:    []string{"Content-Length", "Retry-After"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}
@@ -28,3 +37,13 @@ func main() {
 	app.MapRoutes(engine)
 	engine.Run(":8080")
 }
+
+func trustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
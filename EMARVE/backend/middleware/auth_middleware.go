@@ -0,0 +1,206 @@
+package middleware
+
+import (
+	"backend/domain"
+	"backend/interfaces"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Claves usadas para guardar la identidad del usuario en el contexto de gin
+const (
+	ContextUserID      = "auth.userID"
+	ContextRole        = "auth.role"
+	ContextRoles       = "auth.roles"
+	ContextPermissions = "auth.permissions"
+	ContextSessionID   = "auth.sessionID"
+)
+
+type AuthMiddleware struct {
+	validator interfaces.TokenValidatorInterface
+}
+
+func NewAuthMiddleware(validator interfaces.TokenValidatorInterface) *AuthMiddleware {
+	return &AuthMiddleware{validator: validator}
+}
+
+// Authenticate rechaza el request si no trae un Bearer token válido y, si lo trae,
+// deja el ID, los roles y los permisos del usuario en el contexto para los handlers siguientes
+func (m *AuthMiddleware) Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, domain.Result{
+				Message: "Authorization header is required",
+			})
+			return
+		}
+
+		if !strings.HasPrefix(authHeader, "Bearer ") {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, domain.Result{
+				Message: "Unauthorized: bearer token is required",
+			})
+			return
+		}
+
+		claims, err := m.validator.ValidateToken(authHeader)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, domain.Result{
+				Message: fmt.Sprintf("Unauthorized: %s", err.Error()),
+			})
+			return
+		}
+
+		setClaims(c, claims)
+		c.Next()
+	}
+}
+
+// OptionalAuthenticate deja la identidad del usuario en el contexto si el request trae un
+// Bearer token válido y, si no, sigue como anónimo. Es para rutas públicas cuya respuesta
+// depende de quién pregunta.
+func (m *AuthMiddleware) OptionalAuthenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if strings.HasPrefix(authHeader, "Bearer ") {
+			if claims, err := m.validator.ValidateToken(authHeader); err == nil {
+				setClaims(c, claims)
+			}
+		}
+		c.Next()
+	}
+}
+
+func setClaims(c *gin.Context, claims domain.TokenClaims) {
+	c.Set(ContextUserID, claims.UserID)
+	c.Set(ContextRole, claims.Role)
+	c.Set(ContextRoles, claims.Roles)
+	c.Set(ContextPermissions, claims.Permissions)
+	c.Set(ContextSessionID, claims.SessionID)
+}
+
+// RequireRoles permite continuar solo si el usuario autenticado tiene alguno de los roles indicados.
+// Debe usarse después de Authenticate.
+func (m *AuthMiddleware) RequireRoles(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, ok := GetRole(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, domain.Result{
+				Message: "Unauthorized: missing authentication",
+			})
+			return
+		}
+
+		for _, allowed := range roles {
+			if HasRole(c, allowed) {
+				c.Next()
+				return
+			}
+		}
+
+		c.AbortWithStatusJSON(http.StatusForbidden, domain.Result{
+			Message: fmt.Sprintf("Forbidden: role %s is not allowed", role),
+		})
+	}
+}
+
+// RequirePermissions permite continuar solo si el usuario autenticado tiene todos los permisos
+// indicados. Debe usarse después de Authenticate.
+func (m *AuthMiddleware) RequirePermissions(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := GetUserID(c); !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, domain.Result{
+				Message: "Unauthorized: missing authentication",
+			})
+			return
+		}
+
+		for _, required := range permissions {
+			if !HasPermission(c, required) {
+				c.AbortWithStatusJSON(http.StatusForbidden, domain.Result{
+					Message: fmt.Sprintf("Forbidden: permission %s is required", required),
+				})
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// GetUserID devuelve el ID del usuario autenticado guardado por Authenticate
+func GetUserID(c *gin.Context) (int64, bool) {
+	value, exists := c.Get(ContextUserID)
+	if !exists {
+		return 0, false
+	}
+	userID, ok := value.(int64)
+	return userID, ok
+}
+
+// GetRole devuelve el rol del usuario autenticado guardado por Authenticate
+func GetRole(c *gin.Context) (string, bool) {
+	value, exists := c.Get(ContextRole)
+	if !exists {
+		return "", false
+	}
+	role, ok := value.(string)
+	return role, ok
+}
+
+// GetRoles devuelve todos los roles del usuario autenticado. Si no se guardó la lista,
+// devuelve el rol principal.
+func GetRoles(c *gin.Context) []string {
+	if value, exists := c.Get(ContextRoles); exists {
+		if roles, ok := value.([]string); ok && len(roles) > 0 {
+			return roles
+		}
+	}
+	if role, ok := GetRole(c); ok {
+		return []string{role}
+	}
+	return nil
+}
+
+// HasRole indica si el usuario autenticado tiene el rol
+func HasRole(c *gin.Context, role string) bool {
+	for _, assigned := range GetRoles(c) {
+		if assigned == role {
+			return true
+		}
+	}
+	return false
+}
+
+// GetPermissions devuelve los permisos del usuario autenticado guardados por Authenticate
+func GetPermissions(c *gin.Context) []string {
+	value, exists := c.Get(ContextPermissions)
+	if !exists {
+		return nil
+	}
+	permissions, _ := value.([]string)
+	return permissions
+}
+
+// HasPermission indica si el usuario autenticado tiene el permiso
+func HasPermission(c *gin.Context, permission string) bool {
+	for _, granted := range GetPermissions(c) {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// GetSessionID devuelve la sesión a la que pertenece el token del request
+func GetSessionID(c *gin.Context) (int64, bool) {
+	value, exists := c.Get(ContextSessionID)
+	if !exists {
+		return 0, false
+	}
+	sessionID, ok := value.(int64)
+	return sessionID, ok
+}
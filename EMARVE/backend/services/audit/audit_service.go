@@ -0,0 +1,108 @@
+package audit
+
+import (
+	"backend/domain"
+	"backend/interfaces"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+type auditService struct {
+	repo interfaces.AuditRepositoryInterface
+}
+
+func NewAuditService(repo interfaces.AuditRepositoryInterface) interfaces.AuditServiceInterface {
+	return &auditService{repo: repo}
+}
+
+// Record guarda el evento. Un fallo al auditar se loguea pero no interrumpe la operación
+// que lo originó.
+func (s *auditService) Record(event domain.AuditEvent) {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	if err := s.repo.CreateAuditEvent(event); err != nil {
+		log.WithFields(log.Fields{
+			"action":      event.Action,
+			"actor_id":    event.ActorID,
+			"target_type": event.TargetType,
+			"target_id":   event.TargetID,
+		}).Errorf("error saving audit event: %v", err)
+	}
+}
+
+// List devuelve los eventos que cumplen los filtros, del más reciente al más antiguo
+func (s *auditService) List(query domain.AuditQuery) (domain.AuditPage, error) {
+	if query.From != nil && query.To != nil && query.From.After(*query.To) {
+		return domain.AuditPage{}, errors.New("from must be before to")
+	}
+
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PageSize < 1 {
+		query.PageSize = DefaultPageSize
+	}
+	if query.PageSize > MaxPageSize {
+		query.PageSize = MaxPageSize
+	}
+
+	events, total, err := s.repo.ListAuditEvents(query)
+	if err != nil {
+		return domain.AuditPage{}, fmt.Errorf("error getting audit events from DB: %v", err)
+	}
+
+	if events == nil {
+		events = make([]domain.AuditEvent, 0)
+	}
+
+	return domain.AuditPage{
+		Result:   events,
+		Total:    total,
+		Page:     query.Page,
+		PageSize: query.PageSize,
+	}, nil
+}
+
+// nopRecorder descarta los eventos. Es el recorder por defecto de los servicios que no
+// reciben uno, por ejemplo en los tests.
+type nopRecorder struct{}
+
+func NewNopRecorder() interfaces.AuditRecorderInterface {
+	return nopRecorder{}
+}
+
+func (nopRecorder) Record(domain.AuditEvent) {}
+
+// Snapshot serializa una entidad para guardarla como estado anterior o posterior de un evento
+func Snapshot(value interface{}) json.RawMessage {
+	data, err := json.Marshal(value)
+	if err != nil {
+		log.Warnf("error serializing audit snapshot: %v", err)
+		return nil
+	}
+	return data
+}
+
+// NewEvent arma un evento con los datos del actor
+func NewEvent(actor domain.Actor, action, targetType string, targetID int64) domain.AuditEvent {
+	return domain.AuditEvent{
+		ActorID:    actor.UserID,
+		ActorRole:  actor.Role,
+		OnBehalfOf: actor.OnBehalfOf,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		IP:         actor.IP,
+	}
+}
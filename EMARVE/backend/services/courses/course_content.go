@@ -0,0 +1,424 @@
+package courses
+
+import (
+	"backend/domain"
+	"backend/services/audit"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// authorizeContent permite ver el contenido del curso a sus suscriptos, a su staff y a los admins
+func (s *courseService) authorizeContent(courseID int64, actor domain.Actor) error {
+	if err := s.courseExists(courseID); err != nil {
+		return err
+	}
+	if actor.Role == domain.RoleAdmin {
+		return nil
+	}
+
+	member, err := s.repo.GetCourseStaffMember(courseID, actor.UserID)
+	if err != nil {
+		return fmt.Errorf("error getting course staff from DB: %v", err)
+	}
+	if member != nil {
+		return nil
+	}
+
+	subscribed, err := s.repo.IsSubscribed(actor.UserID, courseID)
+	if err != nil {
+		return fmt.Errorf("error checking subscription in DB: %v", err)
+	}
+	if !subscribed {
+		return domain.ErrNotSubscribed
+	}
+	return nil
+}
+
+// authorizeContentChange permite editar el contenido a los mismos que pueden editar el curso.
+// El contenido de un curso archivado queda de solo lectura.
+func (s *courseService) authorizeContentChange(courseID int64, actor domain.Actor) error {
+	course, err := s.repo.GetCourseById(courseID)
+	if err != nil {
+		return fmt.Errorf("%w: %d", domain.ErrCourseNotFound, courseID)
+	}
+	if course.Status == domain.CourseArchived {
+		return domain.ErrCourseArchived
+	}
+	return s.authorize(courseID, actor, domain.StaffOwner, domain.StaffCoInstructor)
+}
+
+func (s *courseService) courseExists(courseID int64) error {
+	if _, err := s.repo.GetCourseById(courseID); err != nil {
+		return fmt.Errorf("%w: %d", domain.ErrCourseNotFound, courseID)
+	}
+	return nil
+}
+
+// ListModules devuelve los módulos del curso con sus lecciones en orden
+func (s *courseService) ListModules(courseID int64, actor domain.Actor) ([]domain.ModuleOutline, error) {
+	if err := s.authorizeContent(courseID, actor); err != nil {
+		return nil, err
+	}
+
+	modules, err := s.repo.GetModules(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting modules from DB: %v", err)
+	}
+	lessons, err := s.repo.GetLessons(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting lessons from DB: %v", err)
+	}
+
+	outline := make([]domain.ModuleOutline, 0, len(modules))
+	positions := make(map[int64]int, len(modules))
+	for _, module := range modules {
+		positions[module.Id] = len(outline)
+		outline = append(outline, domain.ModuleOutline{CourseModule: module, Lessons: make([]domain.Lesson, 0)})
+	}
+	for _, lesson := range lessons {
+		if index, ok := positions[lesson.ModuleID]; ok {
+			outline[index].Lessons = append(outline[index].Lessons, lesson)
+		}
+	}
+	return outline, nil
+}
+
+func (s *courseService) CreateModule(courseID int64, request domain.ModuleRequest, actor domain.Actor) (domain.CourseModule, error) {
+	title, err := contentTitle(request.Title)
+	if err != nil {
+		return domain.CourseModule{}, err
+	}
+	if err := s.authorizeContentChange(courseID, actor); err != nil {
+		return domain.CourseModule{}, err
+	}
+
+	now := time.Now()
+	module, err := s.repo.CreateModule(domain.CourseModule{
+		CourseID:  courseID,
+		Title:     title,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		return domain.CourseModule{}, fmt.Errorf("error creating module in DB: %v", err)
+	}
+
+	event := audit.NewEvent(actor, domain.AuditModuleCreated, domain.AuditTargetCourse, courseID)
+	event.Details = fmt.Sprintf("module %d", module.Id)
+	event.After = audit.Snapshot(module)
+	s.auditLog.Record(event)
+
+	return module, nil
+}
+
+func (s *courseService) UpdateModule(courseID, moduleID int64, request domain.ModuleRequest, actor domain.Actor) (domain.CourseModule, error) {
+	title, err := contentTitle(request.Title)
+	if err != nil {
+		return domain.CourseModule{}, err
+	}
+	if err := s.authorizeContentChange(courseID, actor); err != nil {
+		return domain.CourseModule{}, err
+	}
+
+	before, err := s.courseModule(courseID, moduleID)
+	if err != nil {
+		return domain.CourseModule{}, err
+	}
+
+	after := *before
+	after.Title = title
+	after.UpdatedAt = time.Now()
+	if err := s.repo.UpdateModule(after); err != nil {
+		return domain.CourseModule{}, fmt.Errorf("error updating module in DB: %v", err)
+	}
+
+	event := audit.NewEvent(actor, domain.AuditModuleUpdated, domain.AuditTargetCourse, courseID)
+	event.Details = fmt.Sprintf("module %d", moduleID)
+	event.Before = audit.Snapshot(before)
+	event.After = audit.Snapshot(after)
+	s.auditLog.Record(event)
+
+	return after, nil
+}
+
+// DeleteModule borra el módulo y todas sus lecciones
+func (s *courseService) DeleteModule(courseID, moduleID int64, actor domain.Actor) error {
+	if err := s.authorizeContentChange(courseID, actor); err != nil {
+		return err
+	}
+
+	before, err := s.courseModule(courseID, moduleID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteModule(moduleID); err != nil {
+		return fmt.Errorf("error deleting module in DB: %v", err)
+	}
+
+	event := audit.NewEvent(actor, domain.AuditModuleDeleted, domain.AuditTargetCourse, courseID)
+	event.Details = fmt.Sprintf("module %d", moduleID)
+	event.Before = audit.Snapshot(before)
+	s.auditLog.Record(event)
+
+	return nil
+}
+
+func (s *courseService) ListLessons(courseID int64, actor domain.Actor) ([]domain.Lesson, error) {
+	if err := s.authorizeContent(courseID, actor); err != nil {
+		return nil, err
+	}
+
+	lessons, err := s.repo.GetLessons(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting lessons from DB: %v", err)
+	}
+	if lessons == nil {
+		lessons = make([]domain.Lesson, 0)
+	}
+	return lessons, nil
+}
+
+func (s *courseService) GetLesson(courseID, lessonID int64, actor domain.Actor) (domain.Lesson, error) {
+	if err := s.authorizeContent(courseID, actor); err != nil {
+		return domain.Lesson{}, err
+	}
+
+	lesson, err := s.courseLesson(courseID, lessonID)
+	if err != nil {
+		return domain.Lesson{}, err
+	}
+	return *lesson, nil
+}
+
+// GetCourseFile devuelve un archivo del curso y su ubicación en disco. Como las lecciones,
+// solo lo pueden descargar los suscriptos, el staff y los admins.
+func (s *courseService) GetCourseFile(courseID, fileID int64, actor domain.Actor) (domain.File, string, error) {
+	if err := s.authorizeContent(courseID, actor); err != nil {
+		return domain.File{}, "", err
+	}
+
+	file, err := s.repo.GetFileById(fileID)
+	if err != nil || file.Course_Id != courseID {
+		return domain.File{}, "", fmt.Errorf("%w: file %d", domain.ErrContentNotFound, fileID)
+	}
+	return *file, s.uploadPath(*file), nil
+}
+
+// uploadPath ubica el archivo en el directorio de uploads sin confiar en la ruta guardada
+func (s *courseService) uploadPath(file domain.File) string {
+	return filepath.Join(s.uploadsDir, filepath.Base(file.Url))
+}
+
+// CreateLesson agrega la lección al final del curso
+func (s *courseService) CreateLesson(courseID int64, request domain.LessonRequest, actor domain.Actor) (domain.Lesson, error) {
+	if err := s.authorizeContentChange(courseID, actor); err != nil {
+		return domain.Lesson{}, err
+	}
+
+	lesson, err := s.lessonFromRequest(courseID, request)
+	if err != nil {
+		return domain.Lesson{}, err
+	}
+
+	now := time.Now()
+	lesson.CreatedAt = now
+	lesson.UpdatedAt = now
+	created, err := s.repo.CreateLesson(lesson)
+	if err != nil {
+		return domain.Lesson{}, fmt.Errorf("error creating lesson in DB: %v", err)
+	}
+
+	event := audit.NewEvent(actor, domain.AuditLessonCreated, domain.AuditTargetCourse, courseID)
+	event.Details = fmt.Sprintf("lesson %d", created.Id)
+	event.After = audit.Snapshot(created)
+	s.auditLog.Record(event)
+
+	return created, nil
+}
+
+// UpdateLesson reemplaza el contenido de la lección. Cambiar ModuleID la mueve de módulo
+// sin cambiar su posición en el curso.
+func (s *courseService) UpdateLesson(courseID, lessonID int64, request domain.LessonRequest, actor domain.Actor) (domain.Lesson, error) {
+	if err := s.authorizeContentChange(courseID, actor); err != nil {
+		return domain.Lesson{}, err
+	}
+
+	before, err := s.courseLesson(courseID, lessonID)
+	if err != nil {
+		return domain.Lesson{}, err
+	}
+
+	after, err := s.lessonFromRequest(courseID, request)
+	if err != nil {
+		return domain.Lesson{}, err
+	}
+	after.Id = before.Id
+	after.Position = before.Position
+	after.CreatedAt = before.CreatedAt
+	after.UpdatedAt = time.Now()
+
+	if err := s.repo.UpdateLesson(after); err != nil {
+		return domain.Lesson{}, fmt.Errorf("error updating lesson in DB: %v", err)
+	}
+
+	event := audit.NewEvent(actor, domain.AuditLessonUpdated, domain.AuditTargetCourse, courseID)
+	event.Details = fmt.Sprintf("lesson %d", lessonID)
+	event.Before = audit.Snapshot(before)
+	event.After = audit.Snapshot(after)
+	s.auditLog.Record(event)
+
+	return after, nil
+}
+
+func (s *courseService) DeleteLesson(courseID, lessonID int64, actor domain.Actor) error {
+	if err := s.authorizeContentChange(courseID, actor); err != nil {
+		return err
+	}
+
+	before, err := s.courseLesson(courseID, lessonID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteLesson(lessonID); err != nil {
+		return fmt.Errorf("error deleting lesson in DB: %v", err)
+	}
+
+	event := audit.NewEvent(actor, domain.AuditLessonDeleted, domain.AuditTargetCourse, courseID)
+	event.Details = fmt.Sprintf("lesson %d", lessonID)
+	event.Before = audit.Snapshot(before)
+	s.auditLog.Record(event)
+
+	return nil
+}
+
+// ReorderLessons recibe todas las lecciones del curso en el orden nuevo, sin repetidas
+func (s *courseService) ReorderLessons(courseID int64, lessonIDs []int64, actor domain.Actor) ([]domain.Lesson, error) {
+	if err := s.authorizeContentChange(courseID, actor); err != nil {
+		return nil, err
+	}
+
+	lessons, err := s.repo.GetLessons(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting lessons from DB: %v", err)
+	}
+
+	current := make(map[int64]bool, len(lessons))
+	for _, lesson := range lessons {
+		current[lesson.Id] = true
+	}
+	if len(lessonIDs) != len(lessons) {
+		return nil, fmt.Errorf("the order must include the %d lessons of the course", len(lessons))
+	}
+	seen := make(map[int64]bool, len(lessonIDs))
+	for _, id := range lessonIDs {
+		if !current[id] {
+			return nil, fmt.Errorf("lesson %d does not belong to course %d", id, courseID)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("lesson %d is repeated", id)
+		}
+		seen[id] = true
+	}
+
+	if err := s.repo.ReorderLessons(courseID, lessonIDs); err != nil {
+		return nil, fmt.Errorf("error reordering lessons in DB: %v", err)
+	}
+
+	event := audit.NewEvent(actor, domain.AuditLessonsReordered, domain.AuditTargetCourse, courseID)
+	event.Details = fmt.Sprint(lessonIDs)
+	s.auditLog.Record(event)
+
+	reordered, err := s.repo.GetLessons(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting lessons from DB: %v", err)
+	}
+	return reordered, nil
+}
+
+// lessonFromRequest valida la lección y deja solo el campo de contenido que corresponde al tipo
+func (s *courseService) lessonFromRequest(courseID int64, request domain.LessonRequest) (domain.Lesson, error) {
+	title, err := contentTitle(request.Title)
+	if err != nil {
+		return domain.Lesson{}, err
+	}
+
+	if request.ModuleID == 0 {
+		return domain.Lesson{}, errors.New("module_id is required")
+	}
+	if _, err := s.courseModule(courseID, request.ModuleID); err != nil {
+		return domain.Lesson{}, err
+	}
+
+	lesson := domain.Lesson{
+		CourseID: courseID,
+		ModuleID: request.ModuleID,
+		Title:    title,
+		Type:     request.Type,
+	}
+
+	switch request.Type {
+	case domain.LessonText:
+		if strings.TrimSpace(request.Content) == "" {
+			return domain.Lesson{}, errors.New("content is required for text lessons")
+		}
+		lesson.Content = request.Content
+
+	case domain.LessonLink:
+		link, err := url.Parse(strings.TrimSpace(request.URL))
+		if err != nil || (link.Scheme != "http" && link.Scheme != "https") || link.Host == "" {
+			return domain.Lesson{}, errors.New("url must be an absolute http or https URL")
+		}
+		lesson.URL = link.String()
+
+	case domain.LessonFile:
+		if request.FileID == nil {
+			return domain.Lesson{}, errors.New("file_id is required for file lessons")
+		}
+		file, err := s.repo.GetFileById(*request.FileID)
+		if err != nil || file.Course_Id != courseID {
+			return domain.Lesson{}, fmt.Errorf("file %d does not belong to course %d", *request.FileID, courseID)
+		}
+		lesson.FileID = &file.Id
+
+	default:
+		return domain.Lesson{}, fmt.Errorf("invalid lesson type %q", request.Type)
+	}
+
+	return lesson, nil
+}
+
+// courseModule busca el módulo y verifica que sea del curso de la ruta
+func (s *courseService) courseModule(courseID, moduleID int64) (*domain.CourseModule, error) {
+	module, err := s.repo.GetModuleById(moduleID)
+	if err != nil || module.CourseID != courseID {
+		return nil, fmt.Errorf("%w: module %d", domain.ErrContentNotFound, moduleID)
+	}
+	return module, nil
+}
+
+// courseLesson busca la lección y verifica que sea del curso de la ruta
+func (s *courseService) courseLesson(courseID, lessonID int64) (*domain.Lesson, error) {
+	lesson, err := s.repo.GetLessonById(lessonID)
+	if err != nil || lesson.CourseID != courseID {
+		return nil, fmt.Errorf("%w: lesson %d", domain.ErrContentNotFound, lessonID)
+	}
+	return lesson, nil
+}
+
+func contentTitle(title string) (string, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return "", errors.New("title is required")
+	}
+	if utf8.RuneCountInString(title) > domain.MaxContentTitleLength {
+		return "", fmt.Errorf("title must be at most %d characters", domain.MaxContentTitleLength)
+	}
+	return title, nil
+}
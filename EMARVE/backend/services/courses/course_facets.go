@@ -0,0 +1,149 @@
+package courses
+
+import (
+	"backend/domain"
+	"backend/services/search"
+	"sort"
+)
+
+// matchesFilters aplica en memoria los mismos filtros que el listado aplica en la base
+func matchesFilters(course domain.Course, query domain.CourseQuery) bool {
+	return matchesCategory(course, query) &&
+		matchesInstructor(course, query) &&
+		matchesDurationRange(course, query) &&
+		matchesDurationBucket(course, query)
+}
+
+func matchesCategory(course domain.Course, query domain.CourseQuery) bool {
+	return len(query.Categories) == 0 || containsFolded(query.Categories, course.Category)
+}
+
+func matchesInstructor(course domain.Course, query domain.CourseQuery) bool {
+	return len(query.Instructors) == 0 || containsFolded(query.Instructors, course.Instructor)
+}
+
+func matchesDurationRange(course domain.Course, query domain.CourseQuery) bool {
+	if query.MinDuration > 0 && course.Duration < query.MinDuration {
+		return false
+	}
+	return query.MaxDuration == 0 || course.Duration <= query.MaxDuration
+}
+
+func matchesDurationBucket(course domain.Course, query domain.CourseQuery) bool {
+	if len(query.DurationBuckets) == 0 {
+		return true
+	}
+	for _, key := range query.DurationBuckets {
+		if bucket, ok := domain.FindDurationBucket(key); ok && bucket.Contains(course.Duration) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFolded compara sin distinguir mayúsculas ni tildes, como la collation de la base
+func containsFolded(values []string, value string) bool {
+	folded := search.Fold(value)
+	for _, candidate := range values {
+		if search.Fold(candidate) == folded {
+			return true
+		}
+	}
+	return false
+}
+
+// buildFacets cuenta los valores de cada faceta entre los cursos que cumplen todos los demás
+// filtros. Así, con una categoría seleccionada, se ve cuántos cursos sumaría otra categoría.
+func buildFacets(hits []domain.SearchHit, query domain.CourseQuery) domain.SearchFacets {
+	categories := newFacetCounter(query.Categories)
+	instructors := newFacetCounter(query.Instructors)
+	durations := make(map[string]int)
+
+	for _, hit := range hits {
+		course := hit.Course
+		category := matchesCategory(course, query)
+		instructor := matchesInstructor(course, query)
+		durationRange := matchesDurationRange(course, query)
+		durationBucket := matchesDurationBucket(course, query)
+
+		if instructor && durationRange && durationBucket {
+			categories.add(course.Category)
+		}
+		if category && durationRange && durationBucket {
+			instructors.add(course.Instructor)
+		}
+		if category && instructor && durationRange {
+			for _, bucket := range domain.DurationBuckets {
+				if bucket.Contains(course.Duration) {
+					durations[bucket.Key]++
+				}
+			}
+		}
+	}
+
+	duration := make([]domain.FacetValue, 0, len(domain.DurationBuckets))
+	for _, bucket := range domain.DurationBuckets {
+		duration = append(duration, domain.FacetValue{
+			Value:    bucket.Key,
+			Count:    durations[bucket.Key],
+			Selected: containsFolded(query.DurationBuckets, bucket.Key),
+		})
+	}
+
+	return domain.SearchFacets{
+		Category:   categories.values(),
+		Instructor: instructors.values(),
+		Duration:   duration,
+	}
+}
+
+// facetCounter agrupa los valores sin distinguir mayúsculas ni tildes y muestra la primera
+// forma en que aparece cada uno
+type facetCounter struct {
+	selected []string
+	counts   map[string]*domain.FacetValue
+}
+
+func newFacetCounter(selected []string) *facetCounter {
+	counter := &facetCounter{selected: selected, counts: make(map[string]*domain.FacetValue)}
+	// Los valores seleccionados aparecen aunque ya no tengan cursos, para poder quitarlos
+	for _, value := range selected {
+		counter.entry(value)
+	}
+	return counter
+}
+
+func (f *facetCounter) entry(value string) *domain.FacetValue {
+	key := search.Fold(value)
+	if f.counts[key] == nil {
+		f.counts[key] = &domain.FacetValue{Value: value, Selected: containsFolded(f.selected, value)}
+	}
+	return f.counts[key]
+}
+
+func (f *facetCounter) add(value string) {
+	if value == "" {
+		return
+	}
+	entry := f.entry(value)
+	if entry.Count == 0 {
+		// Se prefiere la forma en que está escrito el curso a la de la selección
+		entry.Value = value
+	}
+	entry.Count++
+}
+
+// values ordena por cantidad de cursos y después alfabéticamente
+func (f *facetCounter) values() []domain.FacetValue {
+	values := make([]domain.FacetValue, 0, len(f.counts))
+	for _, value := range f.counts {
+		values = append(values, *value)
+	}
+	sort.Slice(values, func(a, b int) bool {
+		if values[a].Count != values[b].Count {
+			return values[a].Count > values[b].Count
+		}
+		return search.Fold(values[a].Value) < search.Fold(values[b].Value)
+	})
+	return values
+}
@@ -0,0 +1,81 @@
+package courses
+
+import (
+	"backend/domain"
+	"fmt"
+	"strings"
+)
+
+// Tamaño de página por defecto y máximo del listado de cursos
+const (
+	DefaultCoursesPageSize = 20
+	MaxCoursesPageSize     = 100
+)
+
+// normalizeCourseQuery valida el orden y los filtros y completa la paginación
+func normalizeCourseQuery(query domain.CourseQuery) (domain.CourseQuery, error) {
+	query.Query = strings.TrimSpace(query.Query)
+	query.Categories = cleanValues(query.Categories)
+	query.Instructors = cleanValues(query.Instructors)
+	query.DurationBuckets = cleanValues(query.DurationBuckets)
+
+	for _, key := range query.DurationBuckets {
+		if _, ok := domain.FindDurationBucket(key); !ok {
+			return query, fmt.Errorf("%w: unknown duration bucket %q", domain.ErrInvalidCourseQuery, key)
+		}
+	}
+
+	if query.Sort != "" {
+		if _, ok := domain.CourseSortFields[strings.TrimPrefix(query.Sort, "-")]; !ok {
+			return query, fmt.Errorf("%w: invalid sort field %q", domain.ErrInvalidCourseQuery, query.Sort)
+		}
+	}
+
+	if query.MinDuration < 0 || query.MaxDuration < 0 {
+		return query, fmt.Errorf("%w: duration must not be negative", domain.ErrInvalidCourseQuery)
+	}
+	if query.MaxDuration > 0 && query.MinDuration > query.MaxDuration {
+		return query, fmt.Errorf("%w: min_duration is greater than max_duration", domain.ErrInvalidCourseQuery)
+	}
+
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PageSize < 1 {
+		query.PageSize = DefaultCoursesPageSize
+	}
+	if query.PageSize > MaxCoursesPageSize {
+		query.PageSize = MaxCoursesPageSize
+	}
+
+	return query, nil
+}
+
+// cleanValues quita espacios y valores vacíos de una selección de facetas
+func cleanValues(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	cleaned := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			cleaned = append(cleaned, value)
+		}
+	}
+	if len(cleaned) == 0 {
+		return nil
+	}
+	return cleaned
+}
+
+func coursePage(courses []domain.Course, total int64, query domain.CourseQuery) domain.SearchResponse {
+	results := make([]domain.Course, 0, len(courses))
+	results = append(results, courses...)
+
+	return domain.SearchResponse{
+		Result:   results,
+		Total:    total,
+		Page:     query.Page,
+		PageSize: query.PageSize,
+	}
+}
@@ -0,0 +1,143 @@
+package courses
+
+import (
+	"backend/domain"
+	"fmt"
+	"time"
+)
+
+// ListRevisions devuelve el historial de cambios del curso, primero el más reciente.
+// Lo ve todo el staff del curso.
+func (s *courseService) ListRevisions(courseID int64, actor domain.Actor) ([]domain.CourseRevision, error) {
+	if err := s.authorizeRevisions(courseID, actor); err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.repo.GetCourseRevisions(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting course revisions from DB: %v", err)
+	}
+	if revisions == nil {
+		revisions = make([]domain.CourseRevision, 0)
+	}
+	return revisions, nil
+}
+
+// DiffRevisions compara dos revisiones del curso campo por campo
+func (s *courseService) DiffRevisions(courseID int64, from, to int, actor domain.Actor) (domain.RevisionDiff, error) {
+	if err := s.authorizeRevisions(courseID, actor); err != nil {
+		return domain.RevisionDiff{}, err
+	}
+
+	older, err := s.courseRevision(courseID, from)
+	if err != nil {
+		return domain.RevisionDiff{}, err
+	}
+	newer, err := s.courseRevision(courseID, to)
+	if err != nil {
+		return domain.RevisionDiff{}, err
+	}
+
+	return domain.RevisionDiff{
+		CourseID: courseID,
+		From:     from,
+		To:       to,
+		Changes:  diffRevisions(*older, *newer),
+	}, nil
+}
+
+// RestoreRevision vuelve los campos del curso a los de la revisión. La restauración queda
+// como una revisión nueva, así que el historial no se pierde.
+func (s *courseService) RestoreRevision(courseID int64, number int, actor domain.Actor) (domain.Course, error) {
+	if err := s.authorize(courseID, actor, domain.StaffOwner, domain.StaffCoInstructor); err != nil {
+		return domain.Course{}, err
+	}
+
+	before, err := s.repo.GetCourseById(courseID)
+	if err != nil {
+		return domain.Course{}, fmt.Errorf("%w: %d", domain.ErrCourseNotFound, courseID)
+	}
+	revision, err := s.courseRevision(courseID, number)
+	if err != nil {
+		return domain.Course{}, err
+	}
+
+	after := *before
+	after.Title = revision.Title
+	after.Description = revision.Description
+	after.Category = revision.Category
+	after.Instructor = revision.Instructor
+	after.Duration = revision.Duration
+	after.Requirement = revision.Requirement
+
+	if err := s.saveCourse(*before, after, actor, domain.AuditRevisionRestored, number); err != nil {
+		return domain.Course{}, err
+	}
+	return after, nil
+}
+
+func (s *courseService) authorizeRevisions(courseID int64, actor domain.Actor) error {
+	if err := s.courseExists(courseID); err != nil {
+		return err
+	}
+	return s.authorize(courseID, actor, domain.StaffOwner, domain.StaffCoInstructor, domain.StaffTeachingAssistant)
+}
+
+func (s *courseService) courseRevision(courseID int64, number int) (*domain.CourseRevision, error) {
+	revision, err := s.repo.GetCourseRevision(courseID, number)
+	if err != nil {
+		return nil, fmt.Errorf("error getting course revision from DB: %v", err)
+	}
+	if revision == nil {
+		return nil, fmt.Errorf("%w: %d", domain.ErrRevisionNotFound, number)
+	}
+	return revision, nil
+}
+
+// baselineRevision es cómo estaba el curso antes de su primer cambio. Solo se guarda para los
+// cursos creados antes del historial, que todavía no tienen revisiones.
+func baselineRevision(before domain.Course) domain.CourseRevision {
+	baseline := newRevision(before, 0, 0)
+	if !before.LastUpdate.IsZero() {
+		baseline.CreatedAt = before.LastUpdate
+	}
+	return baseline
+}
+
+func newRevision(course domain.Course, authorID int64, restoredFrom int) domain.CourseRevision {
+	return domain.CourseRevision{
+		CourseID:     int64(course.Id),
+		Title:        course.Title,
+		Description:  course.Description,
+		Category:     course.Category,
+		Instructor:   course.Instructor,
+		Duration:     course.Duration,
+		Requirement:  course.Requirement,
+		AuthorID:     authorID,
+		RestoredFrom: restoredFrom,
+		CreatedAt:    time.Now(),
+	}
+}
+
+// diffRevisions lista los campos editables que difieren entre las dos revisiones
+func diffRevisions(from, to domain.CourseRevision) []domain.FieldChange {
+	fields := []struct {
+		name     string
+		from, to interface{}
+	}{
+		{"title", from.Title, to.Title},
+		{"description", from.Description, to.Description},
+		{"category", from.Category, to.Category},
+		{"instructor", from.Instructor, to.Instructor},
+		{"duration", from.Duration, to.Duration},
+		{"requirement", from.Requirement, to.Requirement},
+	}
+
+	changes := make([]domain.FieldChange, 0)
+	for _, field := range fields {
+		if field.from != field.to {
+			changes = append(changes, domain.FieldChange{Field: field.name, From: field.from, To: field.to})
+		}
+	}
+	return changes
+}
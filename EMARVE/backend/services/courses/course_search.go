@@ -0,0 +1,178 @@
+package courses
+
+import (
+	"backend/domain"
+	"backend/services/search"
+	"cmp"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Cantidad de sugerencias por defecto y máxima del autocompletado
+const (
+	DefaultSuggestions = 10
+	MaxSuggestions     = 20
+)
+
+// RebuildSearchIndex carga en el índice todos los cursos de la base. Se usa al arrancar,
+// porque el índice vive en memoria.
+func (s *courseService) RebuildSearchIndex() error {
+	query := domain.CourseQuery{PageSize: MaxCoursesPageSize, AllStatuses: true}
+	for query.Page = 1; ; query.Page++ {
+		courses, total, err := s.repo.GetCourses(query)
+		if err != nil {
+			return fmt.Errorf("error getting courses from DB: %v", err)
+		}
+		for _, course := range courses {
+			s.index.Index(course)
+		}
+		if len(courses) == 0 || int64(query.Page*query.PageSize) >= total {
+			return nil
+		}
+	}
+}
+
+// SearchCourse busca el texto en el índice y aplica sobre los resultados los filtros, el orden
+// y la paginación del listado. Sin orden explícito los resultados van por relevancia.
+// Sin texto recorre todos los cursos indexados, para que igual haya facetas. Solo se
+// consideran los cursos que el actor puede ver.
+func (s *courseService) SearchCourse(query domain.CourseQuery, actor domain.Actor) (domain.SearchResponse, error) {
+	if query.Sort == domain.SortRelevance {
+		query.Sort = ""
+	}
+	query, err := normalizeCourseQuery(query)
+	if err != nil {
+		return domain.SearchResponse{}, err
+	}
+
+	visible, err := s.visibleTo(actor)
+	if err != nil {
+		return domain.SearchResponse{}, err
+	}
+
+	var candidates []domain.SearchHit
+	if query.Query == "" {
+		for _, course := range s.index.Courses() {
+			if visible(course) {
+				candidates = append(candidates, domain.SearchHit{Course: course})
+			}
+		}
+	} else {
+		for _, hit := range s.index.Search(query.Query) {
+			if visible(hit.Course) {
+				candidates = append(candidates, hit)
+			}
+		}
+	}
+
+	facets := buildFacets(candidates, query)
+
+	hits := make([]domain.SearchHit, 0)
+	for _, hit := range candidates {
+		if matchesFilters(hit.Course, query) {
+			hits = append(hits, hit)
+		}
+	}
+
+	if query.Sort != "" {
+		if err := s.sortHits(hits, query.Sort); err != nil {
+			return domain.SearchResponse{}, err
+		}
+	}
+
+	total := int64(len(hits))
+	from := min((query.Page-1)*query.PageSize, len(hits))
+	to := min(from+query.PageSize, len(hits))
+	hits = hits[from:to]
+
+	courses := make([]domain.Course, 0, len(hits))
+	for _, hit := range hits {
+		courses = append(courses, hit.Course)
+	}
+
+	page := coursePage(courses, total, query)
+	page.Facets = &facets
+	if query.Query == "" {
+		return page, nil
+	}
+
+	page.Highlights = make([]domain.CourseHighlight, 0, len(hits))
+	for _, hit := range hits {
+		page.Highlights = append(page.Highlights, domain.CourseHighlight{
+			CourseID: hit.Course.Id,
+			Score:    hit.Score,
+			Fields:   hit.Highlights,
+		})
+	}
+	if total == 0 {
+		page.DidYouMean = s.index.Correct(query.Query, visible)
+	}
+	return page, nil
+}
+
+// SuggestCourses completa lo que el usuario va escribiendo con títulos, categorías e instructores
+func (s *courseService) SuggestCourses(prefix string, limit int, actor domain.Actor) ([]domain.Suggestion, error) {
+	prefix = strings.TrimSpace(prefix)
+	if prefix == "" {
+		return nil, errors.New("prefix is required")
+	}
+
+	if limit < 1 {
+		limit = DefaultSuggestions
+	}
+	if limit > MaxSuggestions {
+		limit = MaxSuggestions
+	}
+
+	visible, err := s.visibleTo(actor)
+	if err != nil {
+		return nil, err
+	}
+	return s.index.Suggest(prefix, limit, visible), nil
+}
+
+// sortHits ordena los resultados por un campo de CourseSortFields. A igual valor se mantiene
+// el orden por relevancia.
+func (s *courseService) sortHits(hits []domain.SearchHit, sortField string) error {
+	descending := strings.HasPrefix(sortField, "-")
+	field := strings.TrimPrefix(sortField, "-")
+
+	var compare func(a, b domain.Course) int
+	switch field {
+	case "title":
+		compare = func(a, b domain.Course) int {
+			return strings.Compare(search.Fold(a.Title), search.Fold(b.Title))
+		}
+	case "creation_date":
+		compare = func(a, b domain.Course) int {
+			return a.CreationDate.Compare(b.CreationDate)
+		}
+	case "duration":
+		compare = func(a, b domain.Course) int {
+			return cmp.Compare(a.Duration, b.Duration)
+		}
+	case "popularity":
+		ids := make([]int64, 0, len(hits))
+		for _, hit := range hits {
+			ids = append(ids, int64(hit.Course.Id))
+		}
+		counts, err := s.repo.GetSubscriptionCounts(ids)
+		if err != nil {
+			return fmt.Errorf("error getting subscription counts from DB: %v", err)
+		}
+		compare = func(a, b domain.Course) int {
+			return cmp.Compare(counts[int64(a.Id)], counts[int64(b.Id)])
+		}
+	}
+
+	sort.SliceStable(hits, func(a, b int) bool {
+		result := compare(hits[a].Course, hits[b].Course)
+		if descending {
+			return result > 0
+		}
+		return result < 0
+	})
+	return nil
+}
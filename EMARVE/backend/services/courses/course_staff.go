@@ -0,0 +1,147 @@
+package courses
+
+import (
+	"backend/domain"
+	"backend/services/audit"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// authorize permite continuar a los admins y al staff del curso con alguno de los roles indicados
+func (s *courseService) authorize(courseID int64, actor domain.Actor, roles ...string) error {
+	if actor.Role == domain.RoleAdmin {
+		return nil
+	}
+
+	member, err := s.repo.GetCourseStaffMember(courseID, actor.UserID)
+	if err != nil {
+		return fmt.Errorf("error getting course staff from DB: %v", err)
+	}
+	if member == nil {
+		return domain.ErrCourseForbidden
+	}
+
+	for _, role := range roles {
+		if member.Role == role {
+			return nil
+		}
+	}
+	return domain.ErrCourseForbidden
+}
+
+func (s *courseService) ListStaff(courseID int64, actor domain.Actor) ([]domain.CourseStaff, error) {
+	if _, err := s.visibleCourse(courseID, actor); err != nil {
+		return nil, err
+	}
+
+	staff, err := s.repo.GetCourseStaff(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting course staff from DB: %v", err)
+	}
+	if staff == nil {
+		staff = make([]domain.CourseStaff, 0)
+	}
+	return staff, nil
+}
+
+// AddStaff agrega un usuario al staff del curso o le cambia el rol si ya era parte
+func (s *courseService) AddStaff(courseID int64, userID int64, role string, actor domain.Actor) error {
+	switch role {
+	case domain.StaffOwner, domain.StaffCoInstructor, domain.StaffTeachingAssistant:
+	default:
+		return fmt.Errorf("invalid staff role %q", role)
+	}
+
+	if err := s.authorize(courseID, actor, domain.StaffOwner); err != nil {
+		return err
+	}
+
+	if _, err := s.repo.GetCourseById(courseID); err != nil {
+		return fmt.Errorf("error getting course from DB: %v", err)
+	}
+
+	if _, err := s.repo.GetUserById(userID); err != nil {
+		return fmt.Errorf("error getting user from DB: %v", err)
+	}
+
+	previous, err := s.repo.GetCourseStaffMember(courseID, userID)
+	if err != nil {
+		return fmt.Errorf("error getting course staff from DB: %v", err)
+	}
+	if previous != nil && previous.Role == domain.StaffOwner && role != domain.StaffOwner {
+		if err := s.checkOtherOwner(courseID, userID); err != nil {
+			return err
+		}
+	}
+
+	staff := domain.CourseStaff{
+		CourseID:  courseID,
+		UserID:    userID,
+		Role:      role,
+		AddedBy:   actor.UserID,
+		CreatedAt: time.Now(),
+	}
+	if previous != nil {
+		staff.AddedBy = previous.AddedBy
+		staff.CreatedAt = previous.CreatedAt
+	}
+
+	if err := s.repo.SaveCourseStaff(staff); err != nil {
+		return fmt.Errorf("error saving course staff in DB: %v", err)
+	}
+
+	event := audit.NewEvent(actor, domain.AuditCourseStaffAdded, domain.AuditTargetCourse, courseID)
+	if previous != nil {
+		event.Before = audit.Snapshot(previous)
+	}
+	event.After = audit.Snapshot(staff)
+	s.auditLog.Record(event)
+
+	return nil
+}
+
+// RemoveStaff quita a un usuario del staff. El curso no puede quedarse sin owner.
+func (s *courseService) RemoveStaff(courseID int64, userID int64, actor domain.Actor) error {
+	if err := s.authorize(courseID, actor, domain.StaffOwner); err != nil {
+		return err
+	}
+
+	member, err := s.repo.GetCourseStaffMember(courseID, userID)
+	if err != nil {
+		return fmt.Errorf("error getting course staff from DB: %v", err)
+	}
+	if member == nil {
+		return fmt.Errorf("user %d is not staff of course %d", userID, courseID)
+	}
+
+	if member.Role == domain.StaffOwner {
+		if err := s.checkOtherOwner(courseID, userID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.RemoveCourseStaff(courseID, userID); err != nil {
+		return fmt.Errorf("error removing course staff in DB: %v", err)
+	}
+
+	event := audit.NewEvent(actor, domain.AuditCourseStaffRemoved, domain.AuditTargetCourse, courseID)
+	event.Before = audit.Snapshot(member)
+	s.auditLog.Record(event)
+
+	return nil
+}
+
+// checkOtherOwner verifica que el curso tenga otro owner además del usuario indicado
+func (s *courseService) checkOtherOwner(courseID int64, userID int64) error {
+	staff, err := s.repo.GetCourseStaff(courseID)
+	if err != nil {
+		return fmt.Errorf("error getting course staff from DB: %v", err)
+	}
+	for _, member := range staff {
+		if member.Role == domain.StaffOwner && member.UserID != userID {
+			return nil
+		}
+	}
+	return errors.New("a course must keep at least one owner")
+}
@@ -0,0 +1,112 @@
+package courses
+
+import (
+	"backend/domain"
+	"backend/services/audit"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ChangeCourseStatus mueve el curso por el ciclo de publicación. Enviar a revisión y retirar
+// de revisión lo hacen owners y co-instructores, publicar o rechazar solo los admins y
+// archivar el owner.
+func (s *courseService) ChangeCourseStatus(courseID int64, request domain.CourseStatusRequest, actor domain.Actor) (domain.Course, error) {
+	before, err := s.repo.GetCourseById(courseID)
+	if err != nil {
+		return domain.Course{}, fmt.Errorf("%w: %d", domain.ErrCourseNotFound, courseID)
+	}
+
+	// Se autoriza antes de validar la transición para no revelar el estado a quien no puede cambiarlo
+	switch request.Status {
+	case domain.CoursePublished:
+		if actor.Role != domain.RoleAdmin {
+			err = domain.ErrCourseForbidden
+		}
+	case domain.CourseArchived:
+		err = s.authorize(courseID, actor, domain.StaffOwner)
+	default:
+		err = s.authorize(courseID, actor, domain.StaffOwner, domain.StaffCoInstructor)
+	}
+	if err != nil {
+		return domain.Course{}, err
+	}
+
+	if !before.CanMoveTo(request.Status) {
+		return domain.Course{}, fmt.Errorf("%w: from %q to %q", domain.ErrInvalidStatusTransition, before.Status, request.Status)
+	}
+	if request.PublishAt != nil {
+		if request.Status != domain.CoursePublished {
+			return domain.Course{}, errors.New("publish_at can only be set when publishing")
+		}
+		if !request.PublishAt.After(time.Now()) {
+			return domain.Course{}, errors.New("publish_at must be in the future")
+		}
+	}
+
+	if err := s.repo.UpdateCourseStatus(courseID, request.Status, request.PublishAt); err != nil {
+		return domain.Course{}, fmt.Errorf("error updating course status in DB: %v", err)
+	}
+
+	after := *before
+	after.Status = request.Status
+	after.PublishAt = request.PublishAt
+	s.index.Index(after)
+
+	event := audit.NewEvent(actor, domain.AuditCourseStatusChanged, domain.AuditTargetCourse, courseID)
+	event.Details = fmt.Sprintf("%s -> %s", before.Status, after.Status)
+	event.Before = audit.Snapshot(before)
+	event.After = audit.Snapshot(after)
+	s.auditLog.Record(event)
+
+	return after, nil
+}
+
+// visibleTo devuelve qué cursos puede ver el actor en listados y búsquedas: los admins todos,
+// el resto los publicados y aquellos de los que es staff
+func (s *courseService) visibleTo(actor domain.Actor) (func(domain.Course) bool, error) {
+	if actor.Role == domain.RoleAdmin {
+		return func(domain.Course) bool { return true }, nil
+	}
+
+	staff := make(map[int64]bool)
+	if actor.UserID != 0 {
+		ids, err := s.repo.GetStaffCourseIDs(actor.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("error getting course staff from DB: %v", err)
+		}
+		for _, id := range ids {
+			staff[id] = true
+		}
+	}
+
+	now := time.Now()
+	return func(course domain.Course) bool {
+		return course.IsVisible(now) || staff[int64(course.Id)]
+	}, nil
+}
+
+// visibleCourse devuelve el curso si el actor lo puede ver. Si no, responde como si no
+// existiera, igual que los listados.
+func (s *courseService) visibleCourse(courseID int64, actor domain.Actor) (*domain.Course, error) {
+	course, err := s.repo.GetCourseById(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting course from DB: %v", err)
+	}
+
+	visible, err := s.visibleTo(actor)
+	if err != nil {
+		return nil, err
+	}
+	if !visible(*course) {
+		return nil, fmt.Errorf("%w: %d", domain.ErrCourseNotFound, courseID)
+	}
+	return course, nil
+}
+
+// restrictQuery limita el listado de la base a los cursos que puede ver el actor
+func restrictQuery(query domain.CourseQuery, actor domain.Actor) domain.CourseQuery {
+	query.AllStatuses = actor.Role == domain.RoleAdmin
+	query.StaffUserID = actor.UserID
+	return query
+}
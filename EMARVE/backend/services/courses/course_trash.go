@@ -0,0 +1,117 @@
+package courses
+
+import (
+	"backend/domain"
+	"backend/services/audit"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Retención por defecto de la papelera y cada cuánto se purga
+const (
+	DefaultTrashRetention = 30 * 24 * time.Hour
+	TrashPurgeInterval    = time.Hour
+)
+
+// TrashRetentionFromEnv lee COURSE_TRASH_RETENTION (por ejemplo "720h")
+func TrashRetentionFromEnv() time.Duration {
+	if retention, err := time.ParseDuration(os.Getenv("COURSE_TRASH_RETENTION")); err == nil && retention > 0 {
+		return retention
+	}
+	return DefaultTrashRetention
+}
+
+// ListTrash devuelve los cursos borrados que todavía se pueden restaurar
+func (s *courseService) ListTrash() ([]domain.Course, error) {
+	courses, err := s.repo.GetTrashedCourses()
+	if err != nil {
+		return nil, fmt.Errorf("error getting trashed courses from DB: %v", err)
+	}
+	if courses == nil {
+		courses = make([]domain.Course, 0)
+	}
+	return courses, nil
+}
+
+// RestoreCourse saca el curso de la papelera con sus suscripciones, comentarios y archivos
+func (s *courseService) RestoreCourse(courseID int64, actor domain.Actor) (domain.Course, error) {
+	if err := s.repo.RestoreCourse(courseID); err != nil {
+		return domain.Course{}, fmt.Errorf("%w: %d is not in the trash", domain.ErrCourseNotFound, courseID)
+	}
+
+	course, err := s.repo.GetCourseById(courseID)
+	if err != nil {
+		return domain.Course{}, fmt.Errorf("error getting course from DB: %v", err)
+	}
+	s.index.Index(*course)
+
+	event := audit.NewEvent(actor, domain.AuditCourseRestored, domain.AuditTargetCourse, courseID)
+	event.After = audit.Snapshot(course)
+	s.auditLog.Record(event)
+
+	return *course, nil
+}
+
+// PurgeTrash borra definitivamente los cursos que llevan en la papelera más que retention,
+// junto con los archivos en disco que ya no usa otro curso. Devuelve cuántos cursos se borraron.
+func (s *courseService) PurgeTrash(retention time.Duration) (int, error) {
+	courses, err := s.repo.GetTrashedCourses()
+	if err != nil {
+		return 0, fmt.Errorf("error getting trashed courses from DB: %v", err)
+	}
+
+	cutoff := time.Now().Add(-retention)
+	purged := 0
+	for _, course := range courses {
+		if course.DeletedAt.Time.After(cutoff) {
+			continue
+		}
+
+		files, err := s.repo.PurgeCourse(int64(course.Id))
+		if err != nil {
+			return purged, fmt.Errorf("error purging course %d in DB: %v", course.Id, err)
+		}
+		purged++
+
+		// El curso ya no existe, así que un archivo que no se pudo borrar solo se informa en el log
+		for _, file := range files {
+			if err := os.Remove(s.uploadPath(file)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				log.Warnf("error removing file %d of purged course %d: %v", file.Id, course.Id, err)
+			}
+		}
+
+		// Lo ejecuta el sistema, por eso el actor queda vacío
+		event := audit.NewEvent(domain.Actor{}, domain.AuditCoursePurged, domain.AuditTargetCourse, int64(course.Id))
+		event.Details = fmt.Sprintf("%d files removed", len(files))
+		event.Before = audit.Snapshot(course)
+		s.auditLog.Record(event)
+	}
+	return purged, nil
+}
+
+// StartTrashRetention purga la papelera al arrancar y después cada interval, en segundo plano,
+// hasta que se cancela ctx
+func (s *courseService) StartTrashRetention(ctx context.Context, retention, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if purged, err := s.PurgeTrash(retention); err != nil {
+				log.Errorf("error purging course trash: %v", err)
+			} else if purged > 0 {
+				log.Infof("purged %d courses from the trash", purged)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
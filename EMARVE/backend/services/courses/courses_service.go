@@ -3,6 +3,8 @@ package courses
 import (
 	"backend/domain"
 	"backend/interfaces"
+	"backend/services/audit"
+	"backend/services/search"
 	"time"
 
 	"errors"
@@ -11,47 +13,55 @@ import (
 )
 
 type courseService struct {
-	repo interfaces.CourseRepositoryInterface
+	repo       interfaces.CourseRepositoryInterface
+	auditLog   interfaces.AuditRecorderInterface
+	index      interfaces.CourseSearchIndexInterface
+	uploadsDir string
 }
 
-func NewCourseService(repo interfaces.CourseRepositoryInterface) *courseService {
-	return &courseService{repo: repo}
-}
-
-func (s *courseService) SearchCourse(query string) ([]domain.Course, error) {
-
-	trimmed := strings.TrimSpace(query)
+// Option configura dependencias opcionales del servicio de cursos
+type Option func(*courseService)
 
-	courses, err := s.repo.GetCoursewithQuery(trimmed)
-
-	if err != nil {
-		return nil, fmt.Errorf("error getting courses from DB: %s", err)
+// WithSearchIndex reemplaza el índice de búsqueda en memoria que se crea por defecto
+func WithSearchIndex(index interfaces.CourseSearchIndexInterface) Option {
+	return func(s *courseService) {
+		s.index = index
 	}
+}
 
-	results := make([]domain.Course, 0)
-
-	for _, course := range courses {
-		results = append(results, domain.Course{
-			Id:           course.Id,
-			Title:        course.Title,
-			Description:  course.Description,
-			Category:     course.Category,
-			Instructor:   course.Instructor,
-			Duration:     course.Duration,
-			Requirement:  course.Requirement,
-			CreationDate: course.CreationDate,
-			LastUpdate:   course.LastUpdate,
-		})
+// WithAuditRecorder registra las altas, cambios, bajas y suscripciones en el log de auditoría
+func WithAuditRecorder(recorder interfaces.AuditRecorderInterface) Option {
+	return func(s *courseService) {
+		s.auditLog = recorder
 	}
+}
 
-	return results, nil
+// WithUploadsDir cambia el directorio de los archivos subidos, que se borran al purgar la papelera
+func WithUploadsDir(dir string) Option {
+	return func(s *courseService) {
+		s.uploadsDir = dir
+	}
 }
 
-func (s *courseService) GetCourse(ID int64) (domain.Course, error) {
-	course, err := s.repo.GetCourseById(ID)
+func NewCourseService(repo interfaces.CourseRepositoryInterface, options ...Option) *courseService {
+	s := &courseService{
+		repo:       repo,
+		auditLog:   audit.NewNopRecorder(),
+		index:      search.NewCourseIndex(),
+		uploadsDir: "uploads",
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
 
+// GetCourse devuelve el curso si el actor lo puede ver, con el mismo criterio que los listados.
+// Un curso que no puede ver se informa como inexistente.
+func (s *courseService) GetCourse(ID int64, actor domain.Actor) (domain.Course, error) {
+	course, err := s.visibleCourse(ID, actor)
 	if err != nil {
-		return domain.Course{}, fmt.Errorf("error getting course from DB: %v", err)
+		return domain.Course{}, err
 	}
 
 	return domain.Course{
@@ -64,39 +74,37 @@ func (s *courseService) GetCourse(ID int64) (domain.Course, error) {
 		Requirement:  course.Requirement,
 		CreationDate: course.CreationDate,
 		LastUpdate:   course.LastUpdate,
+		Status:       course.Status,
+		PublishAt:    course.PublishAt,
 	}, nil
 }
 
-func (s *courseService) GetAllCourses() ([]domain.Course, error) {
-	courses, err := s.repo.GetCourses()
-
+// GetAllCourses devuelve una página del listado de los cursos que puede ver el actor
+func (s *courseService) GetAllCourses(query domain.CourseQuery, actor domain.Actor) (domain.SearchResponse, error) {
+	query.Query = ""
+	query, err := normalizeCourseQuery(query)
 	if err != nil {
-		return nil, fmt.Errorf("error getting courses from DB: %s", err)
+		return domain.SearchResponse{}, err
 	}
+	query = restrictQuery(query, actor)
 
-	results := make([]domain.Course, 0)
-
-	for _, course := range courses {
-		results = append(results, domain.Course{
-			Id:           course.Id,
-			Title:        course.Title,
-			Description:  course.Description,
-			Category:     course.Category,
-			Instructor:   course.Instructor,
-			Duration:     course.Duration,
-			Requirement:  course.Requirement,
-			CreationDate: course.CreationDate,
-			LastUpdate:   course.LastUpdate,
-		})
+	courses, total, err := s.repo.GetCourses(query)
+	if err != nil {
+		return domain.SearchResponse{}, fmt.Errorf("error getting courses from DB: %s", err)
 	}
 
-	return results, nil
+	return coursePage(courses, total, query), nil
 }
 
-func (s *courseService) GetCourseImages(courseID int64) ([]domain.File, error) {
+// GetCourseImages lista los archivos del curso. Son parte del contenido, así que los ven los
+// mismos que pueden ver las lecciones.
+func (s *courseService) GetCourseImages(courseID int64, actor domain.Actor) ([]domain.File, error) {
 	if courseID <= 0 {
 		return nil, errors.New("invalid course ID")
 	}
+	if err := s.authorizeContent(courseID, actor); err != nil {
+		return nil, err
+	}
 
 	images, err := s.repo.GetCourseImages(courseID)
 	if err != nil {
@@ -106,60 +114,85 @@ func (s *courseService) GetCourseImages(courseID int64) ([]domain.File, error) {
 	return images, nil
 }
 
-func (s *courseService) GetCoursesByInstructor(instructor string) ([]domain.Course, error) {
+func (s *courseService) GetCoursesByInstructor(instructor string, actor domain.Actor) ([]domain.Course, error) {
 	if strings.TrimSpace(instructor) == "" {
 		return nil, errors.New("instructor is required")
 	}
 
-	results, err := s.repo.GetCoursesByInstructor(instructor)
+	courses, err := s.repo.GetCoursesByInstructor(instructor)
 	if err != nil {
 		return nil, fmt.Errorf("error getting courses for instructor %s from DB: %v", instructor, err)
 	}
 
+	visible, err := s.visibleTo(actor)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]domain.Course, 0, len(courses))
+	for _, course := range courses {
+		if visible(course) {
+			results = append(results, course)
+		}
+	}
+
 	return results, nil
 }
 
-func (s *courseService) Subscription(userID int64, courseID int64) error {
+func (s *courseService) Subscription(userID int64, courseID int64, actor domain.Actor) error {
 
 	if _, err := s.repo.GetUserById(userID); err != nil {
 		return fmt.Errorf("error getting user from DB: %v", err)
 	}
 
-	if _, err := s.repo.GetCourseById(courseID); err != nil {
+	course, err := s.repo.GetCourseById(courseID)
+	if err != nil {
 		return fmt.Errorf("error getting course from DB: %v", err)
 	}
 
+	// Las suscripciones existentes a un curso archivado se mantienen, pero no se aceptan nuevas
+	if course.Status == domain.CourseArchived {
+		return domain.ErrCourseArchived
+	}
+	if !course.IsVisible(time.Now()) {
+		return domain.ErrCourseNotPublished
+	}
+
 	if err := s.repo.InsertSubscription(userID, courseID); err != nil {
 		return fmt.Errorf("error inserting subscription into DB: %v", err)
 	}
 
+	event := audit.NewEvent(actor, domain.AuditSubscriptionCreated, domain.AuditTargetCourse, courseID)
+	event.Details = fmt.Sprintf("user %d", userID)
+	s.auditLog.Record(event)
+
 	return nil
 }
 
-func (s *courseService) CreateCourse(title string, description string, category string, instructor string, duration int64, requirement string) error {
+// CreateCourse crea el curso en borrador con quien lo crea como owner
+func (s *courseService) CreateCourse(title string, description string, category string, instructor string, duration int64, requirement string, actor domain.Actor) (domain.Course, error) {
 
 	if strings.TrimSpace(title) == "" {
-		return errors.New("title is required")
+		return domain.Course{}, errors.New("title is required")
 	}
 
 	if strings.TrimSpace(description) == "" {
-		return errors.New("description is required")
+		return domain.Course{}, errors.New("description is required")
 	}
 
 	if strings.TrimSpace(category) == "" {
-		return errors.New("category is required")
+		return domain.Course{}, errors.New("category is required")
 	}
 
 	if strings.TrimSpace(instructor) == "" {
-		return errors.New("instructor is required")
+		return domain.Course{}, errors.New("instructor is required")
 	}
 
 	if duration == 0 {
-		return errors.New("duration is required")
+		return domain.Course{}, errors.New("duration is required")
 	}
 
 	if strings.TrimSpace(requirement) == "" {
-		return errors.New("requirement is required")
+		return domain.Course{}, errors.New("requirement is required")
 	}
 
 	NewCourse := domain.Course{
@@ -171,17 +204,24 @@ func (s *courseService) CreateCourse(title string, description string, category
 		Requirement:  requirement,
 		CreationDate: time.Now(),
 		LastUpdate:   time.Now(),
+		Status:       domain.CourseDraft,
 	}
 
-	err := s.repo.CreateCourse(NewCourse)
+	created, err := s.repo.CreateCourse(NewCourse, actor.UserID, newRevision(NewCourse, actor.UserID, 0))
 	if err != nil {
-		return fmt.Errorf("error creating course from DB: %v", err)
+		return domain.Course{}, fmt.Errorf("error creating course from DB: %v", err)
 	}
 
-	return nil
+	s.index.Index(created)
+
+	event := audit.NewEvent(actor, domain.AuditCourseCreated, domain.AuditTargetCourse, int64(created.Id))
+	event.After = audit.Snapshot(created)
+	s.auditLog.Record(event)
+
+	return created, nil
 }
 
-func (s *courseService) UpdateCourse(courseID int64, title string, description string, category string, instructor string, duration int64, requirement string) error {
+func (s *courseService) UpdateCourse(courseID int64, title string, description string, category string, instructor string, duration int64, requirement string, actor domain.Actor) error {
 
 	if strings.TrimSpace(title) == "" {
 		return errors.New("title is required")
@@ -207,36 +247,141 @@ func (s *courseService) UpdateCourse(courseID int64, title string, description s
 		return errors.New("requirement is required")
 	}
 
-	courseUpdate := domain.Course{
-		Title:       title,
-		Description: description,
-		Category:    category,
-		Instructor:  instructor,
-		Duration:    duration,
-		Requirement: requirement,
+	if err := s.authorize(courseID, actor, domain.StaffOwner, domain.StaffCoInstructor); err != nil {
+		return err
+	}
+
+	before, err := s.repo.GetCourseById(courseID)
+	if err != nil {
+		return fmt.Errorf("error getting course from DB: %v", err)
+	}
+
+	after := *before
+	after.Title = title
+	after.Description = description
+	after.Category = category
+	after.Instructor = instructor
+	after.Duration = duration
+	after.Requirement = requirement
+
+	return s.saveCourse(*before, after, actor, domain.AuditCourseUpdated, 0)
+}
+
+// PatchCourse aplica un JSON Merge Patch a los campos editables del curso. Los campos que no
+// vienen quedan como están; los que vienen se validan igual que al crear, así que null o vacío
+// no se aceptan. Si el patch no cambia nada no se guarda una revisión nueva.
+func (s *courseService) PatchCourse(courseID int64, patch domain.CourseRequest, actor domain.Actor) (domain.Course, error) {
+	if patch.IsEmpty() {
+		return domain.Course{}, errors.New("nothing to update")
+	}
+	if err := validateCoursePatch(patch); err != nil {
+		return domain.Course{}, err
 	}
 
-	err := s.repo.UpdateCourse(courseID, courseUpdate)
+	if err := s.authorize(courseID, actor, domain.StaffOwner, domain.StaffCoInstructor); err != nil {
+		return domain.Course{}, err
+	}
+
+	before, err := s.repo.GetCourseById(courseID)
 	if err != nil {
+		return domain.Course{}, fmt.Errorf("%w: %d", domain.ErrCourseNotFound, courseID)
+	}
+
+	after := patch.Apply(*before)
+	if len(diffRevisions(newRevision(*before, 0, 0), newRevision(after, 0, 0))) == 0 {
+		return after, nil
+	}
+
+	if err := s.saveCourse(*before, after, actor, domain.AuditCourseUpdated, 0); err != nil {
+		return domain.Course{}, err
+	}
+	return after, nil
+}
+
+// validateCoursePatch valida solo los campos que trae el patch
+func validateCoursePatch(patch domain.CourseRequest) error {
+	texts := []struct {
+		name  string
+		field domain.Optional[string]
+	}{
+		{"title", patch.Title},
+		{"description", patch.Description},
+		{"category", patch.Category},
+		{"instructor", patch.Instructor},
+		{"requirement", patch.Requirement},
+	}
+	for _, text := range texts {
+		if text.field.Set && (text.field.Null || strings.TrimSpace(text.field.Value) == "") {
+			return fmt.Errorf("%s cannot be empty", text.name)
+		}
+	}
+
+	if patch.Duration.Set && (patch.Duration.Null || patch.Duration.Value <= 0) {
+		return errors.New("duration must be greater than zero")
+	}
+	return nil
+}
+
+// saveCourse guarda los campos editables del curso junto con su revisión, lo reindexa y
+// registra el evento de auditoría. restoredFrom es la revisión restaurada, o 0 si es un cambio normal.
+func (s *courseService) saveCourse(before, after domain.Course, actor domain.Actor, action string, restoredFrom int) error {
+	courseID := int64(after.Id)
+	courseUpdate := domain.Course{
+		Title:       after.Title,
+		Description: after.Description,
+		Category:    after.Category,
+		Instructor:  after.Instructor,
+		Duration:    after.Duration,
+		Requirement: after.Requirement,
+		LastUpdate:  time.Now(),
+	}
+	after.LastUpdate = courseUpdate.LastUpdate
+
+	revision := newRevision(after, actor.UserID, restoredFrom)
+	if err := s.repo.UpdateCourse(courseID, courseUpdate, baselineRevision(before), revision); err != nil {
 		return fmt.Errorf("error updating course from DB: %v", err)
 	}
+	s.index.Index(after)
+
+	event := audit.NewEvent(actor, action, domain.AuditTargetCourse, courseID)
+	event.Before = audit.Snapshot(before)
+	event.After = audit.Snapshot(after)
+	s.auditLog.Record(event)
+
 	return nil
 }
 
-func (s *courseService) DeleteCourse(courseID int64) error {
+// DeleteCourse manda el curso a la papelera, de donde un admin lo puede restaurar hasta que
+// vence la retención. Solo pueden hacerlo sus owners y los admins.
+func (s *courseService) DeleteCourse(courseID int64, actor domain.Actor) error {
 
-	if err := s.repo.DeleteCourseById(courseID); err != nil {
-		return fmt.Errorf("error deleting course in DB: %v", err)
+	if err := s.authorize(courseID, actor, domain.StaffOwner); err != nil {
+		return err
+	}
+
+	before, err := s.repo.GetCourseById(courseID)
+	if err != nil {
+		return fmt.Errorf("error getting course from DB: %v", err)
 	}
 
-	if err := s.repo.DeleteSubscriptionById(courseID); err != nil {
-		return fmt.Errorf("error deleting subscriptcion in DB: %v", err)
+	if err := s.repo.TrashCourse(courseID); err != nil {
+		return fmt.Errorf("error deleting course in DB: %v", err)
 	}
+	s.index.Remove(courseID)
+
+	event := audit.NewEvent(actor, domain.AuditCourseDeleted, domain.AuditTargetCourse, courseID)
+	event.Before = audit.Snapshot(before)
+	s.auditLog.Record(event)
 
 	return nil
 }
 
-func (s *courseService) CommentList(CourseID int64) ([]domain.CommentResponse, error) {
+// CommentList devuelve los comentarios de un curso que el actor puede ver
+func (s *courseService) CommentList(CourseID int64, actor domain.Actor) ([]domain.CommentResponse, error) {
+	if _, err := s.visibleCourse(CourseID, actor); err != nil {
+		return nil, err
+	}
+
 	commentIDs, err := s.repo.GetCommentsByCourseId(CourseID)
 	if err != nil {
 		return nil, fmt.Errorf("error getting comments IDs for course ID %d: %v", CourseID, err)
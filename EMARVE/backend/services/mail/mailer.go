@@ -0,0 +1,129 @@
+package mail
+
+import (
+	"backend/domain"
+	"backend/interfaces"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// outboxMailer guarda cada email en la tabla outbox. Es el mailer por defecto: no necesita
+// un servidor de correo y permite revisar los mensajes enviados sin salir de la base de datos.
+type outboxMailer struct {
+	repo interfaces.OutboxRepositoryInterface
+}
+
+func NewOutboxMailer(repo interfaces.OutboxRepositoryInterface) interfaces.MailerInterface {
+	return &outboxMailer{repo: repo}
+}
+
+func (m *outboxMailer) Send(email domain.Email) error {
+	if err := validate(email); err != nil {
+		return err
+	}
+
+	err := m.repo.SaveOutboxEmail(domain.OutboxEmail{
+		To:        email.To,
+		Subject:   email.Subject,
+		Body:      email.Body,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("error saving email in outbox: %v", err)
+	}
+	return nil
+}
+
+// SMTPConfig es la configuración de un servidor SMTP, por ejemplo MailHog en desarrollo
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+type smtpMailer struct {
+	config SMTPConfig
+}
+
+func NewSMTPMailer(config SMTPConfig) interfaces.MailerInterface {
+	return &smtpMailer{config: config}
+}
+
+func (m *smtpMailer) Send(email domain.Email) error {
+	if err := validate(email); err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.config.Username != "" {
+		auth = smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+	}
+
+	message := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s",
+		m.config.From, email.To, email.Subject, email.Body)
+
+	addr := net.JoinHostPort(m.config.Host, m.config.Port)
+	if err := smtp.SendMail(addr, auth, m.config.From, []string{email.To}, []byte(message)); err != nil {
+		return fmt.Errorf("error sending email via SMTP: %v", err)
+	}
+	return nil
+}
+
+// logMailer solo registra que se envió un email, sin su contenido. Se usa cuando
+// el servicio se crea sin mailer (por ejemplo en tests).
+type logMailer struct{}
+
+func NewLogMailer() interfaces.MailerInterface {
+	return &logMailer{}
+}
+
+func (m *logMailer) Send(email domain.Email) error {
+	if err := validate(email); err != nil {
+		return err
+	}
+	log.Infof("email %q to %s discarded: no mailer configured", email.Subject, email.To)
+	return nil
+}
+
+// NewMailerFromEnv usa SMTP si SMTP_HOST está definido y, si no, la tabla outbox
+func NewMailerFromEnv(repo interfaces.OutboxRepositoryInterface) interfaces.MailerInterface {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return NewOutboxMailer(repo)
+	}
+
+	return NewSMTPMailer(SMTPConfig{
+		Host:     host,
+		Port:     getEnv("SMTP_PORT", "1025"),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     getEnv("MAIL_FROM", "no-reply@emarve.local"),
+	})
+}
+
+func validate(email domain.Email) error {
+	if strings.TrimSpace(email.To) == "" {
+		return errors.New("email recipient is required")
+	}
+	// Evita inyección de headers a través del destinatario o el asunto
+	if strings.ContainsAny(email.To+email.Subject, "\r\n") {
+		return errors.New("invalid email header")
+	}
+	return nil
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
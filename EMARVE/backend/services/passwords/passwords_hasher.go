@@ -0,0 +1,249 @@
+package passwords
+
+import (
+	"backend/interfaces"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmMD5      = "md5"
+)
+
+// Config define el algoritmo y los parámetros de costo usados para los hashes nuevos
+type Config struct {
+	Algorithm     string
+	BcryptCost    int
+	Argon2Memory  uint32 // en KiB
+	Argon2Time    uint32
+	Argon2Threads uint8
+	Argon2SaltLen uint32
+	Argon2KeyLen  uint32
+}
+
+// DefaultConfig devuelve la configuración recomendada (bcrypt con costo 12)
+func DefaultConfig() Config {
+	return Config{
+		Algorithm:     AlgorithmBcrypt,
+		BcryptCost:    12,
+		Argon2Memory:  64 * 1024,
+		Argon2Time:    3,
+		Argon2Threads: 2,
+		Argon2SaltLen: 16,
+		Argon2KeyLen:  32,
+	}
+}
+
+// ConfigFromEnv lee la configuración desde variables de entorno, usando DefaultConfig para las que falten
+func ConfigFromEnv() Config {
+	config := DefaultConfig()
+	config.Algorithm = strings.ToLower(getEnv("PASSWORD_HASH_ALGORITHM", config.Algorithm))
+	config.BcryptCost = int(getEnvUint("PASSWORD_BCRYPT_COST", uint64(config.BcryptCost)))
+	config.Argon2Memory = uint32(getEnvUint("PASSWORD_ARGON2_MEMORY", uint64(config.Argon2Memory)))
+	config.Argon2Time = uint32(getEnvUint("PASSWORD_ARGON2_TIME", uint64(config.Argon2Time)))
+	config.Argon2Threads = uint8(getEnvUint("PASSWORD_ARGON2_THREADS", uint64(config.Argon2Threads)))
+	return config
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvUint(key string, defaultValue uint64) uint64 {
+	value, err := strconv.ParseUint(os.Getenv(key), 10, 64)
+	if err != nil || value == 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// hasher implementa PasswordHasherInterface. Genera hashes con el algoritmo configurado
+// y verifica cualquiera de los formatos soportados (bcrypt, argon2id y el MD5 heredado).
+type hasher struct {
+	config Config
+}
+
+// NewHasher crea un hasher con la configuración dada
+func NewHasher(config Config) (interfaces.PasswordHasherInterface, error) {
+	switch config.Algorithm {
+	case AlgorithmBcrypt:
+		if config.BcryptCost < bcrypt.MinCost || config.BcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("invalid bcrypt cost %d", config.BcryptCost)
+		}
+	case AlgorithmArgon2id:
+		if config.Argon2Memory == 0 || config.Argon2Time == 0 || config.Argon2Threads == 0 {
+			return nil, errors.New("invalid argon2id parameters")
+		}
+		if config.Argon2SaltLen == 0 || config.Argon2KeyLen == 0 {
+			return nil, errors.New("invalid argon2id salt or key length")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported password hash algorithm: %s", config.Algorithm)
+	}
+
+	return &hasher{config: config}, nil
+}
+
+// NewDefaultHasher crea un hasher con DefaultConfig
+func NewDefaultHasher() interfaces.PasswordHasherInterface {
+	return &hasher{config: DefaultConfig()}
+}
+
+func (h *hasher) Hash(password string) (string, error) {
+	if h.config.Algorithm == AlgorithmArgon2id {
+		return h.hashArgon2id(password)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.config.BcryptCost)
+	if err != nil {
+		return "", fmt.Errorf("error hashing password: %v", err)
+	}
+	return string(hash), nil
+}
+
+func (h *hasher) Verify(password string, encodedHash string) (bool, error) {
+	switch Algorithm(encodedHash) {
+	case AlgorithmBcrypt:
+		err := bcrypt.CompareHashAndPassword([]byte(encodedHash), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("error verifying bcrypt hash: %v", err)
+		}
+		return true, nil
+	case AlgorithmArgon2id:
+		params, salt, key, err := decodeArgon2id(encodedHash)
+		if err != nil {
+			return false, err
+		}
+		candidate := argon2.IDKey([]byte(password), salt, params.time, params.memory, params.threads, uint32(len(key)))
+		return subtle.ConstantTimeCompare(candidate, key) == 1, nil
+	case AlgorithmMD5:
+		sum := md5.Sum([]byte(password))
+		candidate := hex.EncodeToString(sum[:])
+		return subtle.ConstantTimeCompare([]byte(candidate), []byte(strings.ToLower(encodedHash))) == 1, nil
+	default:
+		return false, errors.New("unknown password hash format")
+	}
+}
+
+// NeedsRehash indica si el hash fue generado con otro algoritmo o con parámetros distintos a los configurados
+func (h *hasher) NeedsRehash(encodedHash string) bool {
+	algorithm := Algorithm(encodedHash)
+	if algorithm != h.config.Algorithm {
+		return true
+	}
+
+	switch algorithm {
+	case AlgorithmBcrypt:
+		cost, err := bcrypt.Cost([]byte(encodedHash))
+		return err != nil || cost != h.config.BcryptCost
+	case AlgorithmArgon2id:
+		params, salt, key, err := decodeArgon2id(encodedHash)
+		if err != nil {
+			return true
+		}
+		return params.memory != h.config.Argon2Memory ||
+			params.time != h.config.Argon2Time ||
+			params.threads != h.config.Argon2Threads ||
+			uint32(len(salt)) != h.config.Argon2SaltLen ||
+			uint32(len(key)) != h.config.Argon2KeyLen
+	}
+	return true
+}
+
+// Algorithm identifica el algoritmo con el que se generó un hash almacenado
+func Algorithm(encodedHash string) string {
+	switch {
+	case strings.HasPrefix(encodedHash, "$2a$"), strings.HasPrefix(encodedHash, "$2b$"), strings.HasPrefix(encodedHash, "$2y$"):
+		return AlgorithmBcrypt
+	case strings.HasPrefix(encodedHash, "$argon2id$"):
+		return AlgorithmArgon2id
+	case isLegacyMD5(encodedHash):
+		return AlgorithmMD5
+	}
+	return ""
+}
+
+func isLegacyMD5(encodedHash string) bool {
+	if len(encodedHash) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(encodedHash)
+	return err == nil
+}
+
+type argon2Params struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+}
+
+// hashArgon2id genera un hash en formato PHC: $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>
+func (h *hasher) hashArgon2id(password string) (string, error) {
+	salt := make([]byte, h.config.Argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("error generating salt: %v", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.config.Argon2Time, h.config.Argon2Memory, h.config.Argon2Threads, h.config.Argon2KeyLen)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		h.config.Argon2Memory,
+		h.config.Argon2Time,
+		h.config.Argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func decodeArgon2id(encodedHash string) (argon2Params, []byte, []byte, error) {
+	var params argon2Params
+
+	parts := strings.Split(encodedHash, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, errors.New("invalid argon2id hash format")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2id version: %v", err)
+	}
+	if version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2id version %d", version)
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.time, &params.threads); err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2id parameters: %v", err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2id salt: %v", err)
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2id key: %v", err)
+	}
+
+	return params, salt, key, nil
+}
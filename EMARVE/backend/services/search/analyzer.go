@@ -0,0 +1,136 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// token es una palabra del texto original con su posición en bytes, para poder resaltarla.
+// word es la palabra plegada, que se usa para corregir errores de tipeo, y term su raíz.
+type token struct {
+	word  string
+	term  string
+	start int
+	end   int
+}
+
+// foldings quita tildes y diéresis. La ñ también se pliega porque muchos usuarios la escriben como n.
+var foldings = map[rune]rune{
+	'á': 'a', 'à': 'a', 'â': 'a', 'ä': 'a',
+	'é': 'e', 'è': 'e', 'ê': 'e', 'ë': 'e',
+	'í': 'i', 'ì': 'i', 'î': 'i', 'ï': 'i',
+	'ó': 'o', 'ò': 'o', 'ô': 'o', 'ö': 'o',
+	'ú': 'u', 'ù': 'u', 'û': 'u', 'ü': 'u',
+	'ñ': 'n', 'ç': 'c',
+}
+
+// stopwords son palabras demasiado frecuentes para aportar a la relevancia, ya plegadas
+var stopwords = toSet(
+	"a", "al", "algo", "ante", "como", "con", "de", "del", "desde", "e", "el", "en", "entre", "es",
+	"esta", "este", "esto", "hacia", "hasta", "la", "las", "le", "les", "lo", "los", "mas", "mi",
+	"muy", "ni", "no", "o", "para", "pero", "por", "que", "se", "sin", "sobre", "su", "sus", "te",
+	"tu", "u", "un", "una", "unas", "uno", "unos", "y", "ya",
+	"and", "for", "in", "of", "on", "the", "to", "with",
+)
+
+// derivationalSuffixes se prueban de mayor a menor para quedarse con la raíz más corta posible
+var derivationalSuffixes = []string{
+	"amientos", "imientos", "amiento", "imiento", "aciones", "iciones", "uciones",
+	"idades", "acion", "icion", "ucion", "mente", "ables", "ibles", "istas", "ismos",
+	"idad", "able", "ible", "ista", "ismo",
+}
+
+// minStemLength evita que el stemmer deje raíces tan cortas que mezclen palabras distintas
+const minStemLength = 3
+
+// Analyze convierte un texto en los términos que guarda el índice: palabras en minúscula, sin
+// tildes, sin stopwords y reducidas a su raíz
+func Analyze(text string) []string {
+	tokens := tokenize(text)
+	terms := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		terms = append(terms, t.term)
+	}
+	return terms
+}
+
+// tokenize separa el texto en palabras (letras y dígitos) y descarta las stopwords
+func tokenize(text string) []token {
+	tokens := make([]token, 0)
+	start := -1
+
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		folded := Fold(text[start:end])
+		if !stopwords[folded] {
+			tokens = append(tokens, token{word: folded, term: Stem(folded), start: start, end: end})
+		}
+		start = -1
+	}
+
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		flush(i)
+	}
+	flush(len(text))
+
+	return tokens
+}
+
+// Fold pasa la palabra a minúscula y le quita las tildes
+func Fold(word string) string {
+	return strings.Map(func(r rune) rune {
+		r = unicode.ToLower(r)
+		if folded, ok := foldings[r]; ok {
+			return folded
+		}
+		return r
+	}, word)
+}
+
+// Stem es un stemmer liviano para español: quita un sufijo derivativo, el plural, la terminación
+// del infinitivo y la vocal final. No busca raíces correctas sino que las variantes de una
+// palabra ("programa", "programas", "programación") terminen en el mismo término.
+func Stem(word string) string {
+	if len(word) <= minStemLength {
+		return word
+	}
+
+	for _, suffix := range derivationalSuffixes {
+		if strings.HasSuffix(word, suffix) && len(word)-len(suffix) >= minStemLength {
+			word = strings.TrimSuffix(word, suffix)
+			break
+		}
+	}
+
+	if strings.HasSuffix(word, "s") && len(word) > minStemLength+1 {
+		word = strings.TrimSuffix(word, "s")
+	}
+
+	for _, ending := range []string{"ar", "er", "ir"} {
+		if strings.HasSuffix(word, ending) && len(word)-len(ending) >= minStemLength+1 {
+			return strings.TrimSuffix(word, ending)
+		}
+	}
+
+	if last := word[len(word)-1]; strings.IndexByte("aeo", last) >= 0 && len(word) > minStemLength {
+		word = word[:len(word)-1]
+	}
+
+	return word
+}
+
+func toSet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, word := range words {
+		set[word] = true
+	}
+	return set
+}
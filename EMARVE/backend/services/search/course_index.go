@@ -0,0 +1,224 @@
+package search
+
+import (
+	"backend/domain"
+	"backend/interfaces"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Parámetros de BM25. k1 satura la frecuencia de un término y b pondera el largo del campo.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// indexedField es un campo del curso que se indexa, con el peso que tiene en el puntaje
+type indexedField struct {
+	name  string
+	boost float64
+	value func(domain.Course) string
+}
+
+// fields ordena la relevancia: una coincidencia en el título vale más que en la descripción
+// y esta más que en los requisitos
+var fields = []indexedField{
+	{domain.SearchFieldTitle, 3, func(c domain.Course) string { return c.Title }},
+	{domain.SearchFieldDescription, 1.5, func(c domain.Course) string { return c.Description }},
+	{domain.SearchFieldRequirement, 1, func(c domain.Course) string { return c.Requirement }},
+}
+
+type document struct {
+	course  domain.Course
+	lengths []int
+	// frequencies tiene, por término, la cantidad de apariciones en cada campo
+	frequencies map[string][]int
+	// words son las palabras plegadas del curso con la forma en que aparecen, para las correcciones
+	words map[string]string
+}
+
+// courseIndex es un índice invertido en memoria. Como el de throttle, sirve para una sola
+// instancia del backend y se reconstruye desde la base al arrancar.
+type courseIndex struct {
+	mu           sync.RWMutex
+	documents    map[int64]*document
+	postings     map[string]map[int64]bool
+	totalLengths []int
+}
+
+func NewCourseIndex() interfaces.CourseSearchIndexInterface {
+	return &courseIndex{
+		documents:    make(map[int64]*document),
+		postings:     make(map[string]map[int64]bool),
+		totalLengths: make([]int, len(fields)),
+	}
+}
+
+// Index agrega el curso o reemplaza la versión indexada
+func (i *courseIndex) Index(course domain.Course) {
+	doc := &document{
+		course:      course,
+		lengths:     make([]int, len(fields)),
+		frequencies: make(map[string][]int),
+		words:       make(map[string]string),
+	}
+	for f, field := range fields {
+		text := field.value(course)
+		tokens := tokenize(text)
+		doc.lengths[f] = len(tokens)
+		for _, t := range tokens {
+			if doc.frequencies[t.term] == nil {
+				doc.frequencies[t.term] = make([]int, len(fields))
+			}
+			doc.frequencies[t.term][f]++
+			if _, ok := doc.words[t.word]; !ok {
+				doc.words[t.word] = strings.ToLower(text[t.start:t.end])
+			}
+		}
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	id := int64(course.Id)
+	i.remove(id)
+	i.documents[id] = doc
+	for f := range fields {
+		i.totalLengths[f] += doc.lengths[f]
+	}
+	for term := range doc.frequencies {
+		if i.postings[term] == nil {
+			i.postings[term] = make(map[int64]bool)
+		}
+		i.postings[term][id] = true
+	}
+}
+
+func (i *courseIndex) Remove(courseID int64) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.remove(courseID)
+}
+
+func (i *courseIndex) remove(courseID int64) {
+	doc, ok := i.documents[courseID]
+	if !ok {
+		return
+	}
+	for f := range fields {
+		i.totalLengths[f] -= doc.lengths[f]
+	}
+	for term := range doc.frequencies {
+		delete(i.postings[term], courseID)
+		if len(i.postings[term]) == 0 {
+			delete(i.postings, term)
+		}
+	}
+	delete(i.documents, courseID)
+}
+
+// Courses devuelve todos los cursos indexados ordenados por id
+func (i *courseIndex) Courses() []domain.Course {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.sortedCourses()
+}
+
+// sortedCourses recorre los documentos en orden de id para que los resultados no dependan
+// del orden de los mapas. Requiere tener tomado el lock.
+func (i *courseIndex) sortedCourses() []domain.Course {
+	courses := make([]domain.Course, 0, len(i.documents))
+	for _, doc := range i.documents {
+		courses = append(courses, doc.course)
+	}
+	sort.Slice(courses, func(a, b int) bool {
+		return courses[a].Id < courses[b].Id
+	})
+	return courses
+}
+
+func (i *courseIndex) Len() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return len(i.documents)
+}
+
+// Search devuelve los cursos que contienen alguno de los términos, ordenados por puntaje BM25.
+// Un curso que contiene más términos de la consulta suma más puntaje.
+func (i *courseIndex) Search(query string) []domain.SearchHit {
+	terms := unique(Analyze(query))
+	if len(terms) == 0 {
+		return []domain.SearchHit{}
+	}
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	total := float64(len(i.documents))
+	averages := make([]float64, len(fields))
+	for f := range fields {
+		if total > 0 {
+			averages[f] = float64(i.totalLengths[f]) / total
+		}
+	}
+
+	scores := make(map[int64]float64)
+	for _, term := range terms {
+		matches := i.postings[term]
+		if len(matches) == 0 {
+			continue
+		}
+		df := float64(len(matches))
+		idf := math.Log(1 + (total-df+0.5)/(df+0.5))
+
+		for id := range matches {
+			doc := i.documents[id]
+			for f, field := range fields {
+				tf := float64(doc.frequencies[term][f])
+				if tf == 0 {
+					continue
+				}
+				norm := 1 - bm25B
+				if averages[f] > 0 {
+					norm += bm25B * float64(doc.lengths[f]) / averages[f]
+				}
+				scores[id] += field.boost * idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+			}
+		}
+	}
+
+	matched := toSet(terms...)
+	hits := make([]domain.SearchHit, 0, len(scores))
+	for id, score := range scores {
+		course := i.documents[id].course
+		highlights := make(map[string]string)
+		for _, field := range fields {
+			if snippet, ok := highlight(field.value(course), matched); ok {
+				highlights[field.name] = snippet
+			}
+		}
+		hits = append(hits, domain.SearchHit{Course: course, Score: score, Highlights: highlights})
+	}
+
+	sort.Slice(hits, func(a, b int) bool {
+		if hits[a].Score != hits[b].Score {
+			return hits[a].Score > hits[b].Score
+		}
+		return hits[a].Course.Id < hits[b].Course.Id
+	})
+	return hits
+}
+
+func unique(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	result := make([]string, 0, len(terms))
+	for _, term := range terms {
+		if !seen[term] {
+			seen[term] = true
+			result = append(result, term)
+		}
+	}
+	return result
+}
@@ -0,0 +1,66 @@
+package search
+
+import (
+	"html"
+	"strings"
+)
+
+// Largo aproximado de un fragmento resaltado y cuánto contexto se deja antes de la primera coincidencia
+const (
+	snippetLength  = 160
+	snippetContext = 40
+)
+
+// highlight arma un fragmento del texto alrededor de la primera coincidencia, escapado como HTML
+// y con los términos encontrados entre <em>. Devuelve false si el texto no contiene ningún término.
+func highlight(text string, terms map[string]bool) (string, bool) {
+	tokens := tokenize(text)
+
+	first := -1
+	for n, t := range tokens {
+		if terms[t.term] {
+			first = n
+			break
+		}
+	}
+	if first < 0 {
+		return "", false
+	}
+
+	start, end := 0, len(text)
+	if len(text) > snippetLength {
+		// El fragmento empieza y termina en límites de palabra
+		start = tokens[first].start
+		for n := first; n >= 0 && tokens[first].start-tokens[n].start <= snippetContext; n-- {
+			start = tokens[n].start
+		}
+		end = start
+		for _, t := range tokens {
+			if t.start >= start && t.end-start <= snippetLength {
+				end = t.end
+			}
+		}
+	}
+
+	var builder strings.Builder
+	if start > 0 {
+		builder.WriteString("…")
+	}
+	cursor := start
+	for _, t := range tokens {
+		if t.start < start || t.end > end || !terms[t.term] {
+			continue
+		}
+		builder.WriteString(html.EscapeString(text[cursor:t.start]))
+		builder.WriteString("<em>")
+		builder.WriteString(html.EscapeString(text[t.start:t.end]))
+		builder.WriteString("</em>")
+		cursor = t.end
+	}
+	builder.WriteString(html.EscapeString(text[cursor:end]))
+	if end < len(text) {
+		builder.WriteString("…")
+	}
+
+	return builder.String(), true
+}
@@ -0,0 +1,247 @@
+package search
+
+import (
+	"backend/domain"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Prioridad de una sugerencia: primero los valores que empiezan con el prefijo y después los
+// que tienen una palabra que empieza con él
+const (
+	matchWhole = iota
+	matchWord
+	noMatch
+)
+
+type suggestionCandidate struct {
+	suggestion domain.Suggestion
+	rank       int
+	key        string
+}
+
+// Suggest completa el prefijo con títulos, categorías e instructores de los cursos indexados.
+// Las categorías y los instructores se agrupan sin distinguir mayúsculas ni tildes. Solo se
+// usan los cursos para los que visible devuelve true.
+func (i *courseIndex) Suggest(prefix string, limit int, visible func(domain.Course) bool) []domain.Suggestion {
+	folded := strings.TrimSpace(Fold(prefix))
+	if folded == "" || limit < 1 {
+		return []domain.Suggestion{}
+	}
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	candidates := make([]*suggestionCandidate, 0)
+	grouped := make(map[string]*suggestionCandidate)
+
+	for _, course := range i.sortedCourses() {
+		if !visible(course) {
+			continue
+		}
+		title := Fold(course.Title)
+		if rank := prefixRank(title, folded); rank != noMatch {
+			candidates = append(candidates, &suggestionCandidate{
+				suggestion: domain.Suggestion{Text: course.Title, Field: domain.SearchFieldTitle, CourseID: course.Id},
+				rank:       rank,
+				key:        title,
+			})
+		}
+
+		for _, grouping := range []struct{ field, value string }{
+			{domain.SearchFieldCategory, course.Category},
+			{domain.SearchFieldInstructor, course.Instructor},
+		} {
+			field, value := grouping.field, grouping.value
+			key := Fold(strings.TrimSpace(value))
+			rank := prefixRank(key, folded)
+			if rank == noMatch {
+				continue
+			}
+			group := field + ":" + key
+			if candidate, ok := grouped[group]; ok {
+				candidate.suggestion.Count++
+				continue
+			}
+			candidate := &suggestionCandidate{
+				suggestion: domain.Suggestion{Text: strings.TrimSpace(value), Field: field, Count: 1},
+				rank:       rank,
+				key:        key,
+			}
+			grouped[group] = candidate
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	sort.Slice(candidates, func(a, b int) bool {
+		x, y := candidates[a], candidates[b]
+		if x.rank != y.rank {
+			return x.rank < y.rank
+		}
+		if x.suggestion.Count != y.suggestion.Count {
+			return x.suggestion.Count > y.suggestion.Count
+		}
+		if x.key != y.key {
+			return x.key < y.key
+		}
+		if x.suggestion.Field != y.suggestion.Field {
+			return x.suggestion.Field < y.suggestion.Field
+		}
+		return x.suggestion.CourseID < y.suggestion.CourseID
+	})
+
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	suggestions := make([]domain.Suggestion, 0, len(candidates))
+	for _, candidate := range candidates {
+		suggestions = append(suggestions, candidate.suggestion)
+	}
+	return suggestions
+}
+
+// prefixRank indica si el valor empieza con el prefijo o tiene una palabra que empieza con él
+func prefixRank(value, prefix string) int {
+	if strings.HasPrefix(value, prefix) {
+		return matchWhole
+	}
+	previous := ' '
+	for index, r := range value {
+		if !isWordRune(previous) && isWordRune(r) && strings.HasPrefix(value[index:], prefix) {
+			return matchWord
+		}
+		previous = r
+	}
+	return noMatch
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// vocabularyEntry es una palabra de los cursos visibles, con la cantidad de cursos que la usan
+type vocabularyEntry struct {
+	display   string
+	documents int
+}
+
+// Correct propone la consulta con cada palabra desconocida reemplazada por la palabra más
+// parecida del índice. Devuelve "" si no hay nada que corregir. Como en Suggest, solo se
+// usan las palabras de los cursos para los que visible devuelve true.
+func (i *courseIndex) Correct(query string, visible func(domain.Course) bool) string {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	terms := make(map[string]bool)
+	vocabulary := make(map[string]*vocabularyEntry)
+	for _, doc := range i.documents {
+		if !visible(doc.course) {
+			continue
+		}
+		for term := range doc.frequencies {
+			terms[term] = true
+		}
+		for word, display := range doc.words {
+			if vocabulary[word] == nil {
+				vocabulary[word] = &vocabularyEntry{display: display}
+			}
+			vocabulary[word].documents++
+		}
+	}
+
+	var builder strings.Builder
+	cursor := 0
+	changed := false
+
+	for _, t := range tokenize(query) {
+		if terms[t.term] {
+			continue
+		}
+		correction, ok := closestWord(t.word, vocabulary)
+		if !ok {
+			continue
+		}
+		builder.WriteString(query[cursor:t.start])
+		builder.WriteString(correction)
+		cursor = t.end
+		changed = true
+	}
+
+	if !changed {
+		return ""
+	}
+	builder.WriteString(query[cursor:])
+	return builder.String()
+}
+
+// closestWord busca la palabra del vocabulario a menor distancia de edición. Se admite un
+// error en palabras cortas y dos en las largas; a igual distancia gana la más usada.
+func closestWord(word string, vocabulary map[string]*vocabularyEntry) (string, bool) {
+	length := utf8.RuneCountInString(word)
+	if length < 3 {
+		return "", false
+	}
+	maxEdits := 1
+	if length > 4 {
+		maxEdits = 2
+	}
+
+	var best *vocabularyEntry
+	bestWord := ""
+	bestDistance := maxEdits + 1
+	for candidate, entry := range vocabulary {
+		if abs(utf8.RuneCountInString(candidate)-length) > maxEdits {
+			continue
+		}
+		distance := editDistance(word, candidate)
+		if distance == 0 || distance > maxEdits {
+			continue
+		}
+		if distance < bestDistance ||
+			(distance == bestDistance && (entry.documents > best.documents || (entry.documents == best.documents && candidate < bestWord))) {
+			best, bestWord, bestDistance = entry, candidate, distance
+		}
+	}
+
+	if best == nil {
+		return "", false
+	}
+	return best.display, true
+}
+
+// editDistance es la distancia de Damerau-Levenshtein restringida: cuenta inserciones,
+// borrados, reemplazos y transposiciones de letras vecinas, el error de tipeo más común
+func editDistance(a, b string) int {
+	x, y := []rune(a), []rune(b)
+	rows := make([][]int, len(x)+1)
+	for n := range rows {
+		rows[n] = make([]int, len(y)+1)
+		rows[n][0] = n
+	}
+	for m := range rows[0] {
+		rows[0][m] = m
+	}
+
+	for n := 1; n <= len(x); n++ {
+		for m := 1; m <= len(y); m++ {
+			cost := 1
+			if x[n-1] == y[m-1] {
+				cost = 0
+			}
+			rows[n][m] = min(rows[n-1][m]+1, rows[n][m-1]+1, rows[n-1][m-1]+cost)
+			if n > 1 && m > 1 && x[n-1] == y[m-2] && x[n-2] == y[m-1] {
+				rows[n][m] = min(rows[n][m], rows[n-2][m-2]+1)
+			}
+		}
+	}
+	return rows[len(x)][len(y)]
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
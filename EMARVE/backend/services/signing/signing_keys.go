@@ -0,0 +1,310 @@
+package signing
+
+import (
+	"backend/domain"
+	"backend/interfaces"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	jwt "github.com/golang-jwt/jwt"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// KeyConfig describe una clave en el archivo de configuración. Las claves asimétricas pueden
+// declararse solo con la pública para seguir verificando tokens después de rotarlas.
+type KeyConfig struct {
+	Kid            string `json:"kid"`
+	Alg            string `json:"alg"`
+	Secret         string `json:"secret,omitempty"`
+	PrivateKey     string `json:"private_key,omitempty"`
+	PrivateKeyFile string `json:"private_key_file,omitempty"`
+	PublicKey      string `json:"public_key,omitempty"`
+	PublicKeyFile  string `json:"public_key_file,omitempty"`
+}
+
+// Config es el conjunto de claves válidas y cuál de ellas firma los tokens nuevos
+type Config struct {
+	ActiveKid string      `json:"active_kid"`
+	Keys      []KeyConfig `json:"keys"`
+}
+
+type signingKey struct {
+	kid       string
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// KeySet implementa TokenSignerInterface
+type KeySet struct {
+	active *signingKey
+	keys   map[string]*signingKey
+}
+
+// NewKeySet carga las claves de la configuración. La clave activa debe tener material privado.
+func NewKeySet(config Config) (*KeySet, error) {
+	if len(config.Keys) == 0 {
+		return nil, errors.New("at least one signing key is required")
+	}
+
+	ks := &KeySet{keys: make(map[string]*signingKey)}
+	for _, keyConfig := range config.Keys {
+		key, err := loadKey(keyConfig)
+		if err != nil {
+			return nil, fmt.Errorf("error loading key %q: %v", keyConfig.Kid, err)
+		}
+		if _, exists := ks.keys[key.kid]; exists {
+			return nil, fmt.Errorf("duplicated key id %q", key.kid)
+		}
+		ks.keys[key.kid] = key
+	}
+
+	active, ok := ks.keys[config.ActiveKid]
+	if !ok {
+		return nil, fmt.Errorf("active key %q is not configured", config.ActiveKid)
+	}
+	if active.signKey == nil {
+		return nil, fmt.Errorf("active key %q has no private key", config.ActiveKid)
+	}
+	ks.active = active
+
+	return ks, nil
+}
+
+// LoadFromEnv arma el KeySet desde JWT_KEYS_FILE (JSON con el formato de Config) o, en su
+// defecto, desde JWT_SECRET/JWT_KID. Sin configuración falla, salvo que JWT_EPHEMERAL_KEY
+// habilite una clave Ed25519 efímera: sirve para desarrollo pero invalida los tokens en cada
+// reinicio y no se comparte entre réplicas.
+func LoadFromEnv() (interfaces.TokenSignerInterface, error) {
+	if path := os.Getenv("JWT_KEYS_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %v", path, err)
+		}
+		var config Config
+		if err := json.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("error parsing %s: %v", path, err)
+		}
+		return NewKeySet(config)
+	}
+
+	if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		kid := os.Getenv("JWT_KID")
+		if kid == "" {
+			kid = "default"
+		}
+		return NewKeySet(Config{
+			ActiveKid: kid,
+			Keys:      []KeyConfig{{Kid: kid, Alg: AlgorithmHS256, Secret: secret}},
+		})
+	}
+
+	if ephemeral := strings.ToLower(os.Getenv("JWT_EPHEMERAL_KEY")); ephemeral != "true" && ephemeral != "1" {
+		return nil, errors.New("JWT_KEYS_FILE or JWT_SECRET must be set (or JWT_EPHEMERAL_KEY=true for development)")
+	}
+
+	log.Warn("JWT_EPHEMERAL_KEY is set: using an ephemeral signing key, tokens will not survive a restart")
+	return NewEphemeralKeySet()
+}
+
+// NewEphemeralKeySet genera una clave Ed25519 aleatoria que solo vive en memoria
+func NewEphemeralKeySet() (*KeySet, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("error generating signing key: %v", err)
+	}
+
+	key := &signingKey{
+		kid:       "ephemeral-" + base64.RawURLEncoding.EncodeToString(publicKey[:6]),
+		method:    jwt.SigningMethodEdDSA,
+		signKey:   privateKey,
+		verifyKey: publicKey,
+	}
+	return &KeySet{active: key, keys: map[string]*signingKey{key.kid: key}}, nil
+}
+
+// MustEphemeralKeySet es como NewEphemeralKeySet pero entra en pánico si no puede generar la clave
+func MustEphemeralKeySet() *KeySet {
+	ks, err := NewEphemeralKeySet()
+	if err != nil {
+		panic(err)
+	}
+	return ks
+}
+
+func loadKey(config KeyConfig) (*signingKey, error) {
+	if config.Kid == "" {
+		return nil, errors.New("kid is required")
+	}
+
+	key := &signingKey{kid: config.Kid}
+
+	switch config.Alg {
+	case AlgorithmHS256:
+		if len(config.Secret) < 32 {
+			return nil, errors.New("HS256 secrets must have at least 32 characters")
+		}
+		key.method = jwt.SigningMethodHS256
+		key.signKey = []byte(config.Secret)
+		key.verifyKey = []byte(config.Secret)
+
+	case AlgorithmRS256:
+		key.method = jwt.SigningMethodRS256
+		privatePEM, publicPEM, err := readPEMs(config)
+		if err != nil {
+			return nil, err
+		}
+		if privatePEM != nil {
+			privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privatePEM)
+			if err != nil {
+				return nil, err
+			}
+			key.signKey = privateKey
+			key.verifyKey = &privateKey.PublicKey
+		} else {
+			publicKey, err := jwt.ParseRSAPublicKeyFromPEM(publicPEM)
+			if err != nil {
+				return nil, err
+			}
+			key.verifyKey = publicKey
+		}
+
+	case AlgorithmEdDSA:
+		key.method = jwt.SigningMethodEdDSA
+		privatePEM, publicPEM, err := readPEMs(config)
+		if err != nil {
+			return nil, err
+		}
+		if privatePEM != nil {
+			privateKey, err := jwt.ParseEdPrivateKeyFromPEM(privatePEM)
+			if err != nil {
+				return nil, err
+			}
+			edKey, ok := privateKey.(ed25519.PrivateKey)
+			if !ok {
+				return nil, errors.New("private key is not an Ed25519 key")
+			}
+			key.signKey = edKey
+			key.verifyKey = edKey.Public().(ed25519.PublicKey)
+		} else {
+			publicKey, err := jwt.ParseEdPublicKeyFromPEM(publicPEM)
+			if err != nil {
+				return nil, err
+			}
+			edKey, ok := publicKey.(ed25519.PublicKey)
+			if !ok {
+				return nil, errors.New("public key is not an Ed25519 key")
+			}
+			key.verifyKey = edKey
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", config.Alg)
+	}
+
+	return key, nil
+}
+
+// readPEMs devuelve el PEM privado si está configurado y, si no, el público
+func readPEMs(config KeyConfig) ([]byte, []byte, error) {
+	switch {
+	case config.PrivateKey != "":
+		return []byte(config.PrivateKey), nil, nil
+	case config.PrivateKeyFile != "":
+		data, err := os.ReadFile(config.PrivateKeyFile)
+		return data, nil, err
+	case config.PublicKey != "":
+		return nil, []byte(config.PublicKey), nil
+	case config.PublicKeyFile != "":
+		data, err := os.ReadFile(config.PublicKeyFile)
+		return nil, data, err
+	}
+	return nil, nil, errors.New("a private or public key is required")
+}
+
+// Sign firma los claims con la clave activa e incluye su kid en el header
+func (ks *KeySet) Sign(claims map[string]interface{}) (string, error) {
+	token := jwt.NewWithClaims(ks.active.method, jwt.MapClaims(claims))
+	token.Header["kid"] = ks.active.kid
+
+	tokenString, err := token.SignedString(ks.active.signKey)
+	if err != nil {
+		return "", fmt.Errorf("error signing JWT: %v", err)
+	}
+	return tokenString, nil
+}
+
+// Verify valida la firma con la clave indicada por el kid del token, exigiendo que el
+// algoritmo del header coincida con el de esa clave
+func (ks *KeySet) Verify(tokenString string) (map[string]interface{}, error) {
+	claims := jwt.MapClaims{}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := ks.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return key.verifyKey, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error parsing token: %v", err)
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	return claims, nil
+}
+
+// JWKS devuelve las claves públicas vigentes. Las claves HMAC no se publican.
+func (ks *KeySet) JWKS() domain.JWKSet {
+	set := domain.JWKSet{Keys: make([]domain.JWK, 0)}
+
+	for _, key := range ks.keys {
+		switch publicKey := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, domain.JWK{
+				Kty: "RSA",
+				Kid: key.kid,
+				Alg: AlgorithmRS256,
+				Use: "sig",
+				N:   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, domain.JWK{
+				Kty: "OKP",
+				Kid: key.kid,
+				Alg: AlgorithmEdDSA,
+				Use: "sig",
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(publicKey),
+			})
+		}
+	}
+
+	sort.Slice(set.Keys, func(i, j int) bool {
+		return set.Keys[i].Kid < set.Keys[j].Kid
+	})
+
+	return set
+}
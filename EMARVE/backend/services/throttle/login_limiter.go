@@ -0,0 +1,169 @@
+package throttle
+
+import (
+	"backend/domain"
+	"backend/interfaces"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Policy define cuántos intentos fallidos se toleran antes de empezar a demorar el login
+// y cuántos antes de bloquearlo
+type Policy struct {
+	FreeAttempts     int
+	BaseDelay        time.Duration
+	MaxDelay         time.Duration
+	LockoutThreshold int
+	LockoutDuration  time.Duration
+	// Window es el tiempo sin fallos después del cual el contador vuelve a cero
+	Window time.Duration
+}
+
+// Config tiene una política por cuenta y otra, más permisiva, por IP (varias personas
+// pueden compartir la IP de una red)
+type Config struct {
+	Account Policy
+	IP      Policy
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Account: Policy{
+			FreeAttempts:     3,
+			BaseDelay:        time.Second,
+			MaxDelay:         time.Minute,
+			LockoutThreshold: 10,
+			LockoutDuration:  15 * time.Minute,
+			Window:           15 * time.Minute,
+		},
+		IP: Policy{
+			FreeAttempts:     20,
+			BaseDelay:        time.Second,
+			MaxDelay:         time.Minute,
+			LockoutThreshold: 100,
+			LockoutDuration:  15 * time.Minute,
+			Window:           15 * time.Minute,
+		},
+	}
+}
+
+// ConfigFromEnv lee LOGIN_FREE_ATTEMPTS, LOGIN_LOCKOUT_THRESHOLD y LOGIN_LOCKOUT_DURATION
+// para la política por cuenta
+func ConfigFromEnv() Config {
+	config := DefaultConfig()
+	if value, err := strconv.Atoi(os.Getenv("LOGIN_FREE_ATTEMPTS")); err == nil && value >= 0 {
+		config.Account.FreeAttempts = value
+	}
+	if value, err := strconv.Atoi(os.Getenv("LOGIN_LOCKOUT_THRESHOLD")); err == nil && value > 0 {
+		config.Account.LockoutThreshold = value
+	}
+	if value, err := time.ParseDuration(os.Getenv("LOGIN_LOCKOUT_DURATION")); err == nil && value > 0 {
+		config.Account.LockoutDuration = value
+	}
+	return config
+}
+
+// LoginLimiter aplica backoff exponencial y bloqueo temporal a los intentos de login
+type LoginLimiter struct {
+	mu     sync.Mutex
+	store  interfaces.LoginAttemptStoreInterface
+	config Config
+}
+
+func NewLoginLimiter(store interfaces.LoginAttemptStoreInterface, config Config) *LoginLimiter {
+	return &LoginLimiter{store: store, config: config}
+}
+
+// NewDefaultLoginLimiter usa el store en memoria y DefaultConfig
+func NewDefaultLoginLimiter() *LoginLimiter {
+	return NewLoginLimiter(NewMemoryStore(), DefaultConfig())
+}
+
+func accountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Check devuelve un *domain.LoginThrottledError si la cuenta o la IP todavía no pueden intentar
+func (l *LoginLimiter) Check(email string, ip string) error {
+	now := time.Now()
+
+	if attempt, ok := l.store.Get(accountKey(email)); ok && now.Before(attempt.BlockedUntil) {
+		return &domain.LoginThrottledError{RetryAfter: attempt.BlockedUntil.Sub(now), Locked: attempt.Locked}
+	}
+
+	if ip == "" {
+		return nil
+	}
+	if attempt, ok := l.store.Get(ipKey(ip)); ok && now.Before(attempt.BlockedUntil) {
+		return &domain.LoginThrottledError{RetryAfter: attempt.BlockedUntil.Sub(now)}
+	}
+
+	return nil
+}
+
+// RecordFailure suma un intento fallido a la cuenta y a la IP
+func (l *LoginLimiter) RecordFailure(email string, ip string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.fail(accountKey(email), l.config.Account, true)
+	if ip != "" {
+		l.fail(ipKey(ip), l.config.IP, false)
+	}
+}
+
+// RecordSuccess limpia el contador de la cuenta. El de la IP se mantiene, para que un
+// atacante no pueda reiniciarlo entrando con una cuenta propia.
+func (l *LoginLimiter) RecordSuccess(email string) {
+	l.store.Delete(accountKey(email))
+}
+
+// Unlock quita el bloqueo y los intentos acumulados de una cuenta
+func (l *LoginLimiter) Unlock(email string) {
+	l.store.Delete(accountKey(email))
+}
+
+func (l *LoginLimiter) fail(key string, policy Policy, lockable bool) {
+	now := time.Now()
+
+	attempt, ok := l.store.Get(key)
+	if !ok || now.Sub(attempt.LastFailure) > policy.Window {
+		attempt = domain.LoginAttempt{}
+	}
+
+	attempt.Failures++
+	attempt.LastFailure = now
+
+	switch {
+	case attempt.Failures >= policy.LockoutThreshold:
+		attempt.BlockedUntil = now.Add(policy.LockoutDuration)
+		attempt.Locked = lockable
+	case attempt.Failures > policy.FreeAttempts:
+		attempt.BlockedUntil = now.Add(backoff(policy, attempt.Failures-policy.FreeAttempts))
+	}
+
+	ttl := policy.Window
+	if remaining := attempt.BlockedUntil.Sub(now); remaining > ttl {
+		ttl = remaining
+	}
+	l.store.Set(key, attempt, ttl)
+}
+
+// backoff duplica la espera por cada intento fallido extra, hasta MaxDelay
+func backoff(policy Policy, extraFailures int) time.Duration {
+	delay := policy.BaseDelay
+	for i := 1; i < extraFailures; i++ {
+		delay *= 2
+		if delay >= policy.MaxDelay {
+			return policy.MaxDelay
+		}
+	}
+	return delay
+}
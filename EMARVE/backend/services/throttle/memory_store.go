@@ -0,0 +1,64 @@
+package throttle
+
+import (
+	"backend/domain"
+	"backend/interfaces"
+	"sync"
+	"time"
+)
+
+// maxEntriesBeforePrune es la cantidad de entradas a partir de la cual Set limpia las vencidas
+const maxEntriesBeforePrune = 10000
+
+type memoryEntry struct {
+	attempt   domain.LoginAttempt
+	expiresAt time.Time
+}
+
+// memoryStore guarda los contadores en memoria. Solo sirve para una instancia del backend;
+// con varias réplicas hace falta un store compartido (por ejemplo Redis).
+type memoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+func NewMemoryStore() interfaces.LoginAttemptStoreInterface {
+	return &memoryStore{entries: make(map[string]memoryEntry)}
+}
+
+func (s *memoryStore) Get(key string) (domain.LoginAttempt, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return domain.LoginAttempt{}, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(s.entries, key)
+		return domain.LoginAttempt{}, false
+	}
+	return entry.attempt, true
+}
+
+func (s *memoryStore) Set(key string, attempt domain.LoginAttempt, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if len(s.entries) >= maxEntriesBeforePrune {
+		for k, entry := range s.entries {
+			if now.After(entry.expiresAt) {
+				delete(s.entries, k)
+			}
+		}
+	}
+	s.entries[key] = memoryEntry{attempt: attempt, expiresAt: now.Add(ttl)}
+}
+
+func (s *memoryStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+}
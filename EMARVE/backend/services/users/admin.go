@@ -0,0 +1,182 @@
+package users
+
+import (
+	"backend/domain"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Tamaños de página del listado de usuarios
+const (
+	DefaultUsersPageSize = 20
+	MaxUsersPageSize     = 100
+)
+
+// checkAccountUsable rechaza las cuentas deshabilitadas o con un cambio de contraseña forzado
+func checkAccountUsable(user *domain.User) error {
+	if user.DisabledAt != nil {
+		return domain.ErrAccountDisabled
+	}
+	if user.PasswordResetRequired {
+		return domain.ErrPasswordResetRequired
+	}
+	return nil
+}
+
+// ListUsers devuelve una página de usuarios, sin el hash de la contraseña
+func (s *userService) ListUsers(query domain.UserQuery) (domain.UserPage, error) {
+	query.Email = strings.TrimSpace(query.Email)
+	query.Nickname = strings.TrimSpace(query.Nickname)
+
+	if query.Sort != "" {
+		if _, ok := domain.UserSortFields[strings.TrimPrefix(query.Sort, "-")]; !ok {
+			return domain.UserPage{}, fmt.Errorf("invalid sort field %q", query.Sort)
+		}
+	}
+
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PageSize < 1 {
+		query.PageSize = DefaultUsersPageSize
+	}
+	if query.PageSize > MaxUsersPageSize {
+		query.PageSize = MaxUsersPageSize
+	}
+
+	users, total, err := s.repo.ListUsers(query)
+	if err != nil {
+		return domain.UserPage{}, fmt.Errorf("error getting users from DB: %v", err)
+	}
+
+	results := make([]domain.UserResponse, 0, len(users))
+	for i := range users {
+		results = append(results, domain.NewUserResponse(&users[i]))
+	}
+
+	return domain.UserPage{
+		Result:   results,
+		Total:    total,
+		Page:     query.Page,
+		PageSize: query.PageSize,
+	}, nil
+}
+
+// PromoteUser agrega un rol al usuario (admin si no se indica otro)
+func (s *userService) PromoteUser(userID int64, role string, actor domain.Actor) error {
+	role = roleOrAdmin(role)
+
+	user, err := s.GetUserById(userID)
+	if err != nil {
+		return err
+	}
+	if user.HasRole(role) {
+		return fmt.Errorf("user already has role %s", role)
+	}
+
+	return s.SetUserRoles(user.Id, append(append([]string{}, user.Roles...), role), actor)
+}
+
+// DemoteUser quita un rol al usuario (admin si no se indica otro). Si se queda sin roles
+// pasa a ser estudiante.
+func (s *userService) DemoteUser(userID int64, role string, actor domain.Actor) error {
+	role = roleOrAdmin(role)
+
+	user, err := s.GetUserById(userID)
+	if err != nil {
+		return err
+	}
+	if !user.HasRole(role) {
+		return fmt.Errorf("user does not have role %s", role)
+	}
+
+	roles := make([]string, 0, len(user.Roles))
+	for _, assigned := range user.Roles {
+		if assigned != role {
+			roles = append(roles, assigned)
+		}
+	}
+	if len(roles) == 0 {
+		roles = append(roles, domain.RoleStudent)
+	}
+
+	return s.SetUserRoles(user.Id, roles, actor)
+}
+
+func roleOrAdmin(role string) string {
+	role = strings.ToLower(strings.TrimSpace(role))
+	if role == "" {
+		return domain.RoleAdmin
+	}
+	return role
+}
+
+// DisableUser bloquea la cuenta y cierra sus sesiones
+func (s *userService) DisableUser(userID int64, actor domain.Actor) error {
+	if actor.UserID == userID {
+		return errors.New("admins cannot disable their own account")
+	}
+
+	user, err := s.GetUserById(userID)
+	if err != nil {
+		return err
+	}
+	if user.DisabledAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	if err := s.repo.SetUserDisabled(user.Id, &now); err != nil {
+		return fmt.Errorf("error disabling user in DB: %v", err)
+	}
+
+	if err := s.repo.RevokeUserSessions(user.Id, now); err != nil {
+		return fmt.Errorf("error revoking sessions in DB: %v", err)
+	}
+
+	s.auditUser(actor, domain.AuditUserDisabled, user.Id)
+	return nil
+}
+
+func (s *userService) EnableUser(userID int64, actor domain.Actor) error {
+	user, err := s.GetUserById(userID)
+	if err != nil {
+		return err
+	}
+	if user.DisabledAt == nil {
+		return nil
+	}
+
+	if err := s.repo.SetUserDisabled(user.Id, nil); err != nil {
+		return fmt.Errorf("error enabling user in DB: %v", err)
+	}
+
+	s.auditUser(actor, domain.AuditUserEnabled, user.Id)
+	return nil
+}
+
+// ForcePasswordReset obliga al usuario a elegir una contraseña nueva: cierra sus sesiones,
+// rechaza el login con la contraseña actual y le envía un link de recuperación
+func (s *userService) ForcePasswordReset(userID int64, actor domain.Actor) error {
+	user, err := s.GetUserById(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.SetPasswordResetRequired(user.Id, true); err != nil {
+		return fmt.Errorf("error forcing password reset in DB: %v", err)
+	}
+
+	if err := s.repo.RevokeUserSessions(user.Id, time.Now()); err != nil {
+		return fmt.Errorf("error revoking sessions in DB: %v", err)
+	}
+
+	if err := s.sendPasswordReset(user); err != nil {
+		return err
+	}
+
+	s.auditUser(actor, domain.AuditUserResetForced, user.Id)
+	return nil
+}
@@ -0,0 +1,39 @@
+package users
+
+import (
+	"backend/domain"
+	"backend/interfaces"
+	"backend/services/audit"
+	"fmt"
+)
+
+// WithAuditRecorder registra los logins y las acciones sensibles de los usuarios en el log de auditoría
+func WithAuditRecorder(recorder interfaces.AuditRecorderInterface) Option {
+	return func(s *userService) {
+		s.auditLog = recorder
+	}
+}
+
+// userActor es el actor de las acciones que el usuario hace sobre su propia cuenta
+func userActor(user *domain.User, ip string) domain.Actor {
+	return domain.Actor{UserID: user.Id, Role: domain.PrimaryRole(user.Roles), IP: ip}
+}
+
+func (s *userService) auditUser(actor domain.Actor, action string, userID int64) {
+	s.auditLog.Record(audit.NewEvent(actor, action, domain.AuditTargetUser, userID))
+}
+
+// auditLoginFailed registra un login rechazado. Si el email no corresponde a ningún
+// usuario el evento queda sin actor, con el email en el detalle.
+func (s *userService) auditLoginFailed(user *domain.User, email string, client domain.ClientInfo, reason string) {
+	actor := domain.Actor{IP: client.IP}
+	var userID int64
+	if user != nil {
+		actor = userActor(user, client.IP)
+		userID = user.Id
+	}
+
+	event := audit.NewEvent(actor, domain.AuditLoginFailed, domain.AuditTargetUser, userID)
+	event.Details = fmt.Sprintf("%s: %s", email, reason)
+	s.auditLog.Record(event)
+}
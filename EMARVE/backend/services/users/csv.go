@@ -0,0 +1,316 @@
+package users
+
+import (
+	"backend/domain"
+	"backend/services/audit"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// MaxImportRows limita el tamaño de una importación para que se pueda revisar el reporte
+const MaxImportRows = 1000
+
+// Columnas del CSV de usuarios. La importación requiere nickname y email y acepta además
+// password y roles, separados con ";".
+var (
+	requiredImportColumns = []string{"nickname", "email"}
+	exportColumns         = []string{"id", "nickname", "email", "roles", "email_verified_at", "disabled_at"}
+)
+
+// ImportUsers valida todas las filas del CSV y, si no es una prueba (DryRun) y ninguna fila
+// tiene errores, crea los usuarios con la misma validación y el mismo hasher que el registro.
+// Las filas sin contraseña reciben una aleatoria y un link para elegir la propia. Asignar
+// un rol distinto de student requiere el mismo permiso que en el resto de la API.
+func (s *userService) ImportUsers(r io.Reader, options domain.UserImportOptions, actor domain.Actor) (domain.UserImportReport, error) {
+	report := domain.UserImportReport{DryRun: options.DryRun, Rows: make([]domain.UserImportRow, 0)}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return report, fmt.Errorf("error reading CSV header: %v", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	for _, required := range requiredImportColumns {
+		if _, ok := columns[required]; !ok {
+			return report, fmt.Errorf("missing required column %q", required)
+		}
+	}
+
+	validRoles, err := s.roleNames()
+	if err != nil {
+		return report, err
+	}
+	canAssignRoles := actor.HasPermission(domain.PermRoleAssign)
+
+	for _, courseID := range options.CourseIDs {
+		if _, err := s.repo.GetCourseById(courseID); err != nil {
+			return report, fmt.Errorf("course %d not found", courseID)
+		}
+	}
+
+	passwords := make(map[int]string)
+	seenEmails := make(map[string]int)
+	seenNicknames := make(map[string]int)
+	line := 1
+
+	for {
+		record, err := reader.Read()
+		line++
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return report, fmt.Errorf("error reading CSV line %d: %v", line, err)
+		}
+		if emptyRecord(record) {
+			continue
+		}
+		if len(report.Rows) == MaxImportRows {
+			return report, fmt.Errorf("the file has more than %d rows", MaxImportRows)
+		}
+
+		row := domain.UserImportRow{
+			Line:     line,
+			Nickname: column(record, columns, "nickname"),
+			Email:    column(record, columns, "email"),
+			Roles:    normalizeRoles(strings.Split(column(record, columns, "roles"), ";")),
+		}
+		password := column(record, columns, "password")
+		if len(row.Roles) == 0 {
+			row.Roles = []string{domain.RoleStudent}
+		}
+
+		s.validateImportRow(&row, password, validRoles, canAssignRoles, seenEmails, seenNicknames)
+		if len(row.Errors) > 0 {
+			row.Status = domain.ImportRowError
+		} else {
+			row.Status = domain.ImportRowValid
+			report.Valid++
+		}
+		passwords[len(report.Rows)] = password
+		report.Rows = append(report.Rows, row)
+	}
+
+	report.Total = len(report.Rows)
+	if options.DryRun {
+		return report, nil
+	}
+	if report.Valid != report.Total {
+		return report, domain.ErrImportInvalid
+	}
+
+	for i := range report.Rows {
+		if s.importRow(&report.Rows[i], passwords[i], options.CourseIDs, actor) {
+			report.Created++
+		}
+	}
+
+	return report, nil
+}
+
+// validateImportRow agrega a la fila los errores de validación, incluidos los duplicados
+// dentro del archivo y contra los usuarios existentes. Sin canAssignRoles solo se acepta
+// el rol student.
+func (s *userService) validateImportRow(row *domain.UserImportRow, password string, validRoles map[string]bool, canAssignRoles bool, seenEmails, seenNicknames map[string]int) {
+	// La contraseña es opcional, así que se valida solo si viene
+	if password == "" {
+		password = "-"
+	}
+	if err := validateRegistration(row.Nickname, row.Email, password); err != nil {
+		row.Errors = append(row.Errors, err.Error())
+	}
+
+	for _, role := range row.Roles {
+		if !validRoles[role] {
+			row.Errors = append(row.Errors, fmt.Sprintf("unknown role %q", role))
+		} else if role != domain.RoleStudent && !canAssignRoles {
+			row.Errors = append(row.Errors, fmt.Sprintf("assigning role %q requires the %s permission", role, domain.PermRoleAssign))
+		}
+	}
+
+	if row.Email != "" {
+		key := strings.ToLower(row.Email)
+		if previous, ok := seenEmails[key]; ok {
+			row.Errors = append(row.Errors, fmt.Sprintf("email repeated from line %d", previous))
+		} else {
+			seenEmails[key] = row.Line
+			if taken, err := s.repo.IsEmailTaken(row.Email, 0); err != nil {
+				row.Errors = append(row.Errors, fmt.Sprintf("error checking email in DB: %v", err))
+			} else if taken {
+				row.Errors = append(row.Errors, domain.ErrEmailTaken.Error())
+			}
+		}
+	}
+
+	if row.Nickname != "" {
+		key := strings.ToLower(row.Nickname)
+		if previous, ok := seenNicknames[key]; ok {
+			row.Errors = append(row.Errors, fmt.Sprintf("nickname repeated from line %d", previous))
+		} else {
+			seenNicknames[key] = row.Line
+			if taken, err := s.repo.IsNicknameTaken(row.Nickname, 0); err != nil {
+				row.Errors = append(row.Errors, fmt.Sprintf("error checking nickname in DB: %v", err))
+			} else if taken {
+				row.Errors = append(row.Errors, domain.ErrNicknameTaken.Error())
+			}
+		}
+	}
+}
+
+// importRow crea el usuario de una fila ya validada. Un error solo afecta a esa fila.
+func (s *userService) importRow(row *domain.UserImportRow, password string, courseIDs []int64, actor domain.Actor) bool {
+	fail := func(err error) bool {
+		row.Status = domain.ImportRowError
+		row.Errors = append(row.Errors, err.Error())
+		return false
+	}
+
+	generated := password == ""
+	if generated {
+		random, err := randomHex(16)
+		if err != nil {
+			return fail(err)
+		}
+		password = random
+	}
+
+	hash, err := s.hasher.Hash(password)
+	if err != nil {
+		return fail(err)
+	}
+
+	err = s.repo.CreateUser(domain.User{
+		Nickname:     row.Nickname,
+		Email:        row.Email,
+		PasswordHash: hash,
+		Roles:        row.Roles,
+	})
+	if err != nil {
+		return fail(fmt.Errorf("error creating user from DB: %v", err))
+	}
+
+	created, err := s.repo.GetUserByEmail(row.Email)
+	if err != nil {
+		return fail(fmt.Errorf("error getting created user from DB: %v", err))
+	}
+	row.UserID = created.Id
+	row.Status = domain.ImportRowCreated
+
+	// Desde acá el usuario ya existe: los errores se informan pero la fila cuenta como creada
+	for _, courseID := range courseIDs {
+		if err := s.repo.InsertSubscription(created.Id, courseID); err != nil {
+			row.Errors = append(row.Errors, fmt.Sprintf("error subscribing to course %d: %v", courseID, err))
+		}
+	}
+
+	if err := s.sendVerification(created); err != nil {
+		log.Warnf("error sending verification to imported user %d: %v", created.Id, err)
+	}
+	if generated {
+		if err := s.sendPasswordReset(created); err != nil {
+			log.Warnf("error sending password link to imported user %d: %v", created.Id, err)
+		}
+	}
+
+	event := audit.NewEvent(actor, domain.AuditUserImported, domain.AuditTargetUser, created.Id)
+	event.After = audit.Snapshot(domain.NewUserResponse(created))
+	s.auditLog.Record(event)
+
+	return true
+}
+
+// roleNames devuelve los roles existentes para validar las filas
+func (s *userService) roleNames() (map[string]bool, error) {
+	roles, err := s.repo.GetRoles()
+	if err != nil {
+		return nil, fmt.Errorf("error getting roles from DB: %v", err)
+	}
+	names := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		names[role.Name] = true
+	}
+	return names, nil
+}
+
+func column(record []string, columns map[string]int, name string) string {
+	index, ok := columns[name]
+	if !ok || index >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[index])
+}
+
+func emptyRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// ExportUsersCSV escribe en CSV todos los usuarios que cumplen los filtros, con las mismas
+// columnas que acepta la importación salvo la contraseña
+func (s *userService) ExportUsersCSV(query domain.UserQuery, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(exportColumns); err != nil {
+		return err
+	}
+
+	query.PageSize = MaxUsersPageSize
+	for query.Page = 1; ; query.Page++ {
+		page, err := s.ListUsers(query)
+		if err != nil {
+			return err
+		}
+
+		for _, user := range page.Result {
+			record := []string{
+				fmt.Sprint(user.Id),
+				csvSafe(user.Nickname),
+				csvSafe(user.Email),
+				strings.Join(user.Roles, ";"),
+				formatTime(user.EmailVerifiedAt),
+				formatTime(user.DisabledAt),
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+
+		if len(page.Result) == 0 || int64(query.Page*query.PageSize) >= page.Total {
+			break
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvSafe evita que una planilla interprete el valor como fórmula
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func formatTime(value *time.Time) string {
+	if value == nil {
+		return ""
+	}
+	return value.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,136 @@
+package users
+
+import (
+	"backend/domain"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Políticas para los usuarios que todavía no verificaron su email
+const (
+	// VerificationEnforce rechaza el login hasta que el email esté verificado
+	VerificationEnforce = "enforce"
+	// VerificationWarn permite el login e informa email_verified=false en la respuesta
+	VerificationWarn = "warn"
+)
+
+// VerificationPolicyFromEnv lee EMAIL_VERIFICATION_POLICY ("enforce" por defecto)
+func VerificationPolicyFromEnv() string {
+	if strings.ToLower(os.Getenv("EMAIL_VERIFICATION_POLICY")) == VerificationWarn {
+		return VerificationWarn
+	}
+	return VerificationEnforce
+}
+
+// WithVerificationPolicy define qué hace el login con las cuentas sin verificar
+func WithVerificationPolicy(policy string) Option {
+	return func(s *userService) {
+		s.verificationPolicy = policy
+	}
+}
+
+// WithAPIBaseURL define la URL pública del backend, usada en el link de verificación
+func WithAPIBaseURL(baseURL string) Option {
+	return func(s *userService) {
+		s.apiBaseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// sendVerification invalida los links anteriores y envía uno nuevo al email del usuario
+func (s *userService) sendVerification(user *domain.User) error {
+	now := time.Now()
+
+	if err := s.repo.InvalidateUserTokens(user.Id, domain.TokenPurposeEmailVerification, now); err != nil {
+		return fmt.Errorf("error invalidating previous verification tokens in DB: %v", err)
+	}
+
+	token, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.CreateUserToken(domain.UserToken{
+		UserID:    user.Id,
+		Purpose:   domain.TokenPurposeEmailVerification,
+		TokenHash: tokenHash,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.tokenConfig.EmailVerificationTTL),
+	})
+	if err != nil {
+		return fmt.Errorf("error creating verification token in DB: %v", err)
+	}
+
+	link := fmt.Sprintf("%s/users/verify?token=%s", s.apiBaseURL, url.QueryEscape(token))
+	err = s.mailer.Send(domain.Email{
+		To:      user.Email,
+		Subject: "Verificá tu email de EMARVE",
+		Body: fmt.Sprintf("Hola %s,\n\nPara activar tu cuenta entrá a este link:\n\n%s\n\nEl link vence en %s.\n",
+			user.Nickname, link, s.tokenConfig.EmailVerificationTTL),
+	})
+	if err != nil {
+		return fmt.Errorf("error sending verification email: %v", err)
+	}
+
+	return nil
+}
+
+// VerifyEmail activa la cuenta asociada a un token de verificación
+func (s *userService) VerifyEmail(token string) error {
+	if strings.TrimSpace(token) == "" {
+		return errors.New("token is required")
+	}
+
+	userToken, err := s.useToken(token, domain.TokenPurposeEmailVerification)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.SetEmailVerified(userToken.UserID, time.Now()); err != nil {
+		return fmt.Errorf("error verifying email in DB: %v", err)
+	}
+
+	return nil
+}
+
+// ResendVerification envía un link de verificación nuevo a un usuario sin verificar
+func (s *userService) ResendVerification(userID int64) error {
+	user, err := s.GetUserById(userID)
+	if err != nil {
+		return err
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return errors.New("email is already verified")
+	}
+
+	return s.sendVerification(user)
+}
+
+// MarkEmailVerified verifica manualmente el email de un usuario
+func (s *userService) MarkEmailVerified(userID int64, actor domain.Actor) error {
+	user, err := s.GetUserById(userID)
+	if err != nil {
+		return err
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	if err := s.repo.SetEmailVerified(user.Id, now); err != nil {
+		return fmt.Errorf("error verifying email in DB: %v", err)
+	}
+
+	// Los links pendientes dejan de servir
+	if err := s.repo.InvalidateUserTokens(user.Id, domain.TokenPurposeEmailVerification, now); err != nil {
+		return fmt.Errorf("error invalidating verification tokens in DB: %v", err)
+	}
+
+	s.auditUser(actor, domain.AuditUserEmailVerified, user.Id)
+	return nil
+}
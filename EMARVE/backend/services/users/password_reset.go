@@ -0,0 +1,133 @@
+package users
+
+import (
+	"backend/domain"
+	"backend/interfaces"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// WithMailer reemplaza el mailer por defecto, que descarta los emails
+func WithMailer(mailer interfaces.MailerInterface) Option {
+	return func(s *userService) {
+		s.mailer = mailer
+	}
+}
+
+// WithAppBaseURL define la URL del frontend usada en los links de los emails
+func WithAppBaseURL(baseURL string) Option {
+	return func(s *userService) {
+		s.appBaseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// ForgotPassword envía un link de recuperación al email indicado. Si el email no está
+// registrado no devuelve error, para no revelar qué cuentas existen.
+func (s *userService) ForgotPassword(email string) error {
+	if strings.TrimSpace(email) == "" {
+		return errors.New("email is required")
+	}
+
+	user, err := s.repo.GetUserByEmail(email)
+	if err != nil {
+		log.Infof("password reset requested for unknown email: %v", err)
+		return nil
+	}
+
+	return s.sendPasswordReset(user)
+}
+
+// sendPasswordReset invalida los links anteriores y envía uno nuevo al email del usuario
+func (s *userService) sendPasswordReset(user *domain.User) error {
+	now := time.Now()
+
+	// Solo el último link enviado es válido
+	if err := s.repo.InvalidateUserTokens(user.Id, domain.TokenPurposePasswordReset, now); err != nil {
+		return fmt.Errorf("error invalidating previous reset tokens in DB: %v", err)
+	}
+
+	token, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.CreateUserToken(domain.UserToken{
+		UserID:    user.Id,
+		Purpose:   domain.TokenPurposePasswordReset,
+		TokenHash: tokenHash,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.tokenConfig.PasswordResetTTL),
+	})
+	if err != nil {
+		return fmt.Errorf("error creating reset token in DB: %v", err)
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", s.appBaseURL, url.QueryEscape(token))
+	err = s.mailer.Send(domain.Email{
+		To:      user.Email,
+		Subject: "Restablecer tu contraseña de EMARVE",
+		Body: fmt.Sprintf("Hola %s,\n\nPara elegir una contraseña nueva entrá a este link:\n\n%s\n\nEl link vence en %s. Si no pediste el cambio, ignorá este email.\n",
+			user.Nickname, link, s.tokenConfig.PasswordResetTTL),
+	})
+	if err != nil {
+		return fmt.Errorf("error sending reset email: %v", err)
+	}
+
+	return nil
+}
+
+// ResetPassword cambia la contraseña usando un token de recuperación. El token se consume
+// y se cierran todas las sesiones abiertas del usuario.
+func (s *userService) ResetPassword(token string, newPassword string) error {
+	if strings.TrimSpace(token) == "" {
+		return errors.New("token is required")
+	}
+
+	if strings.TrimSpace(newPassword) == "" {
+		return errors.New("password is required")
+	}
+
+	userToken, err := s.useToken(token, domain.TokenPurposePasswordReset)
+	if err != nil {
+		return err
+	}
+
+	hash, err := s.hasher.Hash(newPassword)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.UpdatePasswordHash(userToken.UserID, hash); err != nil {
+		return fmt.Errorf("error updating password in DB: %v", err)
+	}
+
+	if err := s.repo.RevokeUserSessions(userToken.UserID, time.Now()); err != nil {
+		return fmt.Errorf("error revoking sessions in DB: %v", err)
+	}
+
+	s.auditUser(domain.Actor{UserID: userToken.UserID}, domain.AuditPasswordReset, userToken.UserID)
+	return nil
+}
+
+// useToken valida un token de un solo uso con el propósito indicado y lo marca como usado
+func (s *userService) useToken(token string, purpose string) (*domain.UserToken, error) {
+	userToken, err := s.repo.GetUserTokenByHash(hashToken(token))
+	if err != nil || userToken.Purpose != purpose {
+		return nil, errors.New("invalid or expired token")
+	}
+
+	if userToken.UsedAt != nil || time.Now().After(userToken.ExpiresAt) {
+		return nil, errors.New("invalid or expired token")
+	}
+
+	if err := s.repo.ConsumeUserToken(userToken.Id, time.Now()); err != nil {
+		return nil, errors.New("invalid or expired token")
+	}
+
+	return userToken, nil
+}
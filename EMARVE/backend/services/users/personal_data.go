@@ -0,0 +1,178 @@
+package users
+
+import (
+	"archive/zip"
+	"backend/domain"
+	"backend/services/audit"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// authorizeAccount permite operar sobre la cuenta solo a su dueño y a quien puede gestionar
+// usuarios, igual que middleware.CanAccessUser
+func authorizeAccount(userID int64, actor domain.Actor) error {
+	if actor.UserID != userID && !actor.HasPermission(domain.PermUserManage) {
+		return domain.ErrAccountForbidden
+	}
+	return nil
+}
+
+// ExportUserData junta el perfil, las suscripciones, los comentarios, los archivos y las
+// sesiones activas del usuario
+func (s *userService) ExportUserData(userID int64, actor domain.Actor) (domain.UserExport, error) {
+	if err := authorizeAccount(userID, actor); err != nil {
+		return domain.UserExport{}, err
+	}
+
+	user, err := s.GetUserById(userID)
+	if err != nil {
+		return domain.UserExport{}, err
+	}
+
+	twoFactor, err := s.repo.GetTwoFactor(user.Id)
+	if err != nil {
+		return domain.UserExport{}, fmt.Errorf("error getting two-factor settings from DB: %v", err)
+	}
+
+	subscriptions, err := s.SubscriptionList(user.Id)
+	if err != nil {
+		return domain.UserExport{}, err
+	}
+
+	comments, err := s.repo.GetCommentsByUserId(user.Id)
+	if err != nil {
+		return domain.UserExport{}, fmt.Errorf("error getting comments from DB: %v", err)
+	}
+
+	files, err := s.repo.GetFilesByUserId(user.Id)
+	if err != nil {
+		return domain.UserExport{}, fmt.Errorf("error getting files from DB: %v", err)
+	}
+
+	sessions, err := s.repo.GetActiveSessionsByUserId(user.Id)
+	if err != nil {
+		return domain.UserExport{}, fmt.Errorf("error getting sessions from DB: %v", err)
+	}
+
+	export := domain.UserExport{
+		ExportedAt: time.Now().UTC(),
+		Profile: domain.UserExportProfile{
+			Id:               user.Id,
+			Nickname:         user.Nickname,
+			Email:            user.Email,
+			PendingEmail:     user.PendingEmail,
+			EmailVerifiedAt:  user.EmailVerifiedAt,
+			Roles:            user.Roles,
+			TwoFactorEnabled: twoFactor != nil && twoFactor.EnabledAt != nil,
+		},
+		Subscriptions: subscriptions,
+		Comments:      nonNil(comments),
+		Files:         nonNil(files),
+		Sessions:      nonNil(sessions),
+	}
+
+	s.auditUser(actor, domain.AuditUserExported, user.Id)
+	return export, nil
+}
+
+// WriteExportArchive escribe un zip con export.json y una copia de los archivos subidos
+// por el usuario. Los archivos que ya no están en disco se omiten.
+func (s *userService) WriteExportArchive(export domain.UserExport, w io.Writer) error {
+	archive := zip.NewWriter(w)
+
+	entry, err := archive.Create("export.json")
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(entry)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(export); err != nil {
+		return err
+	}
+
+	for _, file := range export.Files {
+		if err := s.addUploadToArchive(archive, file); err != nil {
+			log.Warnf("error adding file %d to export of user %d: %v", file.Id, export.Profile.Id, err)
+		}
+	}
+
+	return archive.Close()
+}
+
+func (s *userService) addUploadToArchive(archive *zip.Writer, file domain.File) error {
+	source, err := os.Open(s.uploadPath(file))
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	entry, err := archive.Create(fmt.Sprintf("files/%d-%s", file.Id, filepath.Base(file.Name)))
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(entry, source)
+	return err
+}
+
+// uploadPath ubica el archivo en el directorio de uploads sin confiar en la ruta guardada
+func (s *userService) uploadPath(file domain.File) string {
+	return filepath.Join(s.uploadsDir, filepath.Base(file.Url))
+}
+
+// DeleteUser elimina la cuenta y sus datos personales. Los comentarios y el material subido a
+// los cursos se conservan anónimos. No se puede borrar al único owner de un curso: antes hay
+// que sumar otro owner. Quien borra su propia cuenta debe confirmar la contraseña.
+func (s *userService) DeleteUser(userID int64, currentPassword string, actor domain.Actor) error {
+	if err := authorizeAccount(userID, actor); err != nil {
+		return err
+	}
+
+	user, err := s.GetUserById(userID)
+	if err != nil {
+		return err
+	}
+
+	if actor.UserID == user.Id {
+		if currentPassword == "" {
+			return errors.New("current_password is required to delete the account")
+		}
+		valid, err := s.hasher.Verify(currentPassword, user.PasswordHash)
+		if err != nil || !valid {
+			return domain.ErrInvalidCurrentPassword
+		}
+	}
+
+	owned, err := s.repo.GetSoleOwnedCourseIDs(user.Id)
+	if err != nil {
+		return fmt.Errorf("error getting course staff from DB: %v", err)
+	}
+	if len(owned) > 0 {
+		return fmt.Errorf("%w: add another owner to courses %v first", domain.ErrLastCourseOwner, owned)
+	}
+
+	if err := s.repo.DeleteUser(user.Id); err != nil {
+		return fmt.Errorf("error deleting user from DB: %v", err)
+	}
+
+	// El evento solo guarda el id de la cuenta eliminada. Los eventos anteriores sobre ella
+	// quedaron sin datos personales al borrarla.
+	event := audit.NewEvent(actor, domain.AuditUserDeleted, domain.AuditTargetUser, user.Id)
+	s.auditLog.Record(event)
+
+	return nil
+}
+
+// nonNil devuelve una lista vacía en lugar de nil para que el JSON tenga [] y no null
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return make([]T, 0)
+	}
+	return items
+}
@@ -0,0 +1,218 @@
+package users
+
+import (
+	"backend/domain"
+	"backend/services/audit"
+	"errors"
+	"fmt"
+	netmail "net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// profileSnapshot son los datos del perfil que quedan en el log de auditoría
+type profileSnapshot struct {
+	Nickname     string  `json:"nickname"`
+	Email        string  `json:"email"`
+	PendingEmail *string `json:"pending_email,omitempty"`
+}
+
+// UpdateUser actualiza el nickname, el email y la contraseña de una cuenta. Solo puede
+// hacerlo el dueño o un admin. Se valida todo antes de guardar cualquier cambio.
+func (s *userService) UpdateUser(userID int64, request domain.UpdateUserRequest, actor domain.Actor) (*domain.User, error) {
+	if err := authorizeAccount(userID, actor); err != nil {
+		return nil, err
+	}
+
+	user, err := s.GetUserById(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	nickname := strings.TrimSpace(request.Nickname)
+	email := strings.TrimSpace(request.Email)
+	changeNickname := nickname != "" && nickname != user.Nickname
+	changeEmail := email != "" && !strings.EqualFold(email, user.Email)
+	changePassword := request.Password != ""
+
+	if !changeNickname && !changeEmail && !changePassword {
+		return nil, errors.New("nothing to update")
+	}
+
+	if changeNickname {
+		taken, err := s.repo.IsNicknameTaken(nickname, user.Id)
+		if err != nil {
+			return nil, fmt.Errorf("error checking nickname in DB: %v", err)
+		}
+		if taken {
+			return nil, domain.ErrNicknameTaken
+		}
+	}
+
+	if changeEmail {
+		if address, err := netmail.ParseAddress(email); err != nil || address.Address != email {
+			return nil, errors.New("invalid email address")
+		}
+		taken, err := s.repo.IsEmailTaken(email, user.Id)
+		if err != nil {
+			return nil, fmt.Errorf("error checking email in DB: %v", err)
+		}
+		if taken {
+			return nil, domain.ErrEmailTaken
+		}
+	}
+
+	var passwordHash string
+	if changePassword {
+		if strings.TrimSpace(request.Password) == "" {
+			return nil, errors.New("password is required")
+		}
+		if request.CurrentPassword == "" {
+			return nil, errors.New("current_password is required to change the password")
+		}
+		valid, err := s.hasher.Verify(request.CurrentPassword, user.PasswordHash)
+		if err != nil || !valid {
+			return nil, domain.ErrInvalidCurrentPassword
+		}
+		passwordHash, err = s.hasher.Hash(request.Password)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	before := profileSnapshot{Nickname: user.Nickname, Email: user.Email, PendingEmail: user.PendingEmail}
+	changed := make([]string, 0, 3)
+
+	if changeNickname {
+		if err := s.repo.UpdateNickname(user.Id, nickname); err != nil {
+			return nil, fmt.Errorf("error updating nickname in DB: %v", err)
+		}
+		user.Nickname = nickname
+		changed = append(changed, "nickname")
+	}
+
+	if changePassword {
+		if err := s.repo.UpdatePasswordHash(user.Id, passwordHash); err != nil {
+			return nil, fmt.Errorf("error updating password in DB: %v", err)
+		}
+		// Igual que al restablecerla, la contraseña nueva cierra todas las sesiones
+		if err := s.repo.RevokeUserSessions(user.Id, time.Now()); err != nil {
+			return nil, fmt.Errorf("error revoking sessions in DB: %v", err)
+		}
+		changed = append(changed, "password")
+	}
+
+	if changeEmail {
+		if err := s.requestEmailChange(user, email); err != nil {
+			return nil, err
+		}
+		user.PendingEmail = &email
+		changed = append(changed, "email (pending confirmation)")
+	}
+
+	event := audit.NewEvent(actor, domain.AuditUserUpdated, domain.AuditTargetUser, user.Id)
+	event.Details = strings.Join(changed, ", ")
+	event.Before = audit.Snapshot(before)
+	event.After = audit.Snapshot(profileSnapshot{Nickname: user.Nickname, Email: user.Email, PendingEmail: user.PendingEmail})
+	s.auditLog.Record(event)
+
+	return user, nil
+}
+
+// requestEmailChange guarda el email nuevo como pendiente y envía el link de confirmación
+// a esa dirección. El email actual sigue vigente hasta que se confirme.
+func (s *userService) requestEmailChange(user *domain.User, email string) error {
+	now := time.Now()
+
+	if err := s.repo.SetPendingEmail(user.Id, email); err != nil {
+		return fmt.Errorf("error saving pending email in DB: %v", err)
+	}
+
+	if err := s.repo.InvalidateUserTokens(user.Id, domain.TokenPurposeEmailChange, now); err != nil {
+		return fmt.Errorf("error invalidating previous email change tokens in DB: %v", err)
+	}
+
+	token, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.CreateUserToken(domain.UserToken{
+		UserID:    user.Id,
+		Purpose:   domain.TokenPurposeEmailChange,
+		TokenHash: tokenHash,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.tokenConfig.EmailVerificationTTL),
+	})
+	if err != nil {
+		return fmt.Errorf("error creating email change token in DB: %v", err)
+	}
+
+	link := fmt.Sprintf("%s/users/email/confirm?token=%s", s.apiBaseURL, url.QueryEscape(token))
+	err = s.mailer.Send(domain.Email{
+		To:      email,
+		Subject: "Confirmá tu nuevo email de EMARVE",
+		Body: fmt.Sprintf("Hola %s,\n\nPara usar esta dirección en tu cuenta entrá a este link:\n\n%s\n\nEl link vence en %s.\n",
+			user.Nickname, link, s.tokenConfig.EmailVerificationTTL),
+	})
+	if err != nil {
+		return fmt.Errorf("error sending email change confirmation: %v", err)
+	}
+
+	// Aviso a la dirección actual por si el cambio no lo pidió el dueño de la cuenta
+	err = s.mailer.Send(domain.Email{
+		To:      user.Email,
+		Subject: "Cambio de email en EMARVE",
+		Body: fmt.Sprintf("Hola %s,\n\nSe pidió cambiar el email de tu cuenta a %s. Si no fuiste vos, cambiá tu contraseña.\n",
+			user.Nickname, email),
+	})
+	if err != nil {
+		log.Warnf("error notifying user %d about email change: %v", user.Id, err)
+	}
+
+	return nil
+}
+
+// ConfirmEmailChange aplica el email pendiente asociado a un token de confirmación
+func (s *userService) ConfirmEmailChange(token string) error {
+	if strings.TrimSpace(token) == "" {
+		return errors.New("token is required")
+	}
+
+	userToken, err := s.useToken(token, domain.TokenPurposeEmailChange)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.GetUserById(userToken.UserID)
+	if err != nil {
+		return err
+	}
+	if user.PendingEmail == nil {
+		return errors.New("there is no pending email change")
+	}
+	email := *user.PendingEmail
+
+	// Otro usuario pudo registrar la dirección mientras el cambio estaba pendiente
+	taken, err := s.repo.IsEmailTaken(email, user.Id)
+	if err != nil {
+		return fmt.Errorf("error checking email in DB: %v", err)
+	}
+	if taken {
+		return domain.ErrEmailTaken
+	}
+
+	if err := s.repo.ConfirmEmailChange(user.Id, email, time.Now()); err != nil {
+		return fmt.Errorf("error updating email in DB: %v", err)
+	}
+
+	event := audit.NewEvent(userActor(user, ""), domain.AuditUserEmailChanged, domain.AuditTargetUser, user.Id)
+	event.Before = audit.Snapshot(profileSnapshot{Nickname: user.Nickname, Email: user.Email})
+	event.After = audit.Snapshot(profileSnapshot{Nickname: user.Nickname, Email: email})
+	s.auditLog.Record(event)
+
+	return nil
+}
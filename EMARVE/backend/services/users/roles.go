@@ -0,0 +1,82 @@
+package users
+
+import (
+	"backend/domain"
+	"backend/services/audit"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+func (s *userService) ListRoles() ([]domain.Role, error) {
+	roles, err := s.repo.GetRoles()
+	if err != nil {
+		return nil, fmt.Errorf("error getting roles from DB: %v", err)
+	}
+	return roles, nil
+}
+
+// SetUserRoles reemplaza los roles de un usuario. Si pierde algún rol se cierran sus
+// sesiones, para que los tokens emitidos con los permisos anteriores dejen de valer.
+func (s *userService) SetUserRoles(userID int64, roles []string, actor domain.Actor) error {
+	roles = normalizeRoles(roles)
+	if len(roles) == 0 {
+		return errors.New("at least one role is required")
+	}
+
+	user, err := s.GetUserById(userID)
+	if err != nil {
+		return err
+	}
+
+	if actor.UserID == user.Id && user.HasRole(domain.RoleAdmin) && !containsRole(roles, domain.RoleAdmin) {
+		return errors.New("admins cannot remove their own admin role")
+	}
+
+	if err := s.repo.SetUserRoles(user.Id, roles); err != nil {
+		return fmt.Errorf("error updating roles in DB: %v", err)
+	}
+
+	for _, previous := range user.Roles {
+		if !containsRole(roles, previous) {
+			if err := s.repo.RevokeUserSessions(user.Id, time.Now()); err != nil {
+				return fmt.Errorf("error revoking sessions in DB: %v", err)
+			}
+			break
+		}
+	}
+
+	event := audit.NewEvent(actor, domain.AuditUserRolesChanged, domain.AuditTargetUser, user.Id)
+	event.Before = audit.Snapshot(user.Roles)
+	event.After = audit.Snapshot(roles)
+	s.auditLog.Record(event)
+
+	return nil
+}
+
+// normalizeRoles elimina espacios y duplicados y ordena los nombres
+func normalizeRoles(roles []string) []string {
+	seen := make(map[string]bool)
+	result := make([]string, 0, len(roles))
+	for _, role := range roles {
+		role = strings.ToLower(strings.TrimSpace(role))
+		if role == "" || seen[role] {
+			continue
+		}
+		seen[role] = true
+		result = append(result, role)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func containsRole(roles []string, role string) bool {
+	for _, candidate := range roles {
+		if candidate == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,351 @@
+package users
+
+import (
+	"backend/domain"
+	"backend/interfaces"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt"
+)
+
+// TokenConfig define la duración de los access tokens, de las sesiones (refresh tokens)
+// y de los links que se envían por email
+type TokenConfig struct {
+	AccessTTL            time.Duration
+	RefreshTTL           time.Duration
+	PasswordResetTTL     time.Duration
+	EmailVerificationTTL time.Duration
+}
+
+func DefaultTokenConfig() TokenConfig {
+	return TokenConfig{
+		AccessTTL:            15 * time.Minute,
+		RefreshTTL:           7 * 24 * time.Hour,
+		PasswordResetTTL:     30 * time.Minute,
+		EmailVerificationTTL: 48 * time.Hour,
+	}
+}
+
+// TokenConfigFromEnv lee ACCESS_TOKEN_TTL, REFRESH_TOKEN_TTL, PASSWORD_RESET_TTL y
+// EMAIL_VERIFICATION_TTL (por ejemplo "15m", "168h")
+func TokenConfigFromEnv() TokenConfig {
+	config := DefaultTokenConfig()
+	if ttl, err := time.ParseDuration(os.Getenv("ACCESS_TOKEN_TTL")); err == nil && ttl > 0 {
+		config.AccessTTL = ttl
+	}
+	if ttl, err := time.ParseDuration(os.Getenv("REFRESH_TOKEN_TTL")); err == nil && ttl > 0 {
+		config.RefreshTTL = ttl
+	}
+	if ttl, err := time.ParseDuration(os.Getenv("PASSWORD_RESET_TTL")); err == nil && ttl > 0 {
+		config.PasswordResetTTL = ttl
+	}
+	if ttl, err := time.ParseDuration(os.Getenv("EMAIL_VERIFICATION_TTL")); err == nil && ttl > 0 {
+		config.EmailVerificationTTL = ttl
+	}
+	return config
+}
+
+// WithTokenSigner reemplaza la clave efímera por defecto por las claves configuradas
+func WithTokenSigner(signer interfaces.TokenSignerInterface) Option {
+	return func(s *userService) {
+		s.signer = signer
+	}
+}
+
+// WithTokenConfig reemplaza la duración por defecto de los tokens
+func WithTokenConfig(config TokenConfig) Option {
+	return func(s *userService) {
+		s.tokenConfig = config
+	}
+}
+
+// startSession crea una sesión nueva para el usuario y emite su par de tokens
+func (s *userService) startSession(user *domain.User, client domain.ClientInfo) (domain.LoginResponse, error) {
+	refreshToken, refreshHash, err := newOpaqueToken()
+	if err != nil {
+		return domain.LoginResponse{}, err
+	}
+
+	now := time.Now()
+	session, err := s.repo.CreateSession(domain.Session{
+		UserID:           user.Id,
+		RefreshTokenHash: refreshHash,
+		IP:               client.IP,
+		UserAgent:        client.UserAgent,
+		CreatedAt:        now,
+		LastUsedAt:       now,
+		ExpiresAt:        now.Add(s.tokenConfig.RefreshTTL),
+	})
+	if err != nil {
+		return domain.LoginResponse{}, fmt.Errorf("error creating session in DB: %v", err)
+	}
+
+	return s.issueTokens(user, session.Id, refreshToken)
+}
+
+func (s *userService) issueTokens(user *domain.User, sessionID int64, refreshToken string) (domain.LoginResponse, error) {
+	tokenID, err := randomHex(16)
+	if err != nil {
+		return domain.LoginResponse{}, err
+	}
+
+	now := time.Now()
+	// type se mantiene para los servicios que todavía no leen roles
+	tokenString, err := s.signer.Sign(map[string]interface{}{
+		"userID": user.Id,
+		"type":   user.HasRole(domain.RoleAdmin),
+		"roles":  user.Roles,
+		"perms":  user.Permissions,
+		"sid":    sessionID,
+		"jti":    tokenID,
+		"iat":    now.Unix(),
+		"exp":    now.Add(s.tokenConfig.AccessTTL).Unix(),
+	})
+	if err != nil {
+		return domain.LoginResponse{}, err
+	}
+
+	return domain.LoginResponse{
+		Token:        tokenString,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(s.tokenConfig.AccessTTL.Seconds()),
+	}, nil
+}
+
+// RefreshToken rota el refresh token de la sesión y emite un access token nuevo. Si se
+// presenta un refresh token que ya fue rotado, alguien más lo tiene: se revoca la sesión.
+func (s *userService) RefreshToken(refreshToken string, client domain.ClientInfo) (domain.LoginResponse, error) {
+	if strings.TrimSpace(refreshToken) == "" {
+		return domain.LoginResponse{}, errors.New("refresh token is required")
+	}
+
+	refreshHash := hashToken(refreshToken)
+	session, err := s.repo.GetSessionByRefreshHash(refreshHash)
+	if err != nil {
+		return domain.LoginResponse{}, errors.New("invalid refresh token")
+	}
+
+	if err := checkSession(session); err != nil {
+		return domain.LoginResponse{}, err
+	}
+
+	if session.RefreshTokenHash != refreshHash {
+		return domain.LoginResponse{}, s.revokeReusedSession(session.Id)
+	}
+
+	user, err := s.repo.GetUserById(session.UserID)
+	if err != nil {
+		return domain.LoginResponse{}, fmt.Errorf("error getting user from DB: %v", err)
+	}
+
+	if err := checkAccountUsable(user); err != nil {
+		return domain.LoginResponse{}, err
+	}
+
+	newRefreshToken, newRefreshHash, err := newOpaqueToken()
+	if err != nil {
+		return domain.LoginResponse{}, err
+	}
+
+	if err := s.repo.RotateSessionRefresh(session.Id, refreshHash, newRefreshHash, time.Now()); err != nil {
+		if errors.Is(err, domain.ErrRefreshTokenReused) {
+			return domain.LoginResponse{}, s.revokeReusedSession(session.Id)
+		}
+		return domain.LoginResponse{}, fmt.Errorf("error updating session in DB: %v", err)
+	}
+
+	return s.issueTokens(user, session.Id, newRefreshToken)
+}
+
+// revokeReusedSession revoca la sesión cuyo refresh token se usó más de una vez
+func (s *userService) revokeReusedSession(sessionID int64) error {
+	if err := s.repo.RevokeSession(sessionID, time.Now()); err != nil {
+		return fmt.Errorf("error revoking session in DB: %v", err)
+	}
+	return domain.ErrRefreshTokenReused
+}
+
+// Logout revoca la sesión a la que pertenece el token, invalidando también su refresh token
+func (s *userService) Logout(sessionID int64) error {
+	if sessionID <= 0 {
+		return errors.New("invalid session ID")
+	}
+
+	if err := s.repo.RevokeSession(sessionID, time.Now()); err != nil {
+		return fmt.Errorf("error revoking session in DB: %v", err)
+	}
+
+	return nil
+}
+
+func (s *userService) ListSessions(userID int64, currentSessionID int64) ([]domain.SessionResponse, error) {
+	sessions, err := s.repo.GetActiveSessionsByUserId(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting sessions from DB: %v", err)
+	}
+
+	results := make([]domain.SessionResponse, 0)
+
+	for _, session := range sessions {
+		results = append(results, domain.SessionResponse{
+			Id:         session.Id,
+			IP:         session.IP,
+			UserAgent:  session.UserAgent,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.Id == currentSessionID,
+		})
+	}
+
+	return results, nil
+}
+
+// RevokeSession revoca una sesión del usuario. Las sesiones de otros usuarios se informan como inexistentes.
+func (s *userService) RevokeSession(userID int64, sessionID int64) error {
+	session, err := s.repo.GetSessionById(sessionID)
+	if err != nil || session.UserID != userID {
+		return fmt.Errorf("session %d not found", sessionID)
+	}
+
+	if err := s.repo.RevokeSession(sessionID, time.Now()); err != nil {
+		return fmt.Errorf("error revoking session in DB: %v", err)
+	}
+
+	return nil
+}
+
+// ValidateToken verifica la firma y la expiración del Bearer token, que su sesión siga activa
+// y devuelve el usuario, sus roles y sus permisos. Los roles son los vigentes al emitir el token:
+// un cambio se refleja al renovarlo con el refresh token.
+func (s *userService) ValidateToken(tokenString string) (domain.TokenClaims, error) {
+	tokenString = strings.TrimPrefix(tokenString, "Bearer ")
+	if tokenString == "" {
+		return domain.TokenClaims{}, fmt.Errorf("bearer token is required")
+	}
+
+	verified, err := s.signer.Verify(tokenString)
+	if err != nil {
+		return domain.TokenClaims{}, err
+	}
+	claims := jwt.MapClaims(verified)
+
+	// Los mfa_token del login en dos pasos no sirven como access token
+	if _, ok := claims["purpose"]; ok {
+		return domain.TokenClaims{}, fmt.Errorf("invalid token payload")
+	}
+
+	// Los tokens sin expiración o sin sesión (emitidos antes de las sesiones) ya no se aceptan
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return domain.TokenClaims{}, fmt.Errorf("token has no valid expiration")
+	}
+
+	userID, ok := claims["userID"].(float64)
+	if !ok {
+		return domain.TokenClaims{}, fmt.Errorf("invalid token payload")
+	}
+
+	roles, ok := stringList(claims["roles"])
+	if !ok {
+		return domain.TokenClaims{}, fmt.Errorf("invalid token payload")
+	}
+
+	permissions, ok := stringList(claims["perms"])
+	if !ok {
+		return domain.TokenClaims{}, fmt.Errorf("invalid token payload")
+	}
+
+	sessionID, ok := claims["sid"].(float64)
+	if !ok {
+		return domain.TokenClaims{}, fmt.Errorf("invalid token payload")
+	}
+
+	tokenID, _ := claims["jti"].(string)
+
+	session, err := s.repo.GetSessionById(int64(sessionID))
+	if err != nil {
+		return domain.TokenClaims{}, fmt.Errorf("session not found")
+	}
+	if err := checkSession(session); err != nil {
+		return domain.TokenClaims{}, err
+	}
+
+	return domain.TokenClaims{
+		UserID:      int64(userID),
+		Role:        domain.PrimaryRole(roles),
+		Roles:       roles,
+		Permissions: permissions,
+		SessionID:   int64(sessionID),
+		TokenID:     tokenID,
+	}, nil
+}
+
+// stringList convierte un claim de tipo lista de strings. Una lista vacía se serializa como
+// null cuando el usuario no tiene roles o permisos.
+func stringList(claim interface{}) ([]string, bool) {
+	if claim == nil {
+		return make([]string, 0), true
+	}
+	values, ok := claim.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		text, ok := value.(string)
+		if !ok {
+			return nil, false
+		}
+		result = append(result, text)
+	}
+	return result, true
+}
+
+// JWKS publica las claves públicas con las que otros servicios pueden verificar los tokens
+func (s *userService) JWKS() domain.JWKSet {
+	return s.signer.JWKS()
+}
+
+func checkSession(session *domain.Session) error {
+	if session.RevokedAt != nil {
+		return errors.New("session has been revoked")
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return errors.New("session has expired")
+	}
+	return nil
+}
+
+// newOpaqueToken genera un token aleatorio y el hash con el que se guarda en la base de datos
+func newOpaqueToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("error generating token: %v", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashToken(token), nil
+}
+
+// hashToken devuelve el SHA-256 en hex de un token opaco. Los tokens tienen 256 bits
+// de entropía, así que no hace falta un hash lento como para las contraseñas.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("error generating random ID: %v", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
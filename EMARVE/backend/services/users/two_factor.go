@@ -0,0 +1,318 @@
+package users
+
+import (
+	"backend/domain"
+	"backend/services/totp"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt"
+)
+
+const (
+	totpIssuer        = "EMARVE"
+	mfaTokenPurpose   = "mfa"
+	mfaTokenTTL       = 5 * time.Minute
+	recoveryCodeCount = 10
+)
+
+// Admin2FARequiredFromEnv lee ADMIN_2FA_REQUIRED
+func Admin2FARequiredFromEnv() bool {
+	value := strings.ToLower(os.Getenv("ADMIN_2FA_REQUIRED"))
+	return value == "true" || value == "1"
+}
+
+// WithAdmin2FARequired obliga a los admins a usar 2FA. Un admin sin 2FA debe inscribirse
+// durante el login para poder obtener una sesión.
+func WithAdmin2FARequired(required bool) Option {
+	return func(s *userService) {
+		s.admin2FARequired = required
+	}
+}
+
+// secondFactorChallenge decide si el login necesita un segundo paso y, en ese caso,
+// devuelve la respuesta con el mfa_token en lugar de los tokens de sesión
+func (s *userService) secondFactorChallenge(user *domain.User) (*domain.LoginResponse, error) {
+	twoFactor, err := s.repo.GetTwoFactor(user.Id)
+	if err != nil {
+		return nil, fmt.Errorf("error getting two-factor settings from DB: %v", err)
+	}
+
+	enabled := twoFactor != nil && twoFactor.EnabledAt != nil
+	mustEnroll := !enabled && user.HasRole(domain.RoleAdmin) && s.admin2FARequired
+	if !enabled && !mustEnroll {
+		return nil, nil
+	}
+
+	mfaToken, err := s.issueMFAToken(user.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.LoginResponse{
+		MFARequired:           true,
+		MFAEnrollmentRequired: mustEnroll,
+		MFAToken:              mfaToken,
+		EmailVerified:         user.EmailVerifiedAt != nil,
+	}, nil
+}
+
+// issueMFAToken firma un token de corta duración que solo sirve para completar el login
+func (s *userService) issueMFAToken(userID int64) (string, error) {
+	tokenID, err := randomHex(16)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	return s.signer.Sign(map[string]interface{}{
+		"userID":  userID,
+		"purpose": mfaTokenPurpose,
+		"jti":     tokenID,
+		"iat":     now.Unix(),
+		"exp":     now.Add(mfaTokenTTL).Unix(),
+	})
+}
+
+func (s *userService) parseMFAToken(mfaToken string) (int64, error) {
+	verified, err := s.signer.Verify(mfaToken)
+	if err != nil {
+		return 0, errors.New("invalid or expired MFA token")
+	}
+	claims := jwt.MapClaims(verified)
+
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) || claims["purpose"] != mfaTokenPurpose {
+		return 0, errors.New("invalid or expired MFA token")
+	}
+
+	userID, ok := claims["userID"].(float64)
+	if !ok {
+		return 0, errors.New("invalid or expired MFA token")
+	}
+	return int64(userID), nil
+}
+
+// CompleteMFALogin canjea el mfa_token y un código TOTP (o de recuperación) por una sesión.
+// Si la inscripción estaba pendiente, el código la confirma y la respuesta incluye los
+// códigos de recuperación.
+func (s *userService) CompleteMFALogin(mfaToken string, code string, client domain.ClientInfo) (domain.LoginResponse, error) {
+	if strings.TrimSpace(code) == "" {
+		return domain.LoginResponse{}, errors.New("code is required")
+	}
+
+	userID, err := s.parseMFAToken(mfaToken)
+	if err != nil {
+		return domain.LoginResponse{}, err
+	}
+
+	user, err := s.repo.GetUserById(userID)
+	if err != nil {
+		return domain.LoginResponse{}, fmt.Errorf("error getting user from DB: %v", err)
+	}
+
+	if err := s.loginLimiter.Check(user.Email, client.IP); err != nil {
+		return domain.LoginResponse{}, err
+	}
+
+	// La cuenta pudo deshabilitarse entre los dos pasos del login
+	if err := checkAccountUsable(user); err != nil {
+		return domain.LoginResponse{}, err
+	}
+
+	twoFactor, err := s.repo.GetTwoFactor(user.Id)
+	if err != nil {
+		return domain.LoginResponse{}, fmt.Errorf("error getting two-factor settings from DB: %v", err)
+	}
+	if twoFactor == nil {
+		return domain.LoginResponse{}, errors.New("two-factor enrollment has not been started")
+	}
+
+	var recoveryCodes []string
+	if twoFactor.EnabledAt == nil {
+		recoveryCodes, err = s.enableTwoFactor(twoFactor, code)
+	} else {
+		err = s.verifySecondFactor(twoFactor, code)
+	}
+	if err != nil {
+		s.loginLimiter.RecordFailure(user.Email, client.IP)
+		s.auditLoginFailed(user, user.Email, client, err.Error())
+		return domain.LoginResponse{}, err
+	}
+
+	s.loginLimiter.RecordSuccess(user.Email)
+
+	response, err := s.startSession(user, client)
+	if err != nil {
+		return domain.LoginResponse{}, err
+	}
+	response.EmailVerified = user.EmailVerifiedAt != nil
+	response.RecoveryCodes = recoveryCodes
+	s.auditUser(userActor(user, client.IP), domain.AuditLoginSucceeded, user.Id)
+
+	return response, nil
+}
+
+// BeginMFAEnrollment permite a un admin sin 2FA inscribirse durante el login, cuando la política lo exige
+func (s *userService) BeginMFAEnrollment(mfaToken string) (domain.TOTPEnrollment, error) {
+	userID, err := s.parseMFAToken(mfaToken)
+	if err != nil {
+		return domain.TOTPEnrollment{}, err
+	}
+	return s.BeginTOTPEnrollment(userID)
+}
+
+// BeginTOTPEnrollment genera un secreto nuevo. No se activa hasta confirmarlo con un código.
+func (s *userService) BeginTOTPEnrollment(userID int64) (domain.TOTPEnrollment, error) {
+	user, err := s.GetUserById(userID)
+	if err != nil {
+		return domain.TOTPEnrollment{}, err
+	}
+
+	twoFactor, err := s.repo.GetTwoFactor(user.Id)
+	if err != nil {
+		return domain.TOTPEnrollment{}, fmt.Errorf("error getting two-factor settings from DB: %v", err)
+	}
+	if twoFactor != nil && twoFactor.EnabledAt != nil {
+		return domain.TOTPEnrollment{}, errors.New("two-factor authentication is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return domain.TOTPEnrollment{}, err
+	}
+
+	err = s.repo.SaveTwoFactor(domain.TwoFactor{
+		UserID:    user.Id,
+		Secret:    secret,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return domain.TOTPEnrollment{}, fmt.Errorf("error saving two-factor settings in DB: %v", err)
+	}
+
+	return domain.TOTPEnrollment{
+		Secret:     secret,
+		OtpauthURI: totp.URI(totpIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTPEnrollment activa el 2FA con el primer código de la app y devuelve los códigos de recuperación
+func (s *userService) ConfirmTOTPEnrollment(userID int64, code string) ([]string, error) {
+	twoFactor, err := s.repo.GetTwoFactor(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting two-factor settings from DB: %v", err)
+	}
+	if twoFactor == nil {
+		return nil, errors.New("two-factor enrollment has not been started")
+	}
+	if twoFactor.EnabledAt != nil {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	return s.enableTwoFactor(twoFactor, code)
+}
+
+// DisableTOTP desactiva el 2FA previa verificación de un código
+func (s *userService) DisableTOTP(userID int64, code string) error {
+	user, err := s.GetUserById(userID)
+	if err != nil {
+		return err
+	}
+
+	if user.HasRole(domain.RoleAdmin) && s.admin2FARequired {
+		return errors.New("two-factor authentication is required for admin accounts")
+	}
+
+	twoFactor, err := s.repo.GetTwoFactor(user.Id)
+	if err != nil {
+		return fmt.Errorf("error getting two-factor settings from DB: %v", err)
+	}
+	if twoFactor == nil || twoFactor.EnabledAt == nil {
+		return errors.New("two-factor authentication is not enabled")
+	}
+
+	if err := s.verifySecondFactor(twoFactor, code); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteTwoFactor(user.Id); err != nil {
+		return fmt.Errorf("error disabling two-factor authentication in DB: %v", err)
+	}
+
+	s.auditUser(userActor(user, ""), domain.AuditTwoFactorDisabled, user.Id)
+	return nil
+}
+
+func (s *userService) enableTwoFactor(twoFactor *domain.TwoFactor, code string) ([]string, error) {
+	counter, ok := totp.Validate(twoFactor.Secret, code, time.Now(), twoFactor.LastCounter)
+	if !ok {
+		return nil, errors.New("invalid authentication code")
+	}
+
+	if err := s.repo.EnableTwoFactor(twoFactor.UserID, counter, time.Now()); err != nil {
+		return nil, fmt.Errorf("error enabling two-factor authentication in DB: %v", err)
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceRecoveryCodes(twoFactor.UserID, hashes); err != nil {
+		return nil, fmt.Errorf("error saving recovery codes in DB: %v", err)
+	}
+
+	s.auditUser(domain.Actor{UserID: twoFactor.UserID}, domain.AuditTwoFactorEnabled, twoFactor.UserID)
+	return codes, nil
+}
+
+// verifySecondFactor acepta un código TOTP no usado o un código de recuperación
+func (s *userService) verifySecondFactor(twoFactor *domain.TwoFactor, code string) error {
+	if counter, ok := totp.Validate(twoFactor.Secret, code, time.Now(), twoFactor.LastCounter); ok {
+		if err := s.repo.UpdateTwoFactorCounter(twoFactor.UserID, counter); err != nil {
+			return errors.New("invalid authentication code")
+		}
+		return nil
+	}
+
+	normalized := normalizeRecoveryCode(code)
+	if len(normalized) != recoveryCodeLength {
+		return errors.New("invalid authentication code")
+	}
+	if err := s.repo.UseRecoveryCode(twoFactor.UserID, hashToken(normalized), time.Now()); err != nil {
+		return errors.New("invalid authentication code")
+	}
+	return nil
+}
+
+// Los códigos de recuperación tienen 80 bits, en base32 y agrupados de a 4: abcd-efgh-ijkl-mnop
+const recoveryCodeLength = 16
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		buf := make([]byte, 10)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, fmt.Errorf("error generating recovery code: %v", err)
+		}
+		raw := strings.ToLower(recoveryEncoding.EncodeToString(buf))
+		codes = append(codes, fmt.Sprintf("%s-%s-%s-%s", raw[0:4], raw[4:8], raw[8:12], raw[12:16]))
+		hashes = append(hashes, hashToken(raw))
+	}
+
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
@@ -3,90 +3,228 @@ package users
 import (
 	"backend/domain"
 	"backend/interfaces"
-	"crypto/md5"
+	"backend/services/audit"
+	"backend/services/mail"
+	"backend/services/passwords"
+	"backend/services/signing"
+	"backend/services/throttle"
 	"errors"
 	"fmt"
 	"io"
+	netmail "net/mail"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
-	jwt "github.com/golang-jwt/jwt"
+	log "github.com/sirupsen/logrus"
 )
 
 type userService struct {
-	repo interfaces.UserRepositoryInterface
+	repo        interfaces.UserRepositoryInterface
+	hasher      interfaces.PasswordHasherInterface
+	signer      interfaces.TokenSignerInterface
+	mailer      interfaces.MailerInterface
+	tokenConfig TokenConfig
+	appBaseURL  string
+	apiBaseURL  string
+
+	verificationPolicy string
+	loginLimiter       *throttle.LoginLimiter
+	admin2FARequired   bool
+	auditLog           interfaces.AuditRecorderInterface
+	uploadsDir         string
 }
 
-var jwtKey = []byte("secret_key")
+// Option configura dependencias opcionales del servicio de usuarios
+type Option func(*userService)
 
-func NewUserService(repo interfaces.UserRepositoryInterface) *userService {
-	return &userService{repo: repo}
+// WithPasswordHasher reemplaza el hasher de contraseñas por defecto
+func WithPasswordHasher(hasher interfaces.PasswordHasherInterface) Option {
+	return func(s *userService) {
+		s.hasher = hasher
+	}
+}
+
+// WithLoginLimiter reemplaza el limitador de intentos de login por defecto (en memoria)
+func WithLoginLimiter(limiter *throttle.LoginLimiter) Option {
+	return func(s *userService) {
+		s.loginLimiter = limiter
+	}
+}
+
+// WithUploadsDir cambia el directorio donde se guardan los archivos subidos
+func WithUploadsDir(dir string) Option {
+	return func(s *userService) {
+		s.uploadsDir = dir
+	}
+}
+
+func NewUserService(repo interfaces.UserRepositoryInterface, options ...Option) *userService {
+	s := &userService{
+		repo:        repo,
+		hasher:      passwords.NewDefaultHasher(),
+		mailer:      mail.NewLogMailer(),
+		tokenConfig: DefaultTokenConfig(),
+		appBaseURL:  "http://localhost:3000",
+		apiBaseURL:  "http://localhost:8080",
+
+		verificationPolicy: VerificationEnforce,
+		loginLimiter:       throttle.NewDefaultLoginLimiter(),
+		auditLog:           audit.NewNopRecorder(),
+		uploadsDir:         "uploads",
+	}
+	for _, option := range options {
+		option(s)
+	}
+	if s.signer == nil {
+		s.signer = signing.MustEphemeralKeySet()
+	}
+	return s
 }
 
-func (s *userService) Login(email string, password string) (string, error) {
+func (s *userService) Login(email string, password string, client domain.ClientInfo) (domain.LoginResponse, error) {
 
 	if strings.TrimSpace(email) == "" {
-		return "", errors.New("email is required")
+		return domain.LoginResponse{}, errors.New("email is required")
 	}
 
 	if strings.TrimSpace(password) == "" {
-		return "", errors.New("password is required")
+		return domain.LoginResponse{}, errors.New("password is required")
 	}
 
-	hash := fmt.Sprintf("%x", md5.Sum([]byte(password)))
+	// Mientras la cuenta o la IP están bloqueadas ni siquiera se verifica la contraseña
+	if err := s.loginLimiter.Check(email, client.IP); err != nil {
+		s.auditLoginFailed(nil, email, client, err.Error())
+		return domain.LoginResponse{}, err
+	}
 
 	user, err := s.repo.GetUserByEmail(email)
 	if err != nil {
-		return "", fmt.Errorf("error getting user from DB: %v", err)
+		s.loginLimiter.RecordFailure(email, client.IP)
+		s.auditLoginFailed(nil, email, client, "unknown email")
+		return domain.LoginResponse{}, fmt.Errorf("error getting user from DB: %v", err)
+	}
+
+	valid, err := s.hasher.Verify(password, user.PasswordHash)
+	if err != nil || !valid {
+		s.loginLimiter.RecordFailure(email, client.IP)
+		s.auditLoginFailed(user, email, client, "invalid credentials")
+		return domain.LoginResponse{}, errors.New("invalid credentials")
+	}
+
+	if err := checkAccountUsable(user); err != nil {
+		s.auditLoginFailed(user, email, client, err.Error())
+		return domain.LoginResponse{}, err
 	}
 
-	if hash != user.PasswordHash {
-		return "", errors.New("invalid credentials")
+	verified := user.EmailVerifiedAt != nil
+	if !verified && s.verificationPolicy != VerificationWarn {
+		s.auditLoginFailed(user, email, client, domain.ErrEmailNotVerified.Error())
+		return domain.LoginResponse{}, domain.ErrEmailNotVerified
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"userID": user.Id,
-		"type":   user.Type,
-	})
+	s.rehashIfNeeded(user, password)
 
-	tokenString, err := token.SignedString(jwtKey)
+	challenge, err := s.secondFactorChallenge(user)
 	if err != nil {
-		return "", fmt.Errorf("error signing JWT: %v", err)
+		return domain.LoginResponse{}, err
 	}
+	// Con 2FA el contador se limpia recién al completar el segundo paso, para que conocer
+	// la contraseña no permita reintentar códigos sin límite
+	if challenge != nil {
+		return *challenge, nil
+	}
+	s.loginLimiter.RecordSuccess(email)
+
+	response, err := s.startSession(user, client)
+	if err != nil {
+		return domain.LoginResponse{}, err
+	}
+	response.EmailVerified = verified
+	s.auditUser(userActor(user, client.IP), domain.AuditLoginSucceeded, user.Id)
 
-	return tokenString, nil
+	return response, nil
 }
 
-func (s *userService) UserRegister(nickname string, email string, password string, typeUser bool) (bool, error) {
+// rehashIfNeeded migra el hash almacenado al algoritmo configurado (por ejemplo, desde MD5)
+// después de un login exitoso. Un fallo aquí no impide el login.
+func (s *userService) rehashIfNeeded(user *domain.User, password string) {
+	if !s.hasher.NeedsRehash(user.PasswordHash) {
+		return
+	}
 
-	if strings.TrimSpace(nickname) == "" {
-		return typeUser, errors.New("nickname is required")
+	hash, err := s.hasher.Hash(password)
+	if err != nil {
+		log.Warnf("error rehashing password for user %d: %v", user.Id, err)
+		return
 	}
 
-	if strings.TrimSpace(email) == "" {
-		return typeUser, errors.New("email is required")
+	if err := s.repo.UpdatePasswordHash(user.Id, hash); err != nil {
+		log.Warnf("error updating password hash for user %d: %v", user.Id, err)
+		return
 	}
+	user.PasswordHash = hash
+}
 
-	if strings.TrimSpace(password) == "" {
-		return typeUser, errors.New("password is required")
+// UserRegister crea una cuenta de estudiante. El type del registro se acepta por compatibilidad
+// pero se ignora: los admins solo se crean promoviendo cuentas desde /admin.
+func (s *userService) UserRegister(nickname string, email string, password string, typeUser bool) (bool, error) {
+
+	if err := validateRegistration(nickname, email, password); err != nil {
+		return false, err
 	}
 
-	hash := fmt.Sprintf("%x", md5.Sum([]byte(password)))
+	hash, err := s.hasher.Hash(password)
+	if err != nil {
+		return false, err
+	}
 
 	NewUser := domain.User{
 		Nickname:     nickname,
 		Email:        email,
 		PasswordHash: hash,
-		Type:         typeUser,
+		Roles:        []string{domain.RoleStudent},
 	}
 
-	err := s.repo.CreateUser(NewUser)
+	err = s.repo.CreateUser(NewUser)
 	if err != nil {
-		return typeUser, fmt.Errorf("error creating user from DB: %v", err)
+		return false, fmt.Errorf("error creating user from DB: %v", err)
 	}
 
-	return typeUser, err
+	// La cuenta queda sin verificar hasta que se use el link enviado por email. Si el envío
+	// falla, el registro igual es válido y un admin puede reenviar el link.
+	created, err := s.repo.GetUserByEmail(email)
+	if err != nil {
+		log.Warnf("error getting created user %s to send verification: %v", email, err)
+		return false, nil
+	}
+	if err := s.sendVerification(created); err != nil {
+		log.Warnf("error sending verification to user %d: %v", created.Id, err)
+	}
+
+	return false, nil
+}
+
+// validateRegistration valida los datos de una cuenta nueva, sea del registro o de una importación
+func validateRegistration(nickname string, email string, password string) error {
+	if strings.TrimSpace(nickname) == "" {
+		return errors.New("nickname is required")
+	}
+
+	if strings.TrimSpace(email) == "" {
+		return errors.New("email is required")
+	}
+
+	if address, err := netmail.ParseAddress(email); err != nil || address.Address != email {
+		return errors.New("invalid email address")
+	}
+
+	if strings.TrimSpace(password) == "" {
+		return errors.New("password is required")
+	}
+
+	return nil
 }
 
 func (s *userService) SubscriptionList(UserID int64) ([]domain.Course, error) {
@@ -133,9 +271,13 @@ func (s *userService) AddComment(userID int64, courseID int64, comment string) e
 		return fmt.Errorf("error getting user from DB: %v", err)
 	}
 
-	if _, err := s.repo.GetCourseById(courseID); err != nil {
+	course, err := s.repo.GetCourseById(courseID)
+	if err != nil {
 		return fmt.Errorf("error getting course from DB: %v", err)
 	}
+	if course.Status == domain.CourseArchived {
+		return domain.ErrCourseArchived
+	}
 
 	if err := s.repo.InsertComment(userID, courseID, comment); err != nil {
 		return fmt.Errorf("error inserting comment into DB: %v", err)
@@ -144,32 +286,80 @@ func (s *userService) AddComment(userID int64, courseID int64, comment string) e
 	return nil
 }
 
-func (s *userService) UploadFiles(file io.Reader, filename string, userID int64, courseID int64) error {
-	filePath := fmt.Sprintf("uploads/%s", filename)
+// UploadFiles guarda material del curso. Solo pueden subirlo el staff del curso y los admins.
+// En disco se guarda con un nombre generado; el nombre original queda solo para mostrarlo.
+func (s *userService) UploadFiles(file io.Reader, filename string, userID int64, courseID int64, actor domain.Actor) error {
+	if _, err := s.repo.GetCourseById(courseID); err != nil {
+		return fmt.Errorf("%w: %d", domain.ErrCourseNotFound, courseID)
+	}
+
+	if actor.Role != domain.RoleAdmin {
+		member, err := s.repo.GetCourseStaffMember(courseID, actor.UserID)
+		if err != nil {
+			return fmt.Errorf("error getting course staff from DB: %v", err)
+		}
+		if member == nil {
+			return domain.ErrCourseForbidden
+		}
+	}
+
+	filename = filepath.Base(filename)
+	storedName, err := storedUploadName(filename)
+	if err != nil {
+		return err
+	}
+	filePath := filepath.Join(s.uploadsDir, storedName)
 
 	fileRecord := domain.File{
 		User_Id:    userID,
 		Course_Id:  courseID,
 		Name:       filename,
-		Url:        filePath,
+		Url:        fmt.Sprintf("uploads/%s", storedName),
 		UploadDate: time.Now(),
 	}
 
+	// El archivo se escribe antes que el registro para no dejar registros sin archivo
+	if err := writeUpload(filePath, file); err != nil {
+		return fmt.Errorf("error saving file: %v", err)
+	}
+
 	if err := s.repo.SaveFile(fileRecord); err != nil {
+		os.Remove(filePath)
 		return fmt.Errorf("error uploading file in DB: %v", err)
 	}
 
+	event := audit.NewEvent(actor, domain.AuditFileUploaded, domain.AuditTargetCourse, courseID)
+	event.Details = fmt.Sprintf("%s (user %d)", filename, userID)
+	s.auditLog.Record(event)
+
+	return nil
+}
+
+// storedUploadName genera un nombre aleatorio para guardar el archivo, así dos cursos pueden
+// subir archivos con el mismo nombre sin pisarse. Conserva la extensión del original.
+func storedUploadName(filename string) (string, error) {
+	id, err := randomHex(16)
+	if err != nil {
+		return "", err
+	}
+	return id + strings.ToLower(filepath.Ext(filename)), nil
+}
+
+// writeUpload copia el archivo subido al disco. Si la copia falla borra lo que haya escrito.
+func writeUpload(filePath string, file io.Reader) error {
 	destFile, err := os.Create(filePath)
 	if err != nil {
 		return err
 	}
-	defer destFile.Close()
 
 	_, err = io.Copy(destFile, file)
+	if closeErr := destFile.Close(); err == nil {
+		err = closeErr
+	}
 	if err != nil {
+		os.Remove(filePath)
 		return err
 	}
-
 	return nil
 }
 
@@ -187,63 +377,31 @@ func (s *userService) GetUserById(userID int64) (*domain.User, error) {
 }
 
 func (s *userService) UserAuthentication(tokenString string) (string, error) {
-	tokenString = strings.TrimPrefix(tokenString, "Bearer ")
-	if tokenString == "" {
-		return "", fmt.Errorf("bearer token is required")
-	}
-
-	claims := &jwt.MapClaims{}
-
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return jwtKey, nil
-	})
+	claims, err := s.ValidateToken(tokenString)
 	if err != nil {
-		return "", fmt.Errorf("error parsing token: %v", err)
-	}
-	if !token.Valid {
-		return "", fmt.Errorf("invalid token")
+		return "", err
 	}
 
-	userType, ok := (*claims)["type"].(bool)
-	if !ok {
-		return "", fmt.Errorf("invalid token payload")
-	}
-
-	if !userType {
-		return "student", nil
-	} else {
-		return "admin", nil
-	}
+	return claims.Role, nil
 }
 
 func (s *userService) GetUserID(tokenString string) (int, error) {
-	tokenString = strings.TrimPrefix(tokenString, "Bearer ")
-	if tokenString == "" {
-		return 0, fmt.Errorf("bearer token is required")
+	claims, err := s.ValidateToken(tokenString)
+	if err != nil {
+		return 0, err
 	}
 
-	claims := &jwt.MapClaims{}
+	return int(claims.UserID), nil
+}
 
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return jwtKey, nil
-	})
+// UnlockUser quita el bloqueo por intentos fallidos de login de la cuenta del usuario
+func (s *userService) UnlockUser(userID int64, actor domain.Actor) error {
+	user, err := s.GetUserById(userID)
 	if err != nil {
-		return 0, fmt.Errorf("error parsing token: %v", err)
-	}
-	if !token.Valid {
-		return 0, fmt.Errorf("invalid token")
-	}
-
-	userID, ok := (*claims)["userID"].(float64)
-	if !ok {
-		return 0, fmt.Errorf("invalid token payload")
+		return err
 	}
 
-	return int(userID), nil
+	s.loginLimiter.Unlock(user.Email)
+	s.auditUser(actor, domain.AuditUserUnlocked, user.Id)
+	return nil
 }
@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"backend/controllers/audit"
+	"backend/domain"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAuditService simula el servicio de auditoría
+type MockAuditService struct {
+	mock.Mock
+}
+
+func (m *MockAuditService) Record(event domain.AuditEvent) {
+	m.Called(event)
+}
+
+func (m *MockAuditService) List(query domain.AuditQuery) (domain.AuditPage, error) {
+	args := m.Called(query)
+	return args.Get(0).(domain.AuditPage), args.Error(1)
+}
+
+func TestListAuditEvents_Filters(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockAuditService)
+	controller := audit.NewAuditController(mockService)
+
+	mockService.On("List", mock.MatchedBy(func(query domain.AuditQuery) bool {
+		return query.ActorID == 3 &&
+			query.Action == domain.AuditCourseDeleted &&
+			query.TargetType == domain.AuditTargetCourse &&
+			query.Page == 2 && query.PageSize == 10 &&
+			query.From != nil && query.From.Year() == 2024 && query.To == nil
+	})).Return(domain.AuditPage{Result: []domain.AuditEvent{{Id: 11}}, Total: 11, Page: 2, PageSize: 10}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/admin/audit?actor_id=3&action=course.delete&target_type=course&page=2&page_size=10&from=2024-01-01T00:00:00Z", nil)
+
+	// Act
+	controller.ListEvents(c)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response domain.AuditPage
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(11), response.Total)
+	assert.Len(t, response.Result, 1)
+	mockService.AssertExpectations(t)
+}
+
+func TestListAuditEvents_InvalidQuery(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockAuditService)
+	controller := audit.NewAuditController(mockService)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/admin/audit?from=yesterday", nil)
+
+	// Act
+	controller.ListEvents(c)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "List", mock.Anything)
+}
@@ -3,10 +3,15 @@ package controllers
 import (
 	"backend/controllers/courses"
 	"backend/domain"
+	"backend/middleware"
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -19,56 +24,187 @@ type MockCourseService struct {
 	mock.Mock
 }
 
-func (m *MockCourseService) SearchCourse(query string) ([]domain.Course, error) {
-	args := m.Called(query)
-	return args.Get(0).([]domain.Course), args.Error(1)
+func (m *MockCourseService) SearchCourse(query domain.CourseQuery, actor domain.Actor) (domain.SearchResponse, error) {
+	args := m.Called(query, actor)
+	return args.Get(0).(domain.SearchResponse), args.Error(1)
+}
+
+func (m *MockCourseService) SuggestCourses(prefix string, limit int, actor domain.Actor) ([]domain.Suggestion, error) {
+	args := m.Called(prefix, limit, actor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Suggestion), args.Error(1)
 }
 
-func (m *MockCourseService) GetCourse(id int64) (domain.Course, error) {
-	args := m.Called(id)
+func (m *MockCourseService) GetCourse(id int64, actor domain.Actor) (domain.Course, error) {
+	args := m.Called(id, actor)
 	return args.Get(0).(domain.Course), args.Error(1)
 }
 
-func (m *MockCourseService) GetAllCourses() ([]domain.Course, error) {
-	args := m.Called()
-	return args.Get(0).([]domain.Course), args.Error(1)
+func (m *MockCourseService) GetAllCourses(query domain.CourseQuery, actor domain.Actor) (domain.SearchResponse, error) {
+	args := m.Called(query, actor)
+	return args.Get(0).(domain.SearchResponse), args.Error(1)
+}
+
+func (m *MockCourseService) Subscription(userID, courseID int64, actor domain.Actor) error {
+	args := m.Called(userID, courseID, actor)
+	return args.Error(0)
+}
+
+func (m *MockCourseService) CreateCourse(title, description, category, instructor string, duration int64, requirement string, actor domain.Actor) (domain.Course, error) {
+	args := m.Called(title, description, category, instructor, duration, requirement, actor)
+	return args.Get(0).(domain.Course), args.Error(1)
 }
 
-func (m *MockCourseService) Subscription(userID, courseID int64) error {
-	args := m.Called(userID, courseID)
+func (m *MockCourseService) ListStaff(courseID int64, actor domain.Actor) ([]domain.CourseStaff, error) {
+	args := m.Called(courseID, actor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.CourseStaff), args.Error(1)
+}
+
+func (m *MockCourseService) AddStaff(courseID int64, userID int64, role string, actor domain.Actor) error {
+	args := m.Called(courseID, userID, role, actor)
 	return args.Error(0)
 }
 
-func (m *MockCourseService) CreateCourse(title, description, category, instructor string, duration int64, requirement string) error {
-	args := m.Called(title, description, category, instructor, duration, requirement)
+func (m *MockCourseService) RemoveStaff(courseID int64, userID int64, actor domain.Actor) error {
+	args := m.Called(courseID, userID, actor)
 	return args.Error(0)
 }
 
-func (m *MockCourseService) UpdateCourse(courseID int64, title, description, category, instructor string, duration int64, requirement string) error {
-	args := m.Called(courseID, title, description, category, instructor, duration, requirement)
+func (m *MockCourseService) UpdateCourse(courseID int64, title, description, category, instructor string, duration int64, requirement string, actor domain.Actor) error {
+	args := m.Called(courseID, title, description, category, instructor, duration, requirement, actor)
 	return args.Error(0)
 }
 
-func (m *MockCourseService) DeleteCourse(courseID int64) error {
-	args := m.Called(courseID)
+func (m *MockCourseService) PatchCourse(courseID int64, patch domain.CourseRequest, actor domain.Actor) (domain.Course, error) {
+	args := m.Called(courseID, patch, actor)
+	return args.Get(0).(domain.Course), args.Error(1)
+}
+
+func (m *MockCourseService) DeleteCourse(courseID int64, actor domain.Actor) error {
+	args := m.Called(courseID, actor)
 	return args.Error(0)
 }
 
-func (m *MockCourseService) CommentList(courseID int64) ([]domain.CommentResponse, error) {
-	args := m.Called(courseID)
+func (m *MockCourseService) CommentList(courseID int64, actor domain.Actor) ([]domain.CommentResponse, error) {
+	args := m.Called(courseID, actor)
 	return args.Get(0).([]domain.CommentResponse), args.Error(1)
 }
 
-func (m *MockCourseService) GetCoursesByInstructor(instructor string) ([]domain.Course, error) {
-	args := m.Called(instructor)
+func (m *MockCourseService) GetCoursesByInstructor(instructor string, actor domain.Actor) ([]domain.Course, error) {
+	args := m.Called(instructor, actor)
+	return args.Get(0).([]domain.Course), args.Error(1)
+}
+
+func (m *MockCourseService) ChangeCourseStatus(courseID int64, request domain.CourseStatusRequest, actor domain.Actor) (domain.Course, error) {
+	args := m.Called(courseID, request, actor)
+	return args.Get(0).(domain.Course), args.Error(1)
+}
+
+func (m *MockCourseService) ListTrash() ([]domain.Course, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
 	return args.Get(0).([]domain.Course), args.Error(1)
 }
 
-func (m *MockCourseService) GetCourseImages(courseID int64) ([]domain.File, error) {
-	args := m.Called(courseID)
+func (m *MockCourseService) RestoreCourse(courseID int64, actor domain.Actor) (domain.Course, error) {
+	args := m.Called(courseID, actor)
+	return args.Get(0).(domain.Course), args.Error(1)
+}
+
+func (m *MockCourseService) ListRevisions(courseID int64, actor domain.Actor) ([]domain.CourseRevision, error) {
+	args := m.Called(courseID, actor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.CourseRevision), args.Error(1)
+}
+
+func (m *MockCourseService) DiffRevisions(courseID int64, from, to int, actor domain.Actor) (domain.RevisionDiff, error) {
+	args := m.Called(courseID, from, to, actor)
+	return args.Get(0).(domain.RevisionDiff), args.Error(1)
+}
+
+func (m *MockCourseService) RestoreRevision(courseID int64, number int, actor domain.Actor) (domain.Course, error) {
+	args := m.Called(courseID, number, actor)
+	return args.Get(0).(domain.Course), args.Error(1)
+}
+
+func (m *MockCourseService) ListModules(courseID int64, actor domain.Actor) ([]domain.ModuleOutline, error) {
+	args := m.Called(courseID, actor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.ModuleOutline), args.Error(1)
+}
+
+func (m *MockCourseService) CreateModule(courseID int64, request domain.ModuleRequest, actor domain.Actor) (domain.CourseModule, error) {
+	args := m.Called(courseID, request, actor)
+	return args.Get(0).(domain.CourseModule), args.Error(1)
+}
+
+func (m *MockCourseService) UpdateModule(courseID, moduleID int64, request domain.ModuleRequest, actor domain.Actor) (domain.CourseModule, error) {
+	args := m.Called(courseID, moduleID, request, actor)
+	return args.Get(0).(domain.CourseModule), args.Error(1)
+}
+
+func (m *MockCourseService) DeleteModule(courseID, moduleID int64, actor domain.Actor) error {
+	args := m.Called(courseID, moduleID, actor)
+	return args.Error(0)
+}
+
+func (m *MockCourseService) ListLessons(courseID int64, actor domain.Actor) ([]domain.Lesson, error) {
+	args := m.Called(courseID, actor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Lesson), args.Error(1)
+}
+
+func (m *MockCourseService) GetLesson(courseID, lessonID int64, actor domain.Actor) (domain.Lesson, error) {
+	args := m.Called(courseID, lessonID, actor)
+	return args.Get(0).(domain.Lesson), args.Error(1)
+}
+
+func (m *MockCourseService) CreateLesson(courseID int64, request domain.LessonRequest, actor domain.Actor) (domain.Lesson, error) {
+	args := m.Called(courseID, request, actor)
+	return args.Get(0).(domain.Lesson), args.Error(1)
+}
+
+func (m *MockCourseService) UpdateLesson(courseID, lessonID int64, request domain.LessonRequest, actor domain.Actor) (domain.Lesson, error) {
+	args := m.Called(courseID, lessonID, request, actor)
+	return args.Get(0).(domain.Lesson), args.Error(1)
+}
+
+func (m *MockCourseService) DeleteLesson(courseID, lessonID int64, actor domain.Actor) error {
+	args := m.Called(courseID, lessonID, actor)
+	return args.Error(0)
+}
+
+func (m *MockCourseService) ReorderLessons(courseID int64, lessonIDs []int64, actor domain.Actor) ([]domain.Lesson, error) {
+	args := m.Called(courseID, lessonIDs, actor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Lesson), args.Error(1)
+}
+
+func (m *MockCourseService) GetCourseImages(courseID int64, actor domain.Actor) ([]domain.File, error) {
+	args := m.Called(courseID, actor)
 	return args.Get(0).([]domain.File), args.Error(1)
 }
 
+func (m *MockCourseService) GetCourseFile(courseID, fileID int64, actor domain.Actor) (domain.File, string, error) {
+	args := m.Called(courseID, fileID, actor)
+	return args.Get(0).(domain.File), args.String(1), args.Error(2)
+}
+
 func TestSearchCourse_Success(t *testing.T) {
 	// Arrange
 	gin.SetMode(gin.TestMode)
@@ -80,7 +216,7 @@ func TestSearchCourse_Success(t *testing.T) {
 		{Id: 2, Title: "Advanced Go", Description: "Advanced Go concepts"},
 	}
 
-	mockService.On("SearchCourse", "go").Return(expectedCourses, nil)
+	mockService.On("SearchCourse", domain.CourseQuery{Query: "go"}, mock.AnythingOfType("domain.Actor")).Return(domain.SearchResponse{Result: expectedCourses, Total: 2, Page: 1, PageSize: 20}, nil)
 
 	// Act
 	w := httptest.NewRecorder()
@@ -107,7 +243,7 @@ func TestSearchCourse_Error(t *testing.T) {
 	mockService := new(MockCourseService)
 	controller := courses.NewCourseController(mockService)
 
-	mockService.On("SearchCourse", "invalid").Return([]domain.Course{}, assert.AnError)
+	mockService.On("SearchCourse", domain.CourseQuery{Query: "invalid"}, mock.AnythingOfType("domain.Actor")).Return(domain.SearchResponse{}, assert.AnError)
 
 	// Act
 	w := httptest.NewRecorder()
@@ -137,7 +273,7 @@ func TestGetCourse_Success(t *testing.T) {
 		Requirement: "Basic programming knowledge",
 	}
 
-	mockService.On("GetCourse", int64(1)).Return(expectedCourse, nil)
+	mockService.On("GetCourse", int64(1), mock.AnythingOfType("domain.Actor")).Return(expectedCourse, nil)
 
 	// Act
 	w := httptest.NewRecorder()
@@ -164,7 +300,7 @@ func TestGetCourse_NotFound(t *testing.T) {
 	mockService := new(MockCourseService)
 	controller := courses.NewCourseController(mockService)
 
-	mockService.On("GetCourse", int64(999)).Return(domain.Course{}, assert.AnError)
+	mockService.On("GetCourse", int64(999), mock.AnythingOfType("domain.Actor")).Return(domain.Course{}, assert.AnError)
 
 	// Act
 	w := httptest.NewRecorder()
@@ -206,7 +342,7 @@ func TestGetAllCourses_Success(t *testing.T) {
 		{Id: 2, Title: "Course 2", Description: "Description 2"},
 	}
 
-	mockService.On("GetAllCourses").Return(expectedCourses, nil)
+	mockService.On("GetAllCourses", domain.CourseQuery{}, mock.AnythingOfType("domain.Actor")).Return(domain.SearchResponse{Result: expectedCourses, Total: 2, Page: 1, PageSize: 20}, nil)
 
 	// Act
 	w := httptest.NewRecorder()
@@ -226,6 +362,107 @@ func TestGetAllCourses_Success(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestGetAllCourses_PaginationLinks(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCourseService)
+	controller := courses.NewCourseController(mockService)
+
+	expectedQuery := domain.CourseQuery{Categories: []string{"Programming", "Data"}, MinDuration: 30, Sort: "-popularity", Page: 2, PageSize: 10}
+	mockService.On("GetAllCourses", expectedQuery, mock.AnythingOfType("domain.Actor")).Return(domain.SearchResponse{
+		Result:   []domain.Course{{Id: 11, Title: "Course 11"}},
+		Total:    35,
+		Page:     2,
+		PageSize: 10,
+	}, nil)
+
+	// Act
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/courses?category=Programming&category=Data&min_duration=30&sort=-popularity&page=2&page_size=10", nil)
+
+	controller.GetAllCourses(c)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response domain.SearchResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(35), response.Total)
+	assert.Equal(t, "/courses?category=Programming&category=Data&min_duration=30&page=3&page_size=10&sort=-popularity", response.Next)
+	assert.Equal(t, "/courses?category=Programming&category=Data&min_duration=30&page=1&page_size=10&sort=-popularity", response.Prev)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetAllCourses_InvalidQuery(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCourseService)
+	controller := courses.NewCourseController(mockService)
+
+	mockService.On("GetAllCourses", domain.CourseQuery{Sort: "name"}, mock.AnythingOfType("domain.Actor")).
+		Return(domain.SearchResponse{}, fmt.Errorf("%w: invalid sort field", domain.ErrInvalidCourseQuery))
+
+	for _, url := range []string{"/courses?page=0", "/courses?max_duration=abc", "/courses?sort=name"} {
+		// Act
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", url, nil)
+
+		controller.GetAllCourses(c)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code, url)
+	}
+}
+
+func TestSuggestCourses_Success(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCourseService)
+	controller := courses.NewCourseController(mockService)
+
+	suggestions := []domain.Suggestion{{Text: "Python inicial", Field: domain.SearchFieldTitle, CourseID: 2}}
+	mockService.On("SuggestCourses", "pyt", 5, mock.AnythingOfType("domain.Actor")).Return(suggestions, nil)
+
+	// Act
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/courses/suggest?prefix=pyt&limit=5", nil)
+
+	controller.SuggestCourses(c)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response domain.SuggestResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, suggestions, response.Result)
+	mockService.AssertExpectations(t)
+}
+
+func TestSuggestCourses_MissingPrefix(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCourseService)
+	controller := courses.NewCourseController(mockService)
+
+	mockService.On("SuggestCourses", "", 0, mock.AnythingOfType("domain.Actor")).Return(nil, errors.New("prefix is required"))
+
+	// Act
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/courses/suggest", nil)
+
+	controller.SuggestCourses(c)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
 func TestSubscription_Success(t *testing.T) {
 	// Arrange
 	gin.SetMode(gin.TestMode)
@@ -237,7 +474,7 @@ func TestSubscription_Success(t *testing.T) {
 		CourseId: 1,
 	}
 
-	mockService.On("Subscription", int64(1), int64(1)).Return(nil)
+	mockService.On("Subscription", int64(1), int64(1), mock.AnythingOfType("domain.Actor")).Return(nil)
 
 	// Act
 	w := httptest.NewRecorder()
@@ -246,6 +483,8 @@ func TestSubscription_Success(t *testing.T) {
 	jsonBody, _ := json.Marshal(subscribeRequest)
 	c.Request = httptest.NewRequest("POST", "/subscriptions", bytes.NewBuffer(jsonBody))
 	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(middleware.ContextUserID, int64(1))
+	c.Set(middleware.ContextRole, domain.RoleStudent)
 
 	controller.Subscription(c)
 
@@ -271,7 +510,7 @@ func TestSubscription_Conflict(t *testing.T) {
 		CourseId: 1,
 	}
 
-	mockService.On("Subscription", int64(1), int64(1)).Return(assert.AnError)
+	mockService.On("Subscription", int64(1), int64(1), mock.AnythingOfType("domain.Actor")).Return(assert.AnError)
 
 	// Act
 	w := httptest.NewRecorder()
@@ -280,6 +519,8 @@ func TestSubscription_Conflict(t *testing.T) {
 	jsonBody, _ := json.Marshal(subscribeRequest)
 	c.Request = httptest.NewRequest("POST", "/subscriptions", bytes.NewBuffer(jsonBody))
 	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(middleware.ContextUserID, int64(1))
+	c.Set(middleware.ContextRole, domain.RoleStudent)
 
 	controller.Subscription(c)
 
@@ -295,15 +536,15 @@ func TestCreateCourse_Success(t *testing.T) {
 	controller := courses.NewCourseController(mockService)
 
 	courseRequest := domain.CourseRequest{
-		Title:       "New Course",
-		Description: "Course Description",
-		Category:    "Programming",
-		Instructor:  "John Doe",
-		Duration:    60,
-		Requirement: "Basic knowledge",
+		Title:       domain.Some("New Course"),
+		Description: domain.Some("Course Description"),
+		Category:    domain.Some("Programming"),
+		Instructor:  domain.Some("John Doe"),
+		Duration:    domain.Some(int64(60)),
+		Requirement: domain.Some("Basic knowledge"),
 	}
 
-	mockService.On("CreateCourse", "New Course", "Course Description", "Programming", "John Doe", int64(60), "Basic knowledge").Return(nil)
+	mockService.On("CreateCourse", "New Course", "Course Description", "Programming", "John Doe", int64(60), "Basic knowledge", mock.AnythingOfType("domain.Actor")).Return(domain.Course{Id: 1, Title: "New Course"}, nil)
 
 	// Act
 	w := httptest.NewRecorder()
@@ -333,15 +574,15 @@ func TestCreateCourse_Conflict(t *testing.T) {
 	controller := courses.NewCourseController(mockService)
 
 	courseRequest := domain.CourseRequest{
-		Title:       "New Course",
-		Description: "Course Description",
-		Category:    "Programming",
-		Instructor:  "John Doe",
-		Duration:    60,
-		Requirement: "Basic knowledge",
+		Title:       domain.Some("New Course"),
+		Description: domain.Some("Course Description"),
+		Category:    domain.Some("Programming"),
+		Instructor:  domain.Some("John Doe"),
+		Duration:    domain.Some(int64(60)),
+		Requirement: domain.Some("Basic knowledge"),
 	}
 
-	mockService.On("CreateCourse", "New Course", "Course Description", "Programming", "John Doe", int64(60), "Basic knowledge").Return(assert.AnError)
+	mockService.On("CreateCourse", "New Course", "Course Description", "Programming", "John Doe", int64(60), "Basic knowledge", mock.AnythingOfType("domain.Actor")).Return(domain.Course{}, assert.AnError)
 
 	// Act
 	w := httptest.NewRecorder()
@@ -365,15 +606,15 @@ func TestUpdateCourse_Success(t *testing.T) {
 	controller := courses.NewCourseController(mockService)
 
 	courseRequest := domain.CourseRequest{
-		Title:       "Updated Course",
-		Description: "Updated Description",
-		Category:    "Programming",
-		Instructor:  "Jane Doe",
-		Duration:    90,
-		Requirement: "Advanced knowledge",
+		Title:       domain.Some("Updated Course"),
+		Description: domain.Some("Updated Description"),
+		Category:    domain.Some("Programming"),
+		Instructor:  domain.Some("Jane Doe"),
+		Duration:    domain.Some(int64(90)),
+		Requirement: domain.Some("Advanced knowledge"),
 	}
 
-	mockService.On("UpdateCourse", int64(1), "Updated Course", "Updated Description", "Programming", "Jane Doe", int64(90), "Advanced knowledge").Return(nil)
+	mockService.On("UpdateCourse", int64(1), "Updated Course", "Updated Description", "Programming", "Jane Doe", int64(90), "Advanced knowledge", mock.AnythingOfType("domain.Actor")).Return(nil)
 
 	// Act
 	w := httptest.NewRecorder()
@@ -403,7 +644,7 @@ func TestDeleteCourse_Success(t *testing.T) {
 	mockService := new(MockCourseService)
 	controller := courses.NewCourseController(mockService)
 
-	mockService.On("DeleteCourse", int64(1)).Return(nil)
+	mockService.On("DeleteCourse", int64(1), mock.AnythingOfType("domain.Actor")).Return(nil)
 
 	// Act
 	w := httptest.NewRecorder()
@@ -423,7 +664,7 @@ func TestDeleteCourse_NotFound(t *testing.T) {
 	mockService := new(MockCourseService)
 	controller := courses.NewCourseController(mockService)
 
-	mockService.On("DeleteCourse", int64(999)).Return(assert.AnError)
+	mockService.On("DeleteCourse", int64(999), mock.AnythingOfType("domain.Actor")).Return(assert.AnError)
 
 	// Act
 	w := httptest.NewRecorder()
@@ -448,7 +689,7 @@ func TestCommentList_Success(t *testing.T) {
 		{UserID: 2, Comment: "Very helpful"},
 	}
 
-	mockService.On("CommentList", int64(1)).Return(expectedComments, nil)
+	mockService.On("CommentList", int64(1), mock.AnythingOfType("domain.Actor")).Return(expectedComments, nil)
 
 	// Act
 	w := httptest.NewRecorder()
@@ -475,7 +716,7 @@ func TestCommentList_NotFound(t *testing.T) {
 	mockService := new(MockCourseService)
 	controller := courses.NewCourseController(mockService)
 
-	mockService.On("CommentList", int64(999)).Return([]domain.CommentResponse{}, assert.AnError)
+	mockService.On("CommentList", int64(999), mock.AnythingOfType("domain.Actor")).Return([]domain.CommentResponse{}, assert.AnError)
 
 	// Act
 	w := httptest.NewRecorder()
@@ -502,7 +743,7 @@ func TestGetCoursesByInstructor_Success(t *testing.T) {
 		{Id: 1, Title: "Curso 1", Instructor: "profesor1"},
 		{Id: 2, Title: "Curso 2", Instructor: "profesor1"},
 	}
-	mockService.On("GetCoursesByInstructor", "profesor1").Return(expectedCourses, nil)
+	mockService.On("GetCoursesByInstructor", "profesor1", mock.AnythingOfType("domain.Actor")).Return(expectedCourses, nil)
 
 	// Crear request
 	req, _ := http.NewRequest("GET", "/courses/instructor/profesor1", nil)
@@ -557,7 +798,7 @@ func TestGetCoursesByInstructor_ServiceError(t *testing.T) {
 	controller := courses.NewCourseController(mockService)
 
 	// Configurar mock para error
-	mockService.On("GetCoursesByInstructor", "profesor1").Return([]domain.Course{}, assert.AnError)
+	mockService.On("GetCoursesByInstructor", "profesor1", mock.AnythingOfType("domain.Actor")).Return([]domain.Course{}, assert.AnError)
 
 	// Crear request
 	req, _ := http.NewRequest("GET", "/courses/instructor/profesor1", nil)
@@ -591,7 +832,7 @@ func TestGetCourseImages_Success(t *testing.T) {
 		{Id: 1, Name: "imagen1.jpg", Course_Id: 1},
 		{Id: 2, Name: "documento.pdf", Course_Id: 1},
 	}
-	mockService.On("GetCourseImages", int64(1)).Return(expectedFiles, nil)
+	mockService.On("GetCourseImages", int64(1), mock.AnythingOfType("domain.Actor")).Return(expectedFiles, nil)
 
 	// Crear request
 	req, _ := http.NewRequest("GET", "/courses/images/1", nil)
@@ -646,7 +887,7 @@ func TestGetCourseImages_ServiceError(t *testing.T) {
 	controller := courses.NewCourseController(mockService)
 
 	// Configurar mock para error
-	mockService.On("GetCourseImages", int64(1)).Return([]domain.File{}, assert.AnError)
+	mockService.On("GetCourseImages", int64(1), mock.AnythingOfType("domain.Actor")).Return([]domain.File{}, assert.AnError)
 
 	// Crear request
 	req, _ := http.NewRequest("GET", "/courses/images/1", nil)
@@ -668,3 +909,567 @@ func TestGetCourseImages_ServiceError(t *testing.T) {
 
 	mockService.AssertExpectations(t)
 }
+
+func TestSubscription_OtherUserIsForbidden(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCourseService)
+	controller := courses.NewCourseController(mockService)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/subscriptions", bytes.NewBufferString(`{"user_id": 2, "course_id": 1}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(middleware.ContextUserID, int64(1))
+	c.Set(middleware.ContextRole, domain.RoleStudent)
+
+	controller.Subscription(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockService.AssertNotCalled(t, "Subscription", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSubscription_AdminOnBehalfOfUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCourseService)
+	controller := courses.NewCourseController(mockService)
+
+	mockService.On("Subscription", int64(2), int64(1), mock.MatchedBy(func(actor domain.Actor) bool {
+		return actor.UserID == 7 && actor.Role == domain.RoleAdmin && actor.OnBehalfOf == 2
+	})).Return(nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/subscriptions", bytes.NewBufferString(`{"course_id": 1, "on_behalf_of": 2}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(middleware.ContextUserID, int64(7))
+	c.Set(middleware.ContextRole, domain.RoleAdmin)
+
+	controller.Subscription(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestDeleteCourse_Forbidden(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCourseService)
+	controller := courses.NewCourseController(mockService)
+
+	mockService.On("DeleteCourse", int64(1), mock.AnythingOfType("domain.Actor")).Return(domain.ErrCourseForbidden)
+
+	// Act
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+	controller.DeleteCourse(c)
+
+	// Assert
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestListStaff_Success(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCourseService)
+	controller := courses.NewCourseController(mockService)
+
+	staff := []domain.CourseStaff{{CourseID: 1, UserID: 2, Role: domain.StaffOwner}}
+	mockService.On("ListStaff", int64(1), mock.AnythingOfType("domain.Actor")).Return(staff, nil)
+
+	// Act
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+	controller.ListStaff(c)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response domain.StaffList
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, staff, response.Result)
+	mockService.AssertExpectations(t)
+}
+
+func TestAddStaff_Success(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCourseService)
+	controller := courses.NewCourseController(mockService)
+
+	mockService.On("AddStaff", int64(1), int64(9), domain.StaffCoInstructor, mock.AnythingOfType("domain.Actor")).Return(nil)
+
+	// Act
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+	jsonBody, _ := json.Marshal(domain.StaffRequest{UserID: 9, Role: domain.StaffCoInstructor})
+	c.Request = httptest.NewRequest("POST", "/courses/1/staff", bytes.NewBuffer(jsonBody))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	controller.AddStaff(c)
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestAddStaff_Forbidden(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCourseService)
+	controller := courses.NewCourseController(mockService)
+
+	mockService.On("AddStaff", int64(1), int64(9), domain.StaffOwner, mock.AnythingOfType("domain.Actor")).Return(domain.ErrCourseForbidden)
+
+	// Act
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+	jsonBody, _ := json.Marshal(domain.StaffRequest{UserID: 9, Role: domain.StaffOwner})
+	c.Request = httptest.NewRequest("POST", "/courses/1/staff", bytes.NewBuffer(jsonBody))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	controller.AddStaff(c)
+
+	// Assert
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestRemoveStaff_Success(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCourseService)
+	controller := courses.NewCourseController(mockService)
+
+	mockService.On("RemoveStaff", int64(1), int64(9), mock.AnythingOfType("domain.Actor")).Return(nil)
+
+	// Act
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}, {Key: "userId", Value: "9"}}
+
+	controller.RemoveStaff(c)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestListLessons_NotSubscribed(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCourseService)
+	controller := courses.NewCourseController(mockService)
+
+	mockService.On("ListLessons", int64(1), mock.AnythingOfType("domain.Actor")).Return(nil, domain.ErrNotSubscribed)
+
+	// Act
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+	c.Request = httptest.NewRequest("GET", "/courses/1/lessons", nil)
+
+	controller.ListLessons(c)
+
+	// Assert
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetLesson_NotFound(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCourseService)
+	controller := courses.NewCourseController(mockService)
+
+	mockService.On("GetLesson", int64(1), int64(100), mock.AnythingOfType("domain.Actor")).Return(domain.Lesson{}, fmt.Errorf("%w: lesson 100", domain.ErrContentNotFound))
+
+	// Act
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}, {Key: "lessonId", Value: "100"}}
+	c.Request = httptest.NewRequest("GET", "/courses/1/lessons/100", nil)
+
+	controller.GetLesson(c)
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateModule_Success(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCourseService)
+	controller := courses.NewCourseController(mockService)
+
+	module := domain.CourseModule{Id: 10, CourseID: 1, Title: "Intro", Position: 1}
+	mockService.On("CreateModule", int64(1), domain.ModuleRequest{Title: "Intro"}, mock.AnythingOfType("domain.Actor")).Return(module, nil)
+
+	// Act
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+	jsonBody, _ := json.Marshal(domain.ModuleRequest{Title: "Intro"})
+	c.Request = httptest.NewRequest("POST", "/courses/1/modules", bytes.NewBuffer(jsonBody))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	controller.CreateModule(c)
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response domain.CourseModule
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), response.Id)
+	mockService.AssertExpectations(t)
+}
+
+func TestReorderLessons_InvalidID(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCourseService)
+	controller := courses.NewCourseController(mockService)
+
+	// Act
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = []gin.Param{{Key: "id", Value: "abc"}}
+	c.Request = httptest.NewRequest("PUT", "/courses/abc/lessons/order", bytes.NewBufferString(`{"lesson_ids":[1]}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	controller.ReorderLessons(c)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "ReorderLessons", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestChangeCourseStatus_Success(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCourseService)
+	controller := courses.NewCourseController(mockService)
+
+	request := domain.CourseStatusRequest{Status: domain.CourseInReview}
+	mockService.On("ChangeCourseStatus", int64(1), request, mock.AnythingOfType("domain.Actor")).Return(domain.Course{Id: 1, Status: domain.CourseInReview}, nil)
+
+	// Act
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+	jsonBody, _ := json.Marshal(request)
+	c.Request = httptest.NewRequest("POST", "/courses/1/status", bytes.NewBuffer(jsonBody))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	controller.ChangeCourseStatus(c)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response domain.Course
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.CourseInReview, response.Status)
+	mockService.AssertExpectations(t)
+}
+
+func TestChangeCourseStatus_InvalidTransition(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCourseService)
+	controller := courses.NewCourseController(mockService)
+
+	request := domain.CourseStatusRequest{Status: domain.CoursePublished}
+	mockService.On("ChangeCourseStatus", int64(1), request, mock.AnythingOfType("domain.Actor")).
+		Return(domain.Course{}, fmt.Errorf("%w: from \"draft\" to \"published\"", domain.ErrInvalidStatusTransition))
+
+	// Act
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+	jsonBody, _ := json.Marshal(request)
+	c.Request = httptest.NewRequest("POST", "/courses/1/status", bytes.NewBuffer(jsonBody))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	controller.ChangeCourseStatus(c)
+
+	// Assert
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestListTrash_Success(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCourseService)
+	controller := courses.NewCourseController(mockService)
+
+	trashed := []domain.Course{{Id: 4, Title: "Curso borrado"}}
+	mockService.On("ListTrash").Return(trashed, nil)
+
+	// Act
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/admin/courses/trash", nil)
+
+	controller.ListTrash(c)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response domain.ListResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Len(t, response.Result, 1)
+	assert.Equal(t, 4, response.Result[0].Id)
+	mockService.AssertExpectations(t)
+}
+
+func TestRestoreCourse_NotInTrash(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCourseService)
+	controller := courses.NewCourseController(mockService)
+
+	mockService.On("RestoreCourse", int64(4), mock.AnythingOfType("domain.Actor")).
+		Return(domain.Course{}, fmt.Errorf("%w: 4 is not in the trash", domain.ErrCourseNotFound))
+
+	// Act
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = []gin.Param{{Key: "id", Value: "4"}}
+	c.Request = httptest.NewRequest("POST", "/admin/courses/4/restore", nil)
+
+	controller.RestoreCourse(c)
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestDiffRevisions_InvalidQuery(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCourseService)
+	controller := courses.NewCourseController(mockService)
+
+	// Act
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+	c.Request = httptest.NewRequest("GET", "/courses/1/revisions/diff?from=1", nil)
+
+	controller.DiffRevisions(c)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "DiffRevisions", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRestoreRevision_Success(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCourseService)
+	controller := courses.NewCourseController(mockService)
+
+	mockService.On("RestoreRevision", int64(1), 2, mock.AnythingOfType("domain.Actor")).Return(domain.Course{Id: 1, Title: "Go"}, nil)
+
+	// Act
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}, {Key: "rev", Value: "2"}}
+	c.Request = httptest.NewRequest("POST", "/courses/1/revisions/2/restore", nil)
+
+	controller.RestoreRevision(c)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestRestoreRevision_NotFound(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCourseService)
+	controller := courses.NewCourseController(mockService)
+
+	mockService.On("RestoreRevision", int64(1), 9, mock.AnythingOfType("domain.Actor")).
+		Return(domain.Course{}, fmt.Errorf("%w: 9", domain.ErrRevisionNotFound))
+
+	// Act
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}, {Key: "rev", Value: "9"}}
+	c.Request = httptest.NewRequest("POST", "/courses/1/revisions/9/restore", nil)
+
+	controller.RestoreRevision(c)
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestPatchCourse_OnlyGivenFields(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCourseService)
+	controller := courses.NewCourseController(mockService)
+
+	mockService.On("PatchCourse", int64(1), mock.MatchedBy(func(patch domain.CourseRequest) bool {
+		return patch.Duration.Set && patch.Duration.Value == 120 && !patch.Title.Set && patch.Requirement.Null
+	}), mock.AnythingOfType("domain.Actor")).Return(domain.Course{Id: 1, Duration: 120}, nil)
+
+	// Act
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+	c.Request = httptest.NewRequest("PATCH", "/courses/1", bytes.NewBufferString(`{"duration": 120, "requirement": null}`))
+	c.Request.Header.Set("Content-Type", "application/merge-patch+json")
+
+	controller.PatchCourse(c)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestPatchCourse_UnknownField(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCourseService)
+	controller := courses.NewCourseController(mockService)
+
+	// Act
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+	c.Request = httptest.NewRequest("PATCH", "/courses/1", bytes.NewBufferString(`{"status": "published"}`))
+	c.Request.Header.Set("Content-Type", "application/merge-patch+json")
+
+	controller.PatchCourse(c)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "PatchCourse", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPatchCourse_UnsupportedContentType(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCourseService)
+	controller := courses.NewCourseController(mockService)
+
+	// Act
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+	c.Request = httptest.NewRequest("PATCH", "/courses/1", bytes.NewBufferString(`[{"op": "replace", "path": "/duration", "value": 120}]`))
+	c.Request.Header.Set("Content-Type", "application/json-patch+json")
+
+	controller.PatchCourse(c)
+
+	// Assert
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	mockService.AssertNotCalled(t, "PatchCourse", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPatchCourse_Forbidden(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCourseService)
+	controller := courses.NewCourseController(mockService)
+
+	mockService.On("PatchCourse", int64(1), mock.AnythingOfType("domain.CourseRequest"), mock.AnythingOfType("domain.Actor")).
+		Return(domain.Course{}, domain.ErrCourseForbidden)
+
+	// Act
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+	c.Request = httptest.NewRequest("PATCH", "/courses/1", bytes.NewBufferString(`{"title": "Go"}`))
+	c.Request.Header.Set("Content-Type", "application/merge-patch+json")
+
+	controller.PatchCourse(c)
+
+	// Assert
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetCourseImages_NotSubscribed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCourseService)
+	controller := courses.NewCourseController(mockService)
+
+	mockService.On("GetCourseImages", int64(1), mock.AnythingOfType("domain.Actor")).Return([]domain.File{}, domain.ErrNotSubscribed)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/courses/images/1", nil)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	controller.GetCourseImages(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestDownloadCourseFile_Success(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCourseService)
+	controller := courses.NewCourseController(mockService)
+
+	path := filepath.Join(t.TempDir(), "apunte.pdf")
+	assert.NoError(t, os.WriteFile(path, []byte("pdf"), 0o600))
+	mockService.On("GetCourseFile", int64(1), int64(9), mock.AnythingOfType("domain.Actor")).
+		Return(domain.File{Id: 9, Course_Id: 1, Name: "apunte.pdf"}, path, nil)
+
+	// Act
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}, {Key: "fileId", Value: "9"}}
+	c.Request = httptest.NewRequest("GET", "/courses/1/files/9", nil)
+
+	controller.DownloadCourseFile(c)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "pdf", w.Body.String())
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "apunte.pdf")
+}
+
+func TestDownloadCourseFile_NotSubscribed(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCourseService)
+	controller := courses.NewCourseController(mockService)
+
+	mockService.On("GetCourseFile", int64(1), int64(9), mock.AnythingOfType("domain.Actor")).
+		Return(domain.File{}, "", domain.ErrNotSubscribed)
+
+	// Act
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = []gin.Param{{Key: "id", Value: "1"}, {Key: "fileId", Value: "9"}}
+	c.Request = httptest.NewRequest("GET", "/courses/1/files/9", nil)
+
+	controller.DownloadCourseFile(c)
+
+	// Assert
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -3,12 +3,16 @@ package controllers
 import (
 	"backend/controllers/users"
 	"backend/domain"
+	"backend/middleware"
 	"bytes"
 	"encoding/json"
+	"errors"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -20,9 +24,32 @@ type MockUserService struct {
 	mock.Mock
 }
 
-func (m *MockUserService) Login(email, password string) (string, error) {
-	args := m.Called(email, password)
-	return args.String(0), args.Error(1)
+func (m *MockUserService) Login(email, password string, client domain.ClientInfo) (domain.LoginResponse, error) {
+	args := m.Called(email, password, client)
+	return args.Get(0).(domain.LoginResponse), args.Error(1)
+}
+
+func (m *MockUserService) RefreshToken(refreshToken string, client domain.ClientInfo) (domain.LoginResponse, error) {
+	args := m.Called(refreshToken, client)
+	return args.Get(0).(domain.LoginResponse), args.Error(1)
+}
+
+func (m *MockUserService) Logout(sessionID int64) error {
+	args := m.Called(sessionID)
+	return args.Error(0)
+}
+
+func (m *MockUserService) ListSessions(userID, currentSessionID int64) ([]domain.SessionResponse, error) {
+	args := m.Called(userID, currentSessionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.SessionResponse), args.Error(1)
+}
+
+func (m *MockUserService) RevokeSession(userID, sessionID int64) error {
+	args := m.Called(userID, sessionID)
+	return args.Error(0)
 }
 
 func (m *MockUserService) UserRegister(nickname, email, password string, typeUser bool) (bool, error) {
@@ -40,8 +67,8 @@ func (m *MockUserService) AddComment(userID, courseID int64, comment string) err
 	return args.Error(0)
 }
 
-func (m *MockUserService) UploadFiles(file io.Reader, filename string, userID, courseID int64) error {
-	args := m.Called(file, filename, userID, courseID)
+func (m *MockUserService) UploadFiles(file io.Reader, filename string, userID, courseID int64, actor domain.Actor) error {
+	args := m.Called(file, filename, userID, courseID, actor)
 	return args.Error(0)
 }
 
@@ -55,11 +82,157 @@ func (m *MockUserService) GetUserID(tokenString string) (int, error) {
 	return args.Int(0), args.Error(1)
 }
 
+func (m *MockUserService) ValidateToken(tokenString string) (domain.TokenClaims, error) {
+	args := m.Called(tokenString)
+	return args.Get(0).(domain.TokenClaims), args.Error(1)
+}
+
+func (m *MockUserService) JWKS() domain.JWKSet {
+	args := m.Called()
+	return args.Get(0).(domain.JWKSet)
+}
+
+func (m *MockUserService) ForgotPassword(email string) error {
+	args := m.Called(email)
+	return args.Error(0)
+}
+
+func (m *MockUserService) ResetPassword(token, newPassword string) error {
+	args := m.Called(token, newPassword)
+	return args.Error(0)
+}
+
+func (m *MockUserService) VerifyEmail(token string) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockUserService) ResendVerification(userID int64) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockUserService) MarkEmailVerified(userID int64, actor domain.Actor) error {
+	args := m.Called(userID, actor)
+	return args.Error(0)
+}
+
+func (m *MockUserService) UnlockUser(userID int64, actor domain.Actor) error {
+	args := m.Called(userID, actor)
+	return args.Error(0)
+}
+
+func (m *MockUserService) ListRoles() ([]domain.Role, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.Role), args.Error(1)
+}
+
+func (m *MockUserService) SetUserRoles(userID int64, roles []string, actor domain.Actor) error {
+	args := m.Called(userID, roles, actor)
+	return args.Error(0)
+}
+
+func (m *MockUserService) CompleteMFALogin(mfaToken, code string, client domain.ClientInfo) (domain.LoginResponse, error) {
+	args := m.Called(mfaToken, code, client)
+	return args.Get(0).(domain.LoginResponse), args.Error(1)
+}
+
+func (m *MockUserService) BeginMFAEnrollment(mfaToken string) (domain.TOTPEnrollment, error) {
+	args := m.Called(mfaToken)
+	return args.Get(0).(domain.TOTPEnrollment), args.Error(1)
+}
+
+func (m *MockUserService) BeginTOTPEnrollment(userID int64) (domain.TOTPEnrollment, error) {
+	args := m.Called(userID)
+	return args.Get(0).(domain.TOTPEnrollment), args.Error(1)
+}
+
+func (m *MockUserService) ConfirmTOTPEnrollment(userID int64, code string) ([]string, error) {
+	args := m.Called(userID, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockUserService) DisableTOTP(userID int64, code string) error {
+	args := m.Called(userID, code)
+	return args.Error(0)
+}
+
 func (m *MockUserService) GetUserById(userID int64) (*domain.User, error) {
 	args := m.Called(userID)
 	return args.Get(0).(*domain.User), args.Error(1)
 }
 
+func (m *MockUserService) UpdateUser(userID int64, request domain.UpdateUserRequest, actor domain.Actor) (*domain.User, error) {
+	args := m.Called(userID, request, actor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
+func (m *MockUserService) ConfirmEmailChange(token string) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockUserService) ExportUserData(userID int64, actor domain.Actor) (domain.UserExport, error) {
+	args := m.Called(userID, actor)
+	return args.Get(0).(domain.UserExport), args.Error(1)
+}
+
+func (m *MockUserService) WriteExportArchive(export domain.UserExport, w io.Writer) error {
+	args := m.Called(export, w)
+	return args.Error(0)
+}
+
+func (m *MockUserService) DeleteUser(userID int64, currentPassword string, actor domain.Actor) error {
+	args := m.Called(userID, currentPassword, actor)
+	return args.Error(0)
+}
+
+func (m *MockUserService) ListUsers(query domain.UserQuery) (domain.UserPage, error) {
+	args := m.Called(query)
+	return args.Get(0).(domain.UserPage), args.Error(1)
+}
+
+func (m *MockUserService) PromoteUser(userID int64, role string, actor domain.Actor) error {
+	args := m.Called(userID, role, actor)
+	return args.Error(0)
+}
+
+func (m *MockUserService) DemoteUser(userID int64, role string, actor domain.Actor) error {
+	args := m.Called(userID, role, actor)
+	return args.Error(0)
+}
+
+func (m *MockUserService) DisableUser(userID int64, actor domain.Actor) error {
+	args := m.Called(userID, actor)
+	return args.Error(0)
+}
+
+func (m *MockUserService) EnableUser(userID int64, actor domain.Actor) error {
+	args := m.Called(userID, actor)
+	return args.Error(0)
+}
+
+func (m *MockUserService) ForcePasswordReset(userID int64, actor domain.Actor) error {
+	args := m.Called(userID, actor)
+	return args.Error(0)
+}
+
+func (m *MockUserService) ImportUsers(r io.Reader, options domain.UserImportOptions, actor domain.Actor) (domain.UserImportReport, error) {
+	args := m.Called(r, options, actor)
+	return args.Get(0).(domain.UserImportReport), args.Error(1)
+}
+
+func (m *MockUserService) ExportUsersCSV(query domain.UserQuery, w io.Writer) error {
+	args := m.Called(query, w)
+	return args.Error(0)
+}
+
 func TestLogin_Success(t *testing.T) {
 	// Arrange
 	gin.SetMode(gin.TestMode)
@@ -73,7 +246,12 @@ func TestLogin_Success(t *testing.T) {
 		Password: "password123",
 	}
 
-	mockService.On("Login", "test@example.com", "password123").Return(expectedToken, nil)
+	mockService.On("Login", "test@example.com", "password123", mock.AnythingOfType("domain.ClientInfo")).Return(domain.LoginResponse{
+		Token:        expectedToken,
+		RefreshToken: "refresh-token-123",
+		TokenType:    "Bearer",
+		ExpiresIn:    900,
+	}, nil)
 
 	// Act
 	w := httptest.NewRecorder()
@@ -94,6 +272,7 @@ func TestLogin_Success(t *testing.T) {
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
 	assert.Equal(t, expectedToken, response.Token)
+	assert.Equal(t, "refresh-token-123", response.RefreshToken)
 
 	mockService.AssertExpectations(t)
 }
@@ -165,7 +344,7 @@ func TestLogin_Unauthorized(t *testing.T) {
 		Password: "wrongpassword",
 	}
 
-	mockService.On("Login", "test@example.com", "wrongpassword").Return("", assert.AnError)
+	mockService.On("Login", "test@example.com", "wrongpassword", mock.AnythingOfType("domain.ClientInfo")).Return(domain.LoginResponse{}, assert.AnError)
 
 	// Act
 	w := httptest.NewRecorder()
@@ -229,6 +408,7 @@ func TestSubscriptionList_Success(t *testing.T) {
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
 	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+	c.Set(middleware.ContextUserID, int64(1))
 
 	controller.SubscriptionList(c)
 
@@ -265,6 +445,8 @@ func TestAddComment_Success(t *testing.T) {
 	jsonBody, _ := json.Marshal(commentRequest)
 	c.Request = httptest.NewRequest("POST", "/comments", bytes.NewBuffer(jsonBody))
 	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(middleware.ContextUserID, int64(1))
+	c.Set(middleware.ContextRole, domain.RoleStudent)
 
 	controller.AddComment(c)
 
@@ -352,7 +534,7 @@ func TestGetUserById_Success(t *testing.T) {
 		Id:       1,
 		Nickname: "usuario1",
 		Email:    "usuario1@test.com",
-		Type:     false,
+		Roles:    []string{domain.RoleStudent},
 	}
 	mockService.On("GetUserById", int64(1)).Return(expectedUser, nil)
 
@@ -362,6 +544,7 @@ func TestGetUserById_Success(t *testing.T) {
 	c, _ := gin.CreateTestContext(w)
 	c.Request = req
 	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Set(middleware.ContextUserID, int64(1))
 
 	// Ejecutar
 	controller.GetUserById(c)
@@ -375,8 +558,45 @@ func TestGetUserById_Success(t *testing.T) {
 	assert.Equal(t, expectedUser.Id, response.Id)
 	assert.Equal(t, expectedUser.Nickname, response.Nickname)
 	assert.Equal(t, expectedUser.Email, response.Email)
-	assert.Equal(t, expectedUser.Type, response.Type)
+	assert.False(t, response.Type)
+	assert.Equal(t, expectedUser.Roles, response.Roles)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetUserById_OtherUserForbidden(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/users/2", nil)
+	c.Params = gin.Params{{Key: "id", Value: "2"}}
+	c.Set(middleware.ContextUserID, int64(1))
+
+	controller.GetUserById(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockService.AssertNotCalled(t, "GetUserById", mock.Anything)
+}
+
+func TestSubscriptionList_UserManagerAllowed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	mockService.On("SubscriptionList", int64(2)).Return([]domain.Course{}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "2"}}
+	c.Set(middleware.ContextUserID, int64(1))
+	c.Set(middleware.ContextPermissions, []string{domain.PermUserManage})
+
+	controller.SubscriptionList(c)
 
+	assert.Equal(t, http.StatusOK, w.Code)
 	mockService.AssertExpectations(t)
 }
 
@@ -420,6 +640,7 @@ func TestGetUserById_ServiceError(t *testing.T) {
 	c, _ := gin.CreateTestContext(w)
 	c.Request = req
 	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Set(middleware.ContextUserID, int64(1))
 
 	// Ejecutar
 	controller.GetUserById(c)
@@ -434,3 +655,768 @@ func TestGetUserById_ServiceError(t *testing.T) {
 
 	mockService.AssertExpectations(t)
 }
+
+func TestRefreshToken_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	mockService.On("RefreshToken", "refresh-token-123", mock.AnythingOfType("domain.ClientInfo")).Return(domain.LoginResponse{
+		Token:        "new-access-token",
+		RefreshToken: "new-refresh-token",
+	}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/users/refresh", bytes.NewBufferString(`{"refresh_token": "refresh-token-123"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	controller.RefreshToken(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response domain.LoginResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "new-access-token", response.Token)
+	assert.Equal(t, "new-refresh-token", response.RefreshToken)
+	mockService.AssertExpectations(t)
+}
+
+func TestRefreshToken_Unauthorized(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	mockService.On("RefreshToken", "expired", mock.AnythingOfType("domain.ClientInfo")).Return(domain.LoginResponse{}, assert.AnError)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/users/refresh", bytes.NewBufferString(`{"refresh_token": "expired"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	controller.RefreshToken(c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestLogout_RevokesCurrentSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	mockService.On("Logout", int64(10)).Return(nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/users/logout", nil)
+	c.Set(middleware.ContextUserID, int64(1))
+	c.Set(middleware.ContextSessionID, int64(10))
+
+	controller.Logout(c)
+
+	assert.Equal(t, http.StatusNoContent, c.Writer.Status())
+	mockService.AssertExpectations(t)
+}
+
+func TestListSessions_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	mockService.On("ListSessions", int64(1), int64(10)).Return([]domain.SessionResponse{
+		{Id: 10, Current: true},
+		{Id: 11},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/users/sessions", nil)
+	c.Set(middleware.ContextUserID, int64(1))
+	c.Set(middleware.ContextSessionID, int64(10))
+
+	controller.ListSessions(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response domain.SessionList
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response.Result, 2)
+	assert.True(t, response.Result[0].Current)
+	mockService.AssertExpectations(t)
+}
+
+func TestRevokeSession_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	mockService.On("RevokeSession", int64(1), int64(99)).Return(assert.AnError)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("DELETE", "/users/sessions/99", nil)
+	c.Params = gin.Params{{Key: "id", Value: "99"}}
+	c.Set(middleware.ContextUserID, int64(1))
+
+	controller.RevokeSession(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestJWKS_ReturnsPublicKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	mockService.On("JWKS").Return(domain.JWKSet{Keys: []domain.JWK{
+		{Kty: "OKP", Kid: "2025-10", Alg: "EdDSA", Use: "sig", Crv: "Ed25519", X: "abc"},
+	}})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/.well-known/jwks.json", nil)
+
+	controller.JWKS(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response domain.JWKSet
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response.Keys, 1)
+	assert.Equal(t, "2025-10", response.Keys[0].Kid)
+	mockService.AssertExpectations(t)
+}
+
+func TestForgotPassword_AlwaysAccepted(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	mockService.On("ForgotPassword", "unknown@example.com").Return(nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/users/password/forgot", bytes.NewBufferString(`{"email": "unknown@example.com"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	controller.ForgotPassword(c)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	var response domain.Result
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Contains(t, response.Message, "If the email is registered")
+	mockService.AssertExpectations(t)
+}
+
+func TestForgotPassword_MissingEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/users/password/forgot", bytes.NewBufferString(`{}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	controller.ForgotPassword(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "ForgotPassword", mock.Anything)
+}
+
+func TestResetPassword_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	mockService.On("ResetPassword", "reset-token", "newPassword123").Return(nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/users/password/reset", bytes.NewBufferString(`{"token": "reset-token", "password": "newPassword123"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	controller.ResetPassword(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestResetPassword_InvalidToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	mockService.On("ResetPassword", "used-token", "newPassword123").Return(errors.New("invalid or expired token"))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/users/password/reset", bytes.NewBufferString(`{"token": "used-token", "password": "newPassword123"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	controller.ResetPassword(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response domain.Result
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Contains(t, response.Message, "invalid or expired token")
+	mockService.AssertExpectations(t)
+}
+
+func TestLogin_UnverifiedEmailIsForbidden(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	mockService.On("Login", "new@example.com", "password123", mock.AnythingOfType("domain.ClientInfo")).Return(domain.LoginResponse{}, domain.ErrEmailNotVerified)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/users/login", bytes.NewBufferString(`{"email": "new@example.com", "password": "password123"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	controller.Login(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	var response domain.Result
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Contains(t, response.Message, "email address is not verified")
+}
+
+func TestVerifyEmail_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	mockService.On("VerifyEmail", "verify-token").Return(nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/users/verify?token=verify-token", nil)
+
+	controller.VerifyEmail(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestResendVerification_AlreadyVerified(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	mockService.On("ResendVerification", int64(5)).Return(errors.New("email is already verified"))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/admin/users/5/verification/resend", nil)
+	c.Params = gin.Params{{Key: "id", Value: "5"}}
+
+	controller.ResendVerification(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestLogin_ThrottledReturnsRetryAfter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	mockService.On("Login", "test@example.com", "wrong", mock.AnythingOfType("domain.ClientInfo")).Return(domain.LoginResponse{},
+		&domain.LoginThrottledError{RetryAfter: 1500 * time.Millisecond})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/users/login", bytes.NewBufferString(`{"email": "test@example.com", "password": "wrong"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	controller.Login(c)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+}
+
+func TestLogin_LockedAccount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	mockService.On("Login", "test@example.com", "wrong", mock.AnythingOfType("domain.ClientInfo")).Return(domain.LoginResponse{},
+		&domain.LoginThrottledError{RetryAfter: 15 * time.Minute, Locked: true})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/users/login", bytes.NewBufferString(`{"email": "test@example.com", "password": "wrong"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	controller.Login(c)
+
+	assert.Equal(t, http.StatusLocked, w.Code)
+	assert.Equal(t, "900", w.Header().Get("Retry-After"))
+	var response domain.Result
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Contains(t, response.Message, "account is temporarily locked")
+}
+
+func TestUnlockUser_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	mockService.On("UnlockUser", int64(5), mock.AnythingOfType("domain.Actor")).Return(nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/admin/users/5/unlock", nil)
+	c.Params = gin.Params{{Key: "id", Value: "5"}}
+
+	controller.UnlockUser(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestCompleteMFALogin_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	mockService.On("CompleteMFALogin", "mfa-token", "123456", mock.AnythingOfType("domain.ClientInfo")).Return(domain.LoginResponse{
+		Token: "access-token",
+	}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/users/login/2fa", bytes.NewBufferString(`{"mfa_token": "mfa-token", "code": "123456"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	controller.CompleteMFALogin(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response domain.LoginResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "access-token", response.Token)
+	mockService.AssertExpectations(t)
+}
+
+func TestCompleteMFALogin_InvalidCode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	mockService.On("CompleteMFALogin", "mfa-token", "000000", mock.AnythingOfType("domain.ClientInfo")).Return(domain.LoginResponse{},
+		errors.New("invalid authentication code"))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/users/login/2fa", bytes.NewBufferString(`{"mfa_token": "mfa-token", "code": "000000"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	controller.CompleteMFALogin(c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestConfirmTOTPEnrollment_ReturnsRecoveryCodes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	mockService.On("ConfirmTOTPEnrollment", int64(1), "123456").Return([]string{"aaaa-bbbb-cccc-dddd"}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/users/2fa/confirm", bytes.NewBufferString(`{"code": "123456"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(middleware.ContextUserID, int64(1))
+
+	controller.ConfirmTOTPEnrollment(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response domain.RecoveryCodesResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []string{"aaaa-bbbb-cccc-dddd"}, response.RecoveryCodes)
+	mockService.AssertExpectations(t)
+}
+
+func TestAddComment_TakesAuthorFromToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	mockService.On("AddComment", int64(3), int64(1), "Great course!").Return(nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/users/comments", bytes.NewBufferString(`{"courseID": 1, "comment": "Great course!"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(middleware.ContextUserID, int64(3))
+	c.Set(middleware.ContextRole, domain.RoleStudent)
+
+	controller.AddComment(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestAddComment_StudentCannotActOnBehalf(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/users/comments", bytes.NewBufferString(`{"courseID": 1, "comment": "spam", "on_behalf_of": 9}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(middleware.ContextUserID, int64(3))
+	c.Set(middleware.ContextRole, domain.RoleStudent)
+
+	controller.AddComment(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockService.AssertNotCalled(t, "AddComment", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUploadFiles_OtherUserIsForbidden(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", "notes.txt")
+	assert.NoError(t, err)
+	part.Write([]byte("content"))
+	writer.WriteField("user_id", "9")
+	writer.WriteField("course_id", "1")
+	writer.Close()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/upload", body)
+	c.Request.Header.Set("Content-Type", writer.FormDataContentType())
+	c.Set(middleware.ContextUserID, int64(3))
+	c.Set(middleware.ContextRole, domain.RoleStudent)
+
+	controller.UploadFiles(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockService.AssertNotCalled(t, "UploadFiles", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateUser_ReturnsPendingEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	pending := "new@example.com"
+	request := domain.UpdateUserRequest{Email: "new@example.com"}
+	mockService.On("UpdateUser", int64(3), request, mock.AnythingOfType("domain.Actor")).
+		Return(&domain.User{Id: 3, Nickname: "student", Email: "old@example.com", PendingEmail: &pending}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("PATCH", "/users/3", bytes.NewBufferString(`{"email": "new@example.com"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "id", Value: "3"}}
+	c.Set(middleware.ContextUserID, int64(3))
+	c.Set(middleware.ContextRole, domain.RoleStudent)
+
+	controller.UpdateUser(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response domain.UserResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "old@example.com", response.Email)
+	assert.Equal(t, "new@example.com", *response.PendingEmail)
+	mockService.AssertExpectations(t)
+}
+
+func TestUpdateUser_ErrorStatuses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := map[error]int{
+		domain.ErrAccountForbidden:       http.StatusForbidden,
+		domain.ErrInvalidCurrentPassword: http.StatusForbidden,
+		domain.ErrNicknameTaken:          http.StatusConflict,
+		domain.ErrEmailTaken:             http.StatusConflict,
+		errors.New("nothing to update"):  http.StatusBadRequest,
+	}
+	for serviceErr, status := range cases {
+		mockService := new(MockUserService)
+		controller := users.NewUserController(mockService)
+		mockService.On("UpdateUser", int64(4), mock.Anything, mock.Anything).Return(nil, serviceErr)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("PUT", "/users/4", bytes.NewBufferString(`{"nickname": "taken"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "id", Value: "4"}}
+
+		controller.UpdateUser(c)
+
+		assert.Equal(t, status, w.Code, serviceErr.Error())
+	}
+}
+
+func TestConfirmEmailChange_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	mockService.On("ConfirmEmailChange", "change-token").Return(nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/users/email/confirm?token=change-token", nil)
+
+	controller.ConfirmEmailChange(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestExportUserData_ZipFormat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	export := domain.UserExport{Profile: domain.UserExportProfile{Id: 3}}
+	mockService.On("ExportUserData", int64(3), mock.AnythingOfType("domain.Actor")).Return(export, nil)
+	mockService.On("WriteExportArchive", export, mock.Anything).Return(nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/users/3/export?format=zip", nil)
+	c.Params = gin.Params{{Key: "id", Value: "3"}}
+
+	controller.ExportUserData(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "emarve-user-3.zip")
+	mockService.AssertExpectations(t)
+}
+
+func TestExportUserData_Forbidden(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	mockService.On("ExportUserData", int64(4), mock.AnythingOfType("domain.Actor")).Return(domain.UserExport{}, domain.ErrAccountForbidden)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/users/4/export", nil)
+	c.Params = gin.Params{{Key: "id", Value: "4"}}
+
+	controller.ExportUserData(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockService.AssertNotCalled(t, "WriteExportArchive", mock.Anything, mock.Anything)
+}
+
+func TestDeleteUser_WithoutBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	mockService.On("DeleteUser", int64(4), "", mock.AnythingOfType("domain.Actor")).Return(nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("DELETE", "/users/4", nil)
+	c.Params = gin.Params{{Key: "id", Value: "4"}}
+
+	controller.DeleteUser(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestDeleteUser_LastCourseOwner(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	mockService.On("DeleteUser", int64(4), "", mock.AnythingOfType("domain.Actor")).
+		Return(domain.ErrLastCourseOwner)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("DELETE", "/users/4", nil)
+	c.Params = gin.Params{{Key: "id", Value: "4"}}
+
+	controller.DeleteUser(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestListUsers_ParsesQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	expected := domain.UserQuery{Email: "example", Nickname: "stu", Sort: "-id", Page: 2, PageSize: 10}
+	mockService.On("ListUsers", expected).Return(domain.UserPage{
+		Result:   []domain.UserResponse{{Id: 3, Nickname: "student"}},
+		Total:    11,
+		Page:     2,
+		PageSize: 10,
+	}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/admin/users?email=example&nickname=stu&sort=-id&page=2&page_size=10", nil)
+
+	controller.ListUsers(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response domain.UserPage
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, int64(11), response.Total)
+	mockService.AssertExpectations(t)
+}
+
+func TestListUsers_InvalidPage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/admin/users?page=0", nil)
+
+	controller.ListUsers(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "ListUsers", mock.Anything)
+}
+
+func TestPromoteUser_DefaultsToAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	mockService.On("PromoteUser", int64(5), "", mock.AnythingOfType("domain.Actor")).Return(nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/admin/users/5/promote", nil)
+	c.Params = gin.Params{{Key: "id", Value: "5"}}
+
+	controller.PromoteUser(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestDisableUser_Conflict(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	mockService.On("DisableUser", int64(1), mock.AnythingOfType("domain.Actor")).Return(errors.New("admins cannot disable their own account"))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/admin/users/1/disable", nil)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	controller.DisableUser(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestLogin_DisabledAccountIsForbidden(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	mockService.On("Login", "test@example.com", "password123", mock.AnythingOfType("domain.ClientInfo")).Return(domain.LoginResponse{}, domain.ErrAccountDisabled)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/users/login", bytes.NewBufferString(`{"email": "test@example.com", "password": "password123"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	controller.Login(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func newCSVUploadRequest(t *testing.T, url string, content string) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", "users.csv")
+	assert.NoError(t, err)
+	part.Write([]byte(content))
+	writer.Close()
+
+	req := httptest.NewRequest("POST", url, body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestImportUsers_DryRunByDefault(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	options := domain.UserImportOptions{DryRun: true, CourseIDs: []int64{1, 2}}
+	mockService.On("ImportUsers", mock.Anything, options, mock.AnythingOfType("domain.Actor")).
+		Return(domain.UserImportReport{DryRun: true, Total: 1, Valid: 1}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = newCSVUploadRequest(t, "/admin/users/import?course_ids=1,2", "nickname,email\nana,ana@example.com\n")
+
+	controller.ImportUsers(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestImportUsers_InvalidRowsReturnReport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	report := domain.UserImportReport{Total: 1, Rows: []domain.UserImportRow{{Line: 2, Status: domain.ImportRowError, Errors: []string{"invalid email address"}}}}
+	mockService.On("ImportUsers", mock.Anything, domain.UserImportOptions{DryRun: false}, mock.AnythingOfType("domain.Actor")).
+		Return(report, domain.ErrImportInvalid)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = newCSVUploadRequest(t, "/admin/users/import?dry_run=false", "nickname,email\nana,bad\n")
+
+	controller.ImportUsers(c)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	var response domain.UserImportReport
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []string{"invalid email address"}, response.Rows[0].Errors)
+}
+
+func TestExportUsers_ReturnsCSV(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	controller := users.NewUserController(mockService)
+
+	mockService.On("ExportUsersCSV", domain.UserQuery{Email: "example"}, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(io.Writer).Write([]byte("id,nickname\n1,ana\n"))
+	}).Return(nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/admin/users/export?email=example", nil)
+
+	controller.ExportUsers(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/csv")
+	assert.Equal(t, "id,nickname\n1,ana\n", w.Body.String())
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"backend/domain"
+	"backend/middleware"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newActingContext(userID int64, role string) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("POST", "/subscriptions", nil)
+	c.Set(middleware.ContextUserID, userID)
+	c.Set(middleware.ContextRole, role)
+	return c
+}
+
+func TestActingUserID_DefaultsToTokenUser(t *testing.T) {
+	c := newActingContext(3, domain.RoleStudent)
+
+	userID, err := middleware.ActingUserID(c, 0, 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), userID)
+	_, onBehalf := middleware.GetOnBehalfOf(c)
+	assert.False(t, onBehalf)
+}
+
+func TestActingUserID_MatchingClaimIsAccepted(t *testing.T) {
+	c := newActingContext(3, domain.RoleStudent)
+
+	userID, err := middleware.ActingUserID(c, 3, 3)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), userID)
+}
+
+func TestActingUserID_MismatchedClaimIsRejected(t *testing.T) {
+	for _, role := range []string{domain.RoleStudent, domain.RoleAdmin} {
+		c := newActingContext(3, role)
+
+		_, err := middleware.ActingUserID(c, 9, 0)
+
+		assert.ErrorIs(t, err, middleware.ErrUserMismatch)
+	}
+}
+
+func TestActingUserID_StudentCannotActOnBehalf(t *testing.T) {
+	c := newActingContext(3, domain.RoleStudent)
+
+	_, err := middleware.ActingUserID(c, 0, 9)
+
+	assert.ErrorIs(t, err, middleware.ErrOnBehalfNotAllowed)
+}
+
+func TestActingUserID_AdminOnBehalfIsRecorded(t *testing.T) {
+	c := newActingContext(1, domain.RoleAdmin)
+
+	userID, err := middleware.ActingUserID(c, 9, 9)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(9), userID)
+	onBehalfOf, ok := middleware.GetOnBehalfOf(c)
+	assert.True(t, ok)
+	assert.Equal(t, int64(9), onBehalfOf)
+}
+
+func TestActingUserID_WithoutAuthentication(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	_, err := middleware.ActingUserID(c, 0, 0)
+
+	assert.ErrorIs(t, err, middleware.ErrMissingAuthentication)
+}
@@ -0,0 +1,229 @@
+package middleware
+
+import (
+	"backend/domain"
+	"backend/middleware"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTokenValidator simula la validación de tokens del servicio de usuarios
+type MockTokenValidator struct {
+	mock.Mock
+}
+
+func (m *MockTokenValidator) ValidateToken(tokenString string) (domain.TokenClaims, error) {
+	args := m.Called(tokenString)
+	return args.Get(0).(domain.TokenClaims), args.Error(1)
+}
+
+// newTestRouter arma un router con una ruta protegida que devuelve la identidad del contexto
+func newTestRouter(validator *MockTokenValidator, roles ...string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	auth := middleware.NewAuthMiddleware(validator)
+
+	router := gin.New()
+	router.GET("/protected", auth.Authenticate(), auth.RequireRoles(roles...), func(c *gin.Context) {
+		userID, _ := middleware.GetUserID(c)
+		role, _ := middleware.GetRole(c)
+		c.JSON(http.StatusOK, gin.H{"user_id": userID, "role": role})
+	})
+	return router
+}
+
+func TestAuthenticate_MissingHeader(t *testing.T) {
+	validator := new(MockTokenValidator)
+	router := newTestRouter(validator, domain.RoleAdmin)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/protected", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "Authorization header is required")
+	validator.AssertNotCalled(t, "ValidateToken", mock.Anything)
+}
+
+func TestAuthenticate_NotBearer(t *testing.T) {
+	validator := new(MockTokenValidator)
+	router := newTestRouter(validator, domain.RoleAdmin)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/protected", nil)
+	req.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	validator.AssertNotCalled(t, "ValidateToken", mock.Anything)
+}
+
+func TestAuthenticate_InvalidToken(t *testing.T) {
+	validator := new(MockTokenValidator)
+	router := newTestRouter(validator, domain.RoleAdmin)
+	validator.On("ValidateToken", "Bearer bad-token").Return(domain.TokenClaims{}, errors.New("invalid token"))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/protected", nil)
+	req.Header.Set("Authorization", "Bearer bad-token")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid token")
+	validator.AssertExpectations(t)
+}
+
+func TestRequireRoles_Forbidden(t *testing.T) {
+	validator := new(MockTokenValidator)
+	router := newTestRouter(validator, domain.RoleAdmin)
+	validator.On("ValidateToken", "Bearer student-token").Return(domain.TokenClaims{UserID: 2, Role: domain.RoleStudent}, nil)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/protected", nil)
+	req.Header.Set("Authorization", "Bearer student-token")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	validator.AssertExpectations(t)
+}
+
+func TestRequireRoles_AllowedSetsContext(t *testing.T) {
+	validator := new(MockTokenValidator)
+	router := newTestRouter(validator, domain.RoleStudent, domain.RoleAdmin)
+	validator.On("ValidateToken", "Bearer admin-token").Return(domain.TokenClaims{UserID: 1, Role: domain.RoleAdmin}, nil)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/protected", nil)
+	req.Header.Set("Authorization", "Bearer admin-token")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"user_id": 1, "role": "admin"}`, w.Body.String())
+	validator.AssertExpectations(t)
+}
+
+func TestRequireRoles_SecondaryRoleIsAllowed(t *testing.T) {
+	validator := new(MockTokenValidator)
+	router := newTestRouter(validator, domain.RoleTeachingAssistant)
+	validator.On("ValidateToken", "Bearer ta-token").Return(domain.TokenClaims{
+		UserID: 4,
+		Role:   domain.RoleInstructor,
+		Roles:  []string{domain.RoleInstructor, domain.RoleTeachingAssistant},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/protected", nil)
+	req.Header.Set("Authorization", "Bearer ta-token")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	validator.AssertExpectations(t)
+}
+
+func newPermissionRouter(validator *MockTokenValidator, permissions ...string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	auth := middleware.NewAuthMiddleware(validator)
+
+	router := gin.New()
+	router.POST("/courses/create", auth.Authenticate(), auth.RequirePermissions(permissions...), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+	return router
+}
+
+func TestRequirePermissions_Allowed(t *testing.T) {
+	validator := new(MockTokenValidator)
+	router := newPermissionRouter(validator, domain.PermCourseCreate)
+	validator.On("ValidateToken", "Bearer instructor-token").Return(domain.TokenClaims{
+		UserID:      4,
+		Role:        domain.RoleInstructor,
+		Roles:       []string{domain.RoleInstructor},
+		Permissions: []string{domain.PermCourseCreate, domain.PermCourseUpdate},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/courses/create", nil)
+	req.Header.Set("Authorization", "Bearer instructor-token")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestRequirePermissions_Forbidden(t *testing.T) {
+	validator := new(MockTokenValidator)
+	router := newPermissionRouter(validator, domain.PermCourseCreate)
+	validator.On("ValidateToken", "Bearer reviewer-token").Return(domain.TokenClaims{
+		UserID:      5,
+		Role:        domain.RoleContentReviewer,
+		Roles:       []string{domain.RoleContentReviewer},
+		Permissions: []string{domain.PermCourseUpdate, domain.PermCommentModerate},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/courses/create", nil)
+	req.Header.Set("Authorization", "Bearer reviewer-token")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "permission course:create is required")
+}
+
+// newOptionalRouter arma un router con una ruta pública que devuelve la identidad si la hay
+func newOptionalRouter(validator *MockTokenValidator) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	auth := middleware.NewAuthMiddleware(validator)
+
+	router := gin.New()
+	router.GET("/public", auth.OptionalAuthenticate(), func(c *gin.Context) {
+		userID, _ := middleware.GetUserID(c)
+		role, _ := middleware.GetRole(c)
+		c.JSON(http.StatusOK, gin.H{"user_id": userID, "role": role})
+	})
+	return router
+}
+
+func TestOptionalAuthenticate_Anonymous(t *testing.T) {
+	validator := new(MockTokenValidator)
+	router := newOptionalRouter(validator)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/public", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"user_id": 0, "role": ""}`, w.Body.String())
+	validator.AssertNotCalled(t, "ValidateToken", mock.Anything)
+}
+
+func TestOptionalAuthenticate_ValidTokenSetsContext(t *testing.T) {
+	validator := new(MockTokenValidator)
+	router := newOptionalRouter(validator)
+	validator.On("ValidateToken", "Bearer staff-token").Return(domain.TokenClaims{UserID: 7, Role: domain.RoleInstructor}, nil)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/public", nil)
+	req.Header.Set("Authorization", "Bearer staff-token")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"user_id": 7, "role": "instructor"}`, w.Body.String())
+}
+
+func TestOptionalAuthenticate_InvalidTokenIsAnonymous(t *testing.T) {
+	validator := new(MockTokenValidator)
+	router := newOptionalRouter(validator)
+	validator.On("ValidateToken", "Bearer expired").Return(domain.TokenClaims{}, errors.New("token expired"))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/public", nil)
+	req.Header.Set("Authorization", "Bearer expired")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"user_id": 0, "role": ""}`, w.Body.String())
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"backend/domain"
+	"backend/services/audit"
+	"backend/services/courses"
+	"backend/services/users"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAuditRepository simula la tabla de auditoría
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) CreateAuditEvent(event domain.AuditEvent) error {
+	args := m.Called(event)
+	return args.Error(0)
+}
+
+func (m *MockAuditRepository) ListAuditEvents(query domain.AuditQuery) ([]domain.AuditEvent, int64, error) {
+	args := m.Called(query)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]domain.AuditEvent), args.Get(1).(int64), args.Error(2)
+}
+
+// MockAuditRecorder guarda los eventos registrados por los servicios
+type MockAuditRecorder struct {
+	events []domain.AuditEvent
+}
+
+func (m *MockAuditRecorder) Record(event domain.AuditEvent) {
+	m.events = append(m.events, event)
+}
+
+func TestAuditRecord_SetsTimestamp(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockAuditRepository)
+	service := audit.NewAuditService(mockRepo)
+	mockRepo.On("CreateAuditEvent", mock.MatchedBy(func(event domain.AuditEvent) bool {
+		return event.Action == domain.AuditCourseDeleted && !event.CreatedAt.IsZero()
+	})).Return(nil)
+
+	// Act
+	service.Record(domain.AuditEvent{Action: domain.AuditCourseDeleted})
+
+	// Assert
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAuditRecord_ErrorDoesNotPanic(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockAuditRepository)
+	service := audit.NewAuditService(mockRepo)
+	mockRepo.On("CreateAuditEvent", mock.AnythingOfType("domain.AuditEvent")).Return(errors.New("database error"))
+
+	// Act & Assert
+	assert.NotPanics(t, func() {
+		service.Record(domain.AuditEvent{Action: domain.AuditLoginFailed})
+	})
+}
+
+func TestAuditList_DefaultsAndMaxPageSize(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockAuditRepository)
+	service := audit.NewAuditService(mockRepo)
+	mockRepo.On("ListAuditEvents", domain.AuditQuery{Action: domain.AuditLoginFailed, Page: 1, PageSize: audit.MaxPageSize}).
+		Return([]domain.AuditEvent{{Id: 1}}, int64(1), nil)
+
+	// Act
+	page, err := service.List(domain.AuditQuery{Action: domain.AuditLoginFailed, PageSize: 1000})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), page.Total)
+	assert.Equal(t, 1, page.Page)
+	assert.Equal(t, audit.MaxPageSize, page.PageSize)
+	assert.Len(t, page.Result, 1)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAuditList_InvalidRange(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockAuditRepository)
+	service := audit.NewAuditService(mockRepo)
+	from := time.Now()
+	to := from.Add(-time.Hour)
+
+	// Act
+	_, err := service.List(domain.AuditQuery{From: &from, To: &to})
+
+	// Assert
+	assert.Error(t, err)
+	mockRepo.AssertNotCalled(t, "ListAuditEvents", mock.Anything)
+}
+
+func TestDeleteCourse_RecordsAuditEvent(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	recorder := &MockAuditRecorder{}
+	service := courses.NewCourseService(mockRepo, courses.WithAuditRecorder(recorder))
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1, Title: "Test Course"}, nil)
+	mockRepo.On("TrashCourse", int64(1)).Return(nil)
+
+	// Act
+	err := service.DeleteCourse(1, testActor)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, recorder.events, 1)
+	event := recorder.events[0]
+	assert.Equal(t, domain.AuditCourseDeleted, event.Action)
+	assert.Equal(t, testActor.UserID, event.ActorID)
+	assert.Equal(t, testActor.IP, event.IP)
+	assert.Equal(t, int64(1), event.TargetID)
+
+	var before domain.Course
+	assert.NoError(t, json.Unmarshal(event.Before, &before))
+	assert.Equal(t, "Test Course", before.Title)
+}
+
+func TestLogin_RecordsFailedAttempt(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	recorder := &MockAuditRecorder{}
+	service := users.NewUserService(mockRepo, users.WithAuditRecorder(recorder))
+	mockRepo.On("GetUserByEmail", "test@example.com").Return(&domain.User{Id: 1, Email: "test@example.com", PasswordHash: "wrong-hash"}, nil)
+
+	// Act
+	_, err := service.Login("test@example.com", "password123", domain.ClientInfo{IP: "10.0.0.1"})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Len(t, recorder.events, 1)
+	assert.Equal(t, domain.AuditLoginFailed, recorder.events[0].Action)
+	assert.Equal(t, int64(1), recorder.events[0].TargetID)
+	assert.Equal(t, "10.0.0.1", recorder.events[0].IP)
+}
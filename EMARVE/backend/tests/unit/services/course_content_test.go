@@ -0,0 +1,269 @@
+package services
+
+import (
+	"backend/domain"
+	"backend/services/courses"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// studentActor es un alumno sin relación con el staff del curso
+var studentActor = domain.Actor{UserID: 5, Role: domain.RoleStudent, IP: "127.0.0.1"}
+
+func TestListModules_SubscriberSeesOutline(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1}, nil)
+	mockRepo.On("GetCourseStaffMember", int64(1), int64(5)).Return(nil, nil)
+	mockRepo.On("IsSubscribed", int64(5), int64(1)).Return(true, nil)
+	mockRepo.On("GetModules", int64(1)).Return([]domain.CourseModule{
+		{Id: 10, CourseID: 1, Title: "Intro", Position: 1},
+		{Id: 11, CourseID: 1, Title: "Avanzado", Position: 2},
+	}, nil)
+	mockRepo.On("GetLessons", int64(1)).Return([]domain.Lesson{
+		{Id: 100, CourseID: 1, ModuleID: 11, Title: "Primera", Position: 1},
+		{Id: 101, CourseID: 1, ModuleID: 10, Title: "Segunda", Position: 2},
+		{Id: 102, CourseID: 1, ModuleID: 11, Title: "Tercera", Position: 3},
+	}, nil)
+
+	// Act
+	outline, err := service.ListModules(1, studentActor)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, outline, 2)
+	assert.Equal(t, int64(10), outline[0].Id)
+	assert.Len(t, outline[0].Lessons, 1)
+	assert.Equal(t, int64(101), outline[0].Lessons[0].Id)
+	assert.Len(t, outline[1].Lessons, 2)
+	assert.Equal(t, int64(100), outline[1].Lessons[0].Id)
+	assert.Equal(t, int64(102), outline[1].Lessons[1].Id)
+}
+
+func TestListLessons_NotSubscribed(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1}, nil)
+	mockRepo.On("GetCourseStaffMember", int64(1), int64(5)).Return(nil, nil)
+	mockRepo.On("IsSubscribed", int64(5), int64(1)).Return(false, nil)
+
+	// Act
+	lessons, err := service.ListLessons(1, studentActor)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrNotSubscribed)
+	assert.Nil(t, lessons)
+	mockRepo.AssertNotCalled(t, "GetLessons", mock.Anything)
+}
+
+func TestGetLesson_CourseNotFound(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetCourseById", int64(1)).Return(nil, errors.New("record not found"))
+
+	// Act
+	_, err := service.GetLesson(1, 100, studentActor)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrCourseNotFound)
+}
+
+func TestGetLesson_FromAnotherCourse(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1}, nil)
+	mockRepo.On("GetLessonById", int64(100)).Return(&domain.Lesson{Id: 100, CourseID: 2}, nil)
+
+	// Act
+	_, err := service.GetLesson(1, 100, testActor)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrContentNotFound)
+}
+
+func TestGetCourseFile_SubscriberGetsUploadPath(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo, courses.WithUploadsDir("/data/uploads"))
+
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1}, nil)
+	mockRepo.On("GetCourseStaffMember", int64(1), int64(5)).Return(nil, nil)
+	mockRepo.On("IsSubscribed", int64(5), int64(1)).Return(true, nil)
+	mockRepo.On("GetFileById", int64(9)).Return(&domain.File{Id: 9, Course_Id: 1, Url: "uploads/../../etc/apunte.pdf"}, nil)
+
+	// Act
+	file, path, err := service.GetCourseFile(1, 9, studentActor)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(9), file.Id)
+	assert.Equal(t, filepath.Join("/data/uploads", "apunte.pdf"), path)
+}
+
+func TestGetCourseFile_NotSubscribed(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1}, nil)
+	mockRepo.On("GetCourseStaffMember", int64(1), int64(5)).Return(nil, nil)
+	mockRepo.On("IsSubscribed", int64(5), int64(1)).Return(false, nil)
+
+	// Act
+	_, _, err := service.GetCourseFile(1, 9, studentActor)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrNotSubscribed)
+	mockRepo.AssertNotCalled(t, "GetFileById", mock.Anything)
+}
+
+func TestGetCourseFile_FromAnotherCourse(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1}, nil)
+	mockRepo.On("GetFileById", int64(9)).Return(&domain.File{Id: 9, Course_Id: 2}, nil)
+
+	// Act
+	_, _, err := service.GetCourseFile(1, 9, testActor)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrContentNotFound)
+}
+
+func TestCreateModule_TeachingAssistantForbidden(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1}, nil)
+	mockRepo.On("GetCourseStaffMember", int64(1), int64(7)).Return(&domain.CourseStaff{CourseID: 1, UserID: 7, Role: domain.StaffTeachingAssistant}, nil)
+
+	// Act
+	_, err := service.CreateModule(1, domain.ModuleRequest{Title: "Intro"}, staffActor)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrCourseForbidden)
+	mockRepo.AssertNotCalled(t, "CreateModule", mock.Anything)
+}
+
+func TestCreateModule_Success(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	recorder := &MockAuditRecorder{}
+	service := courses.NewCourseService(mockRepo, courses.WithAuditRecorder(recorder))
+
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1}, nil)
+	mockRepo.On("CreateModule", mock.MatchedBy(func(module domain.CourseModule) bool {
+		return module.CourseID == 1 && module.Title == "Intro"
+	})).Return(domain.CourseModule{Id: 10, CourseID: 1, Title: "Intro", Position: 1}, nil)
+
+	// Act
+	module, err := service.CreateModule(1, domain.ModuleRequest{Title: "  Intro  "}, testActor)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), module.Id)
+	assert.Len(t, recorder.events, 1)
+	assert.Equal(t, domain.AuditModuleCreated, recorder.events[0].Action)
+}
+
+func TestCreateLesson_FileFromAnotherCourse(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+	fileID := int64(30)
+
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1}, nil)
+	mockRepo.On("GetModuleById", int64(10)).Return(&domain.CourseModule{Id: 10, CourseID: 1}, nil)
+	mockRepo.On("GetFileById", int64(30)).Return(&domain.File{Id: 30, Course_Id: 2}, nil)
+
+	// Act
+	_, err := service.CreateLesson(1, domain.LessonRequest{ModuleID: 10, Title: "Apunte", Type: domain.LessonFile, FileID: &fileID}, testActor)
+
+	// Assert
+	assert.EqualError(t, err, "file 30 does not belong to course 1")
+	mockRepo.AssertNotCalled(t, "CreateLesson", mock.Anything)
+}
+
+func TestCreateLesson_InvalidURL(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1}, nil)
+	mockRepo.On("GetModuleById", int64(10)).Return(&domain.CourseModule{Id: 10, CourseID: 1}, nil)
+
+	// Act
+	_, err := service.CreateLesson(1, domain.LessonRequest{ModuleID: 10, Title: "Video", Type: domain.LessonLink, URL: "javascript:alert(1)"}, testActor)
+
+	// Assert
+	assert.EqualError(t, err, "url must be an absolute http or https URL")
+	mockRepo.AssertNotCalled(t, "CreateLesson", mock.Anything)
+}
+
+func TestCreateLesson_TextKeepsOnlyContent(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1}, nil)
+	mockRepo.On("GetModuleById", int64(10)).Return(&domain.CourseModule{Id: 10, CourseID: 1}, nil)
+	mockRepo.On("CreateLesson", mock.MatchedBy(func(lesson domain.Lesson) bool {
+		return lesson.Type == domain.LessonText && lesson.Content == "Hola" && lesson.URL == "" && lesson.FileID == nil
+	})).Return(domain.Lesson{Id: 100, CourseID: 1, ModuleID: 10, Type: domain.LessonText, Content: "Hola"}, nil)
+
+	// Act
+	lesson, err := service.CreateLesson(1, domain.LessonRequest{ModuleID: 10, Title: "Bienvenida", Type: domain.LessonText, Content: "Hola", URL: "https://example.com"}, testActor)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), lesson.Id)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReorderLessons_MissingLesson(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1}, nil)
+	mockRepo.On("GetLessons", int64(1)).Return([]domain.Lesson{{Id: 100, CourseID: 1}, {Id: 101, CourseID: 1}}, nil)
+
+	// Act
+	_, err := service.ReorderLessons(1, []int64{101, 101}, testActor)
+
+	// Assert
+	assert.EqualError(t, err, "lesson 101 is repeated")
+	mockRepo.AssertNotCalled(t, "ReorderLessons", mock.Anything, mock.Anything)
+}
+
+func TestReorderLessons_Success(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1}, nil)
+	mockRepo.On("GetLessons", int64(1)).Return([]domain.Lesson{{Id: 100, CourseID: 1}, {Id: 101, CourseID: 1}}, nil)
+	mockRepo.On("ReorderLessons", int64(1), []int64{101, 100}).Return(nil)
+
+	// Act
+	_, err := service.ReorderLessons(1, []int64{101, 100}, testActor)
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
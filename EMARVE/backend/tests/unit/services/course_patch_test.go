@@ -0,0 +1,103 @@
+package services
+
+import (
+	"backend/domain"
+	"backend/services/courses"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPatchCourse_KeepsOmittedFields(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	recorder := &MockAuditRecorder{}
+	service := courses.NewCourseService(mockRepo, courses.WithAuditRecorder(recorder))
+
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{
+		Id: 1, Title: "Go", Description: "Intro", Category: "Programación", Instructor: "Ana", Duration: 10, Requirement: "Nada",
+	}, nil)
+	mockRepo.On("UpdateCourse", int64(1), mock.MatchedBy(func(course domain.Course) bool {
+		return course.Duration == 20 && course.Title == "Go" && course.Requirement == "Nada" && !course.LastUpdate.IsZero()
+	}), mock.AnythingOfType("domain.CourseRevision"), mock.AnythingOfType("domain.CourseRevision")).Return(nil)
+
+	// Act
+	course, err := service.PatchCourse(1, domain.CourseRequest{Duration: domain.Some(int64(20))}, testActor)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(20), course.Duration)
+	assert.Equal(t, "Intro", course.Description)
+	assert.Len(t, recorder.events, 1)
+	assert.Equal(t, domain.AuditCourseUpdated, recorder.events[0].Action)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPatchCourse_NullNotAllowed(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	// Act
+	_, err := service.PatchCourse(1, domain.CourseRequest{Title: domain.Optional[string]{Set: true, Null: true}}, testActor)
+
+	// Assert
+	assert.EqualError(t, err, "title cannot be empty")
+	mockRepo.AssertNotCalled(t, "UpdateCourse", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPatchCourse_InvalidDuration(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	// Act
+	_, err := service.PatchCourse(1, domain.CourseRequest{Duration: domain.Some(int64(-5))}, testActor)
+
+	// Assert
+	assert.EqualError(t, err, "duration must be greater than zero")
+}
+
+func TestPatchCourse_EmptyPatch(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	// Act
+	_, err := service.PatchCourse(1, domain.CourseRequest{}, testActor)
+
+	// Assert
+	assert.EqualError(t, err, "nothing to update")
+}
+
+func TestPatchCourse_NoChangesSkipsSave(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1, Title: "Go", Duration: 10}, nil)
+
+	// Act
+	course, err := service.PatchCourse(1, domain.CourseRequest{Title: domain.Some("Go")}, testActor)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "Go", course.Title)
+	mockRepo.AssertNotCalled(t, "UpdateCourse", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPatchCourse_TeachingAssistantForbidden(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetCourseStaffMember", int64(1), int64(7)).Return(&domain.CourseStaff{CourseID: 1, UserID: 7, Role: domain.StaffTeachingAssistant}, nil)
+
+	// Act
+	_, err := service.PatchCourse(1, domain.CourseRequest{Title: domain.Some("Go")}, staffActor)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrCourseForbidden)
+	mockRepo.AssertNotCalled(t, "GetCourseById", mock.Anything)
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"backend/domain"
+	"backend/services/courses"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUpdateCourse_FirstChangeStoresBaseline(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1, Title: "Old", Description: "Old description"}, nil)
+	mockRepo.On("UpdateCourse", int64(1), mock.AnythingOfType("domain.Course"), mock.MatchedBy(func(baseline domain.CourseRevision) bool {
+		return baseline.Title == "Old" && baseline.AuthorID == 0
+	}), mock.MatchedBy(func(revision domain.CourseRevision) bool {
+		return revision.Title == "Title" && revision.AuthorID == testActor.UserID
+	})).Return(nil)
+
+	// Act
+	err := service.UpdateCourse(1, "Title", "Description", "Category", "Instructor", 60, "Requirement", testActor)
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestListRevisions_TeachingAssistantAllowed(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1}, nil)
+	mockRepo.On("GetCourseStaffMember", int64(1), int64(7)).Return(&domain.CourseStaff{CourseID: 1, UserID: 7, Role: domain.StaffTeachingAssistant}, nil)
+	mockRepo.On("GetCourseRevisions", int64(1)).Return([]domain.CourseRevision{{CourseID: 1, Number: 2}, {CourseID: 1, Number: 1}}, nil)
+
+	// Act
+	revisions, err := service.ListRevisions(1, staffActor)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, revisions, 2)
+	assert.Equal(t, 2, revisions[0].Number)
+}
+
+func TestListRevisions_StudentForbidden(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1}, nil)
+	mockRepo.On("GetCourseStaffMember", int64(1), int64(5)).Return(nil, nil)
+
+	// Act
+	_, err := service.ListRevisions(1, studentActor)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrCourseForbidden)
+	mockRepo.AssertNotCalled(t, "GetCourseRevisions", mock.Anything)
+}
+
+func TestDiffRevisions_OnlyChangedFields(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1}, nil)
+	mockRepo.On("GetCourseRevision", int64(1), 1).Return(&domain.CourseRevision{
+		CourseID: 1, Number: 1, Title: "Go", Description: "Intro", Category: "Programación", Instructor: "Ana", Duration: 10, Requirement: "Nada",
+	}, nil)
+	mockRepo.On("GetCourseRevision", int64(1), 3).Return(&domain.CourseRevision{
+		CourseID: 1, Number: 3, Title: "Go", Description: "Intro dañada", Category: "Programación", Instructor: "Ana", Duration: 20, Requirement: "Nada",
+	}, nil)
+
+	// Act
+	diff, err := service.DiffRevisions(1, 1, 3, testActor)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.FieldChange{
+		{Field: "description", From: "Intro", To: "Intro dañada"},
+		{Field: "duration", From: int64(10), To: int64(20)},
+	}, diff.Changes)
+}
+
+func TestDiffRevisions_MissingRevision(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1}, nil)
+	mockRepo.On("GetCourseRevision", int64(1), 1).Return(&domain.CourseRevision{CourseID: 1, Number: 1}, nil)
+	mockRepo.On("GetCourseRevision", int64(1), 9).Return(nil, nil)
+
+	// Act
+	_, err := service.DiffRevisions(1, 1, 9, testActor)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrRevisionNotFound)
+}
+
+func TestRestoreRevision_RecordsNewRevision(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	recorder := &MockAuditRecorder{}
+	service := courses.NewCourseService(mockRepo, courses.WithAuditRecorder(recorder))
+
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1, Title: "Go", Description: "Intro dañada", Duration: 20}, nil)
+	mockRepo.On("GetCourseRevision", int64(1), 1).Return(&domain.CourseRevision{
+		CourseID: 1, Number: 1, Title: "Go", Description: "Intro", Category: "Programación", Instructor: "Ana", Duration: 10, Requirement: "Nada",
+	}, nil)
+	mockRepo.On("UpdateCourse", int64(1), mock.MatchedBy(func(course domain.Course) bool {
+		return course.Description == "Intro" && course.Duration == 10
+	}), mock.AnythingOfType("domain.CourseRevision"), mock.MatchedBy(func(revision domain.CourseRevision) bool {
+		return revision.RestoredFrom == 1 && revision.Description == "Intro"
+	})).Return(nil)
+
+	// Act
+	course, err := service.RestoreRevision(1, 1, testActor)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "Intro", course.Description)
+	assert.Len(t, recorder.events, 1)
+	assert.Equal(t, domain.AuditRevisionRestored, recorder.events[0].Action)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"backend/domain"
+	"backend/services/courses"
+	"backend/services/users"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// staffActor es un usuario sin rol global de admin
+var staffActor = domain.Actor{UserID: 7, Role: domain.RoleInstructor, IP: "127.0.0.1"}
+
+func TestUpdateCourse_CoInstructorAllowed(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetCourseStaffMember", int64(1), int64(7)).Return(&domain.CourseStaff{CourseID: 1, UserID: 7, Role: domain.StaffCoInstructor}, nil)
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1, Title: "Old"}, nil)
+	mockRepo.On("UpdateCourse", int64(1), mock.AnythingOfType("domain.Course"), mock.AnythingOfType("domain.CourseRevision"), mock.AnythingOfType("domain.CourseRevision")).Return(nil)
+
+	// Act
+	err := service.UpdateCourse(1, "Title", "Description", "Category", "Instructor", 60, "Requirement", staffActor)
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateCourse_TeachingAssistantForbidden(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetCourseStaffMember", int64(1), int64(7)).Return(&domain.CourseStaff{CourseID: 1, UserID: 7, Role: domain.StaffTeachingAssistant}, nil)
+
+	// Act
+	err := service.UpdateCourse(1, "Title", "Description", "Category", "Instructor", 60, "Requirement", staffActor)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrCourseForbidden)
+	mockRepo.AssertNotCalled(t, "UpdateCourse", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDeleteCourse_NonStaffForbidden(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetCourseStaffMember", int64(1), int64(7)).Return(nil, nil)
+
+	// Act
+	err := service.DeleteCourse(1, staffActor)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrCourseForbidden)
+	mockRepo.AssertNotCalled(t, "TrashCourse", mock.Anything)
+}
+
+func TestAddStaff_OwnerInvites(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	recorder := &MockAuditRecorder{}
+	service := courses.NewCourseService(mockRepo, courses.WithAuditRecorder(recorder))
+
+	mockRepo.On("GetCourseStaffMember", int64(1), int64(7)).Return(&domain.CourseStaff{CourseID: 1, UserID: 7, Role: domain.StaffOwner}, nil)
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1}, nil)
+	mockRepo.On("GetUserById", int64(9)).Return(&domain.User{Id: 9}, nil)
+	mockRepo.On("GetCourseStaffMember", int64(1), int64(9)).Return(nil, nil)
+	mockRepo.On("SaveCourseStaff", mock.MatchedBy(func(staff domain.CourseStaff) bool {
+		return staff.CourseID == 1 && staff.UserID == 9 && staff.Role == domain.StaffTeachingAssistant && staff.AddedBy == 7
+	})).Return(nil)
+
+	// Act
+	err := service.AddStaff(1, 9, domain.StaffTeachingAssistant, staffActor)
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	assert.Len(t, recorder.events, 1)
+	assert.Equal(t, domain.AuditCourseStaffAdded, recorder.events[0].Action)
+}
+
+func TestAddStaff_InvalidRole(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	// Act
+	err := service.AddStaff(1, 9, "janitor", testActor)
+
+	// Assert
+	assert.Error(t, err)
+	mockRepo.AssertNotCalled(t, "SaveCourseStaff", mock.Anything)
+}
+
+func TestAddStaff_CoInstructorForbidden(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetCourseStaffMember", int64(1), int64(7)).Return(&domain.CourseStaff{CourseID: 1, UserID: 7, Role: domain.StaffCoInstructor}, nil)
+
+	// Act
+	err := service.AddStaff(1, 9, domain.StaffTeachingAssistant, staffActor)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrCourseForbidden)
+	mockRepo.AssertNotCalled(t, "SaveCourseStaff", mock.Anything)
+}
+
+func TestRemoveStaff_LastOwnerRejected(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	owner := domain.CourseStaff{CourseID: 1, UserID: 7, Role: domain.StaffOwner}
+	mockRepo.On("GetCourseStaffMember", int64(1), int64(7)).Return(&owner, nil)
+	mockRepo.On("GetCourseStaff", int64(1)).Return([]domain.CourseStaff{owner, {CourseID: 1, UserID: 9, Role: domain.StaffTeachingAssistant}}, nil)
+
+	// Act
+	err := service.RemoveStaff(1, 7, staffActor)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "at least one owner")
+	mockRepo.AssertNotCalled(t, "RemoveCourseStaff", mock.Anything, mock.Anything)
+}
+
+func TestRemoveStaff_AdminRemovesAssistant(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetCourseStaffMember", int64(1), int64(9)).Return(&domain.CourseStaff{CourseID: 1, UserID: 9, Role: domain.StaffTeachingAssistant}, nil)
+	mockRepo.On("RemoveCourseStaff", int64(1), int64(9)).Return(nil)
+
+	// Act
+	err := service.RemoveStaff(1, 9, testActor)
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUploadFiles_NonStaffForbidden(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1}, nil)
+	mockRepo.On("GetCourseStaffMember", int64(1), int64(7)).Return(nil, nil)
+
+	// Act
+	err := service.UploadFiles(bytes.NewBufferString("data"), "notes.pdf", 7, 1, staffActor)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrCourseForbidden)
+	mockRepo.AssertNotCalled(t, "SaveFile", mock.Anything)
+}
+
+func TestUploadFiles_UnknownCourse(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo, users.WithUploadsDir(t.TempDir()))
+
+	mockRepo.On("GetCourseById", int64(99)).Return(nil, errors.New("record not found"))
+
+	// Act
+	err := service.UploadFiles(bytes.NewBufferString("data"), "notes.pdf", 1, 99, testActor)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrCourseNotFound)
+	mockRepo.AssertNotCalled(t, "SaveFile", mock.Anything)
+}
+
+func TestUploadFiles_FailedRecordRemovesFile(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	dir := t.TempDir()
+	service := users.NewUserService(mockRepo, users.WithUploadsDir(dir))
+
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1}, nil)
+	mockRepo.On("SaveFile", mock.AnythingOfType("domain.File")).Return(errors.New("database error"))
+
+	// Act
+	err := service.UploadFiles(bytes.NewBufferString("data"), "notes.pdf", 1, 1, testActor)
+
+	// Assert
+	assert.Error(t, err)
+	entries, readErr := os.ReadDir(dir)
+	assert.NoError(t, readErr)
+	assert.Empty(t, entries)
+}
+
+func TestUploadFiles_SameNameInTwoCourses(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	dir := t.TempDir()
+	service := users.NewUserService(mockRepo, users.WithUploadsDir(dir))
+
+	var saved []domain.File
+	mockRepo.On("GetCourseById", mock.AnythingOfType("int64")).Return(&domain.Course{}, nil)
+	mockRepo.On("SaveFile", mock.AnythingOfType("domain.File")).Run(func(args mock.Arguments) {
+		saved = append(saved, args.Get(0).(domain.File))
+	}).Return(nil)
+
+	// Act
+	errA := service.UploadFiles(bytes.NewBufferString("course A"), "notes.pdf", 1, 1, testActor)
+	errB := service.UploadFiles(bytes.NewBufferString("course B"), "notes.pdf", 1, 2, testActor)
+
+	// Assert
+	assert.NoError(t, errA)
+	assert.NoError(t, errB)
+	assert.Len(t, saved, 2)
+	assert.Equal(t, "notes.pdf", saved[0].Name)
+	assert.Equal(t, "notes.pdf", saved[1].Name)
+	assert.NotEqual(t, saved[0].Url, saved[1].Url)
+
+	contentA, err := os.ReadFile(filepath.Join(dir, filepath.Base(saved[0].Url)))
+	assert.NoError(t, err)
+	assert.Equal(t, "course A", string(contentA))
+	contentB, err := os.ReadFile(filepath.Join(dir, filepath.Base(saved[1].Url)))
+	assert.NoError(t, err)
+	assert.Equal(t, "course B", string(contentB))
+}
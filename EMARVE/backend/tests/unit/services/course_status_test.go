@@ -0,0 +1,250 @@
+package services
+
+import (
+	"backend/domain"
+	"backend/services/courses"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestChangeCourseStatus_CoInstructorSubmitsForReview(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	recorder := &MockAuditRecorder{}
+	service := courses.NewCourseService(mockRepo, courses.WithAuditRecorder(recorder))
+
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1, Title: "Go", Status: domain.CourseDraft}, nil)
+	mockRepo.On("GetCourseStaffMember", int64(1), int64(7)).Return(&domain.CourseStaff{CourseID: 1, UserID: 7, Role: domain.StaffCoInstructor}, nil)
+	mockRepo.On("UpdateCourseStatus", int64(1), domain.CourseInReview, (*time.Time)(nil)).Return(nil)
+
+	// Act
+	course, err := service.ChangeCourseStatus(1, domain.CourseStatusRequest{Status: domain.CourseInReview}, staffActor)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, domain.CourseInReview, course.Status)
+	assert.Len(t, recorder.events, 1)
+	assert.Equal(t, domain.AuditCourseStatusChanged, recorder.events[0].Action)
+	assert.Equal(t, "draft -> review", recorder.events[0].Details)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestChangeCourseStatus_OnlyAdminsPublish(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1, Status: domain.CourseInReview}, nil)
+
+	// Act
+	_, err := service.ChangeCourseStatus(1, domain.CourseStatusRequest{Status: domain.CoursePublished}, staffActor)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrCourseForbidden)
+	mockRepo.AssertNotCalled(t, "UpdateCourseStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestChangeCourseStatus_ForbiddenBeforeTransitionCheck(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1, Status: domain.CourseArchived}, nil)
+	mockRepo.On("GetCourseStaffMember", int64(1), int64(5)).Return(nil, nil)
+
+	// Act
+	_, err := service.ChangeCourseStatus(1, domain.CourseStatusRequest{Status: domain.CourseInReview}, studentActor)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrCourseForbidden)
+	assert.NotContains(t, err.Error(), domain.CourseArchived)
+}
+
+func TestChangeCourseStatus_InvalidTransition(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1, Status: domain.CourseDraft}, nil)
+
+	// Act
+	_, err := service.ChangeCourseStatus(1, domain.CourseStatusRequest{Status: domain.CoursePublished}, testActor)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrInvalidStatusTransition)
+	mockRepo.AssertNotCalled(t, "UpdateCourseStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestChangeCourseStatus_SchedulesPublication(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+	publishAt := time.Now().Add(48 * time.Hour)
+
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1, Status: domain.CourseInReview}, nil)
+	mockRepo.On("UpdateCourseStatus", int64(1), domain.CoursePublished, &publishAt).Return(nil)
+
+	// Act
+	course, err := service.ChangeCourseStatus(1, domain.CourseStatusRequest{Status: domain.CoursePublished, PublishAt: &publishAt}, testActor)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, course.IsVisible(time.Now()))
+	assert.True(t, course.IsVisible(publishAt))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestChangeCourseStatus_PastPublicationDate(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+	publishAt := time.Now().Add(-time.Hour)
+
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1, Status: domain.CourseInReview}, nil)
+
+	// Act
+	_, err := service.ChangeCourseStatus(1, domain.CourseStatusRequest{Status: domain.CoursePublished, PublishAt: &publishAt}, testActor)
+
+	// Assert
+	assert.EqualError(t, err, "publish_at must be in the future")
+}
+
+func TestSubscription_ArchivedCourse(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetUserById", int64(1)).Return(&domain.User{Id: 1}, nil)
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1, Status: domain.CourseArchived}, nil)
+
+	// Act
+	err := service.Subscription(1, 1, testActor)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrCourseArchived)
+	mockRepo.AssertNotCalled(t, "InsertSubscription", mock.Anything, mock.Anything)
+}
+
+func TestSubscription_DraftCourse(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetUserById", int64(1)).Return(&domain.User{Id: 1}, nil)
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1, Status: domain.CourseDraft}, nil)
+
+	// Act
+	err := service.Subscription(1, 1, testActor)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrCourseNotPublished)
+	mockRepo.AssertNotCalled(t, "InsertSubscription", mock.Anything, mock.Anything)
+}
+
+func TestCreateLesson_ArchivedCourseIsReadOnly(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1, Status: domain.CourseArchived}, nil)
+
+	// Act
+	_, err := service.CreateLesson(1, domain.LessonRequest{ModuleID: 10, Title: "Nueva", Type: domain.LessonText, Content: "Hola"}, testActor)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrCourseArchived)
+	mockRepo.AssertNotCalled(t, "CreateLesson", mock.Anything)
+}
+
+func TestSearchCourse_StudentsSeeOnlyPublished(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	tomorrow := time.Now().Add(24 * time.Hour)
+	service := newIndexedCourseService(mockRepo,
+		domain.Course{Id: 1, Title: "Go inicial", Status: domain.CoursePublished},
+		domain.Course{Id: 2, Title: "Go avanzado", Status: domain.CourseDraft},
+		domain.Course{Id: 3, Title: "Go web", Status: domain.CoursePublished, PublishAt: &tomorrow},
+		domain.Course{Id: 4, Title: "Go y datos", Status: domain.CourseArchived},
+	)
+
+	mockRepo.On("GetStaffCourseIDs", int64(5)).Return([]int64{}, nil)
+
+	// Act
+	result, err := service.SearchCourse(domain.CourseQuery{Query: "go"}, studentActor)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), result.Total)
+	assert.Equal(t, 1, result.Result[0].Id)
+}
+
+func TestSearchCourse_StaffSeeTheirDrafts(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := newIndexedCourseService(mockRepo,
+		domain.Course{Id: 1, Title: "Go inicial", Status: domain.CoursePublished},
+		domain.Course{Id: 2, Title: "Go avanzado", Status: domain.CourseDraft},
+		domain.Course{Id: 3, Title: "Go web", Status: domain.CourseInReview},
+	)
+
+	mockRepo.On("GetStaffCourseIDs", int64(7)).Return([]int64{2}, nil)
+
+	// Act
+	result, err := service.SearchCourse(domain.CourseQuery{Query: "go", Sort: "title"}, staffActor)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), result.Total)
+	assert.Equal(t, 2, result.Result[0].Id)
+	assert.Equal(t, 1, result.Result[1].Id)
+}
+
+func TestGetAllCourses_RestrictsToStaffOfActor(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetCourses", mock.MatchedBy(func(query domain.CourseQuery) bool {
+		return !query.AllStatuses && query.StaffUserID == 7
+	})).Return([]domain.Course{}, int64(0), nil)
+
+	// Act
+	_, err := service.GetAllCourses(domain.CourseQuery{}, staffActor)
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetCourse_DraftHiddenFromAnonymous(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetCourseById", int64(2)).Return(&domain.Course{Id: 2, Status: domain.CourseDraft}, nil)
+
+	// Act
+	_, err := service.GetCourse(2, anonymousActor)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrCourseNotFound)
+}
+
+func TestGetCourse_StaffSeesDraft(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetCourseById", int64(2)).Return(&domain.Course{Id: 2, Status: domain.CourseDraft}, nil)
+	mockRepo.On("GetStaffCourseIDs", int64(7)).Return([]int64{2}, nil)
+
+	// Act
+	course, err := service.GetCourse(2, staffActor)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, domain.CourseDraft, course.Status)
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"backend/domain"
+	"backend/services/courses"
+	"backend/services/search"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// trashedCourse es un curso que está en la papelera desde hace age
+func trashedCourse(id int, age time.Duration) domain.Course {
+	return domain.Course{
+		Id:        id,
+		Title:     "Curso borrado",
+		Status:    domain.CoursePublished,
+		DeletedAt: gorm.DeletedAt{Time: time.Now().Add(-age), Valid: true},
+	}
+}
+
+func TestListTrash_Empty(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetTrashedCourses").Return(nil, nil)
+
+	// Act
+	trashed, err := service.ListTrash()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotNil(t, trashed)
+	assert.Empty(t, trashed)
+}
+
+func TestRestoreCourse_ReindexesAndAudits(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	recorder := &MockAuditRecorder{}
+	index := search.NewCourseIndex()
+	service := courses.NewCourseService(mockRepo, courses.WithSearchIndex(index), courses.WithAuditRecorder(recorder))
+
+	mockRepo.On("RestoreCourse", int64(3)).Return(nil)
+	mockRepo.On("GetCourseById", int64(3)).Return(&domain.Course{Id: 3, Title: "Fotografía", Status: domain.CoursePublished}, nil)
+
+	// Act
+	course, err := service.RestoreCourse(3, testActor)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 3, course.Id)
+	assert.Equal(t, 1, index.Len())
+	assert.Len(t, recorder.events, 1)
+	assert.Equal(t, domain.AuditCourseRestored, recorder.events[0].Action)
+}
+
+func TestRestoreCourse_NotInTrash(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("RestoreCourse", int64(3)).Return(errors.New("record not found"))
+
+	// Act
+	_, err := service.RestoreCourse(3, testActor)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrCourseNotFound)
+	mockRepo.AssertNotCalled(t, "GetCourseById", mock.Anything)
+}
+
+func TestPurgeTrash_OnlyExpiredCourses(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	recorder := &MockAuditRecorder{}
+	uploads := t.TempDir()
+	service := courses.NewCourseService(mockRepo, courses.WithUploadsDir(uploads), courses.WithAuditRecorder(recorder))
+
+	assert.NoError(t, os.WriteFile(filepath.Join(uploads, "apunte.pdf"), []byte("pdf"), 0o600))
+
+	mockRepo.On("GetTrashedCourses").Return([]domain.Course{
+		trashedCourse(1, time.Hour),
+		trashedCourse(2, 40*24*time.Hour),
+	}, nil)
+	mockRepo.On("PurgeCourse", int64(2)).Return([]domain.File{{Id: 9, Course_Id: 2, Url: "uploads/apunte.pdf"}}, nil)
+
+	// Act
+	purged, err := service.PurgeTrash(30 * 24 * time.Hour)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	mockRepo.AssertNotCalled(t, "PurgeCourse", int64(1))
+	assert.NoFileExists(t, filepath.Join(uploads, "apunte.pdf"))
+	assert.Len(t, recorder.events, 1)
+	assert.Equal(t, domain.AuditCoursePurged, recorder.events[0].Action)
+	assert.Equal(t, int64(0), recorder.events[0].ActorID)
+}
+
+func TestPurgeTrash_StopsOnError(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetTrashedCourses").Return([]domain.Course{
+		trashedCourse(1, 40*24*time.Hour),
+		trashedCourse(2, 40*24*time.Hour),
+	}, nil)
+	mockRepo.On("PurgeCourse", int64(1)).Return(nil, errors.New("database error"))
+
+	// Act
+	purged, err := service.PurgeTrash(30 * 24 * time.Hour)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, 0, purged)
+	mockRepo.AssertNotCalled(t, "PurgeCourse", int64(2))
+}
@@ -2,25 +2,112 @@ package services
 
 import (
 	"backend/domain"
+	"backend/interfaces"
 	"backend/services/courses"
+	"backend/services/search"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
+// anonymousActor es un visitante sin token: solo ve los cursos publicados
+var anonymousActor = domain.Actor{}
+
 // MockCourseRepository simula el repositorio de cursos
 type MockCourseRepository struct {
 	mock.Mock
 }
 
-func (m *MockCourseRepository) GetCoursewithQuery(query string) ([]domain.Course, error) {
-	args := m.Called(query)
+func (m *MockCourseRepository) IsSubscribed(userID, courseID int64) (bool, error) {
+	args := m.Called(userID, courseID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCourseRepository) GetFileById(fileID int64) (*domain.File, error) {
+	args := m.Called(fileID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]domain.Course), args.Error(1)
+	return args.Get(0).(*domain.File), args.Error(1)
+}
+
+func (m *MockCourseRepository) GetModules(courseID int64) ([]domain.CourseModule, error) {
+	args := m.Called(courseID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.CourseModule), args.Error(1)
+}
+
+func (m *MockCourseRepository) GetModuleById(moduleID int64) (*domain.CourseModule, error) {
+	args := m.Called(moduleID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CourseModule), args.Error(1)
+}
+
+func (m *MockCourseRepository) CreateModule(module domain.CourseModule) (domain.CourseModule, error) {
+	args := m.Called(module)
+	return args.Get(0).(domain.CourseModule), args.Error(1)
+}
+
+func (m *MockCourseRepository) UpdateModule(module domain.CourseModule) error {
+	args := m.Called(module)
+	return args.Error(0)
+}
+
+func (m *MockCourseRepository) DeleteModule(moduleID int64) error {
+	args := m.Called(moduleID)
+	return args.Error(0)
+}
+
+func (m *MockCourseRepository) GetLessons(courseID int64) ([]domain.Lesson, error) {
+	args := m.Called(courseID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Lesson), args.Error(1)
+}
+
+func (m *MockCourseRepository) GetLessonById(lessonID int64) (*domain.Lesson, error) {
+	args := m.Called(lessonID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Lesson), args.Error(1)
+}
+
+func (m *MockCourseRepository) CreateLesson(lesson domain.Lesson) (domain.Lesson, error) {
+	args := m.Called(lesson)
+	return args.Get(0).(domain.Lesson), args.Error(1)
+}
+
+func (m *MockCourseRepository) UpdateLesson(lesson domain.Lesson) error {
+	args := m.Called(lesson)
+	return args.Error(0)
+}
+
+func (m *MockCourseRepository) DeleteLesson(lessonID int64) error {
+	args := m.Called(lessonID)
+	return args.Error(0)
+}
+
+func (m *MockCourseRepository) ReorderLessons(courseID int64, lessonIDs []int64) error {
+	args := m.Called(courseID, lessonIDs)
+	return args.Error(0)
+}
+
+func (m *MockCourseRepository) GetSubscriptionCounts(courseIDs []int64) (map[int64]int64, error) {
+	args := m.Called(courseIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int64]int64), args.Error(1)
 }
 
 func (m *MockCourseRepository) GetCourseById(id int64) (*domain.Course, error) {
@@ -31,12 +118,12 @@ func (m *MockCourseRepository) GetCourseById(id int64) (*domain.Course, error) {
 	return args.Get(0).(*domain.Course), args.Error(1)
 }
 
-func (m *MockCourseRepository) GetCourses() ([]domain.Course, error) {
-	args := m.Called()
+func (m *MockCourseRepository) GetCourses(query domain.CourseQuery) ([]domain.Course, int64, error) {
+	args := m.Called(query)
 	if args.Get(0) == nil {
-		return nil, args.Error(1)
+		return nil, 0, args.Error(2)
 	}
-	return args.Get(0).([]domain.Course), args.Error(1)
+	return args.Get(0).([]domain.Course), args.Get(1).(int64), args.Error(2)
 }
 
 func (m *MockCourseRepository) GetUserById(userID int64) (*domain.User, error) {
@@ -52,26 +139,97 @@ func (m *MockCourseRepository) InsertSubscription(userID, courseID int64) error
 	return args.Error(0)
 }
 
-func (m *MockCourseRepository) CreateCourse(course domain.Course) error {
-	args := m.Called(course)
+func (m *MockCourseRepository) CreateCourse(course domain.Course, ownerID int64, revision domain.CourseRevision) (domain.Course, error) {
+	args := m.Called(course, ownerID, revision)
+	return args.Get(0).(domain.Course), args.Error(1)
+}
+
+func (m *MockCourseRepository) GetCourseStaffMember(courseID int64, userID int64) (*domain.CourseStaff, error) {
+	args := m.Called(courseID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CourseStaff), args.Error(1)
+}
+
+func (m *MockCourseRepository) GetCourseStaff(courseID int64) ([]domain.CourseStaff, error) {
+	args := m.Called(courseID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.CourseStaff), args.Error(1)
+}
+
+func (m *MockCourseRepository) SaveCourseStaff(staff domain.CourseStaff) error {
+	args := m.Called(staff)
+	return args.Error(0)
+}
+
+func (m *MockCourseRepository) RemoveCourseStaff(courseID int64, userID int64) error {
+	args := m.Called(courseID, userID)
+	return args.Error(0)
+}
+
+func (m *MockCourseRepository) UpdateCourse(courseID int64, course domain.Course, baseline, revision domain.CourseRevision) error {
+	args := m.Called(courseID, course, baseline, revision)
 	return args.Error(0)
 }
 
-func (m *MockCourseRepository) UpdateCourse(courseID int64, course domain.Course) error {
-	args := m.Called(courseID, course)
+func (m *MockCourseRepository) UpdateCourseStatus(courseID int64, status string, publishAt *time.Time) error {
+	args := m.Called(courseID, status, publishAt)
 	return args.Error(0)
 }
 
-func (m *MockCourseRepository) DeleteCourseById(courseID int64) error {
+func (m *MockCourseRepository) GetStaffCourseIDs(userID int64) ([]int64, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int64), args.Error(1)
+}
+
+func (m *MockCourseRepository) GetCourseRevisions(courseID int64) ([]domain.CourseRevision, error) {
+	args := m.Called(courseID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.CourseRevision), args.Error(1)
+}
+
+func (m *MockCourseRepository) GetCourseRevision(courseID int64, number int) (*domain.CourseRevision, error) {
+	args := m.Called(courseID, number)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CourseRevision), args.Error(1)
+}
+
+func (m *MockCourseRepository) TrashCourse(courseID int64) error {
 	args := m.Called(courseID)
 	return args.Error(0)
 }
 
-func (m *MockCourseRepository) DeleteSubscriptionById(courseID int64) error {
+func (m *MockCourseRepository) GetTrashedCourses() ([]domain.Course, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Course), args.Error(1)
+}
+
+func (m *MockCourseRepository) RestoreCourse(courseID int64) error {
 	args := m.Called(courseID)
 	return args.Error(0)
 }
 
+func (m *MockCourseRepository) PurgeCourse(courseID int64) ([]domain.File, error) {
+	args := m.Called(courseID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.File), args.Error(1)
+}
+
 func (m *MockCourseRepository) GetCommentsByCourseId(courseID int64) ([]int64, error) {
 	args := m.Called(courseID)
 	if args.Get(0) == nil {
@@ -101,66 +259,236 @@ func (m *MockCourseRepository) GetCourseImages(courseID int64) ([]domain.File, e
 	return args.Get(0).([]domain.File), args.Error(1)
 }
 
+// testActor es el admin que ejecuta las operaciones en los tests
+var testActor = domain.Actor{UserID: 1, Role: domain.RoleAdmin, Permissions: domain.DefaultRolePermissions[domain.RoleAdmin], IP: "127.0.0.1"}
+
 // Tests para SearchCourse
+
+func newIndexedCourseService(mockRepo *MockCourseRepository, courseList ...domain.Course) interfaces.CourseServiceInterface {
+	index := search.NewCourseIndex()
+	for _, course := range courseList {
+		index.Index(course)
+	}
+	return courses.NewCourseService(mockRepo, courses.WithSearchIndex(index))
+}
+
 func TestSearchCourse_Success(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockCourseRepository)
-	service := courses.NewCourseService(mockRepo)
+	service := newIndexedCourseService(mockRepo,
+		domain.Course{Id: 1, Title: "Cocina italiana", Description: "Pastas y salsas con programación de menús"},
+		domain.Course{Id: 2, Title: "Programación en Go", Description: "Aprendé a programar servicios"},
+		domain.Course{Id: 3, Title: "Diseño gráfico", Description: "Color y tipografía"},
+	)
 
-	expectedCourses := []domain.Course{
-		{Id: 1, Title: "Go Programming", Description: "Learn Go"},
-		{Id: 2, Title: "Advanced Go", Description: "Advanced Go concepts"},
-	}
+	// Act
+	result, err := service.SearchCourse(domain.CourseQuery{Query: "PROGRAMACION"}, testActor)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), result.Total)
+	assert.Len(t, result.Result, 2)
+	assert.Equal(t, 2, result.Result[0].Id, "the title match ranks first")
+	assert.Equal(t, 1, result.Result[1].Id)
+	assert.Equal(t, "<em>Programación</em> en Go", result.Highlights[0].Fields[domain.SearchFieldTitle])
+	assert.Greater(t, result.Highlights[0].Score, result.Highlights[1].Score)
+	mockRepo.AssertNotCalled(t, "GetCourses", mock.Anything)
+}
 
-	mockRepo.On("GetCoursewithQuery", "go").Return(expectedCourses, nil)
+func TestSearchCourse_EmptyQuery(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := newIndexedCourseService(mockRepo,
+		domain.Course{Id: 2, Title: "Course 2"},
+		domain.Course{Id: 1, Title: "Course 1"},
+	)
 
 	// Act
-	result, err := service.SearchCourse("go")
+	result, err := service.SearchCourse(domain.CourseQuery{Query: "   "}, testActor)
 
 	// Assert
 	assert.NoError(t, err)
-	assert.Len(t, result, 2)
-	assert.Equal(t, "Go Programming", result[0].Title)
-	assert.Equal(t, "Advanced Go", result[1].Title)
-	mockRepo.AssertExpectations(t)
+	assert.Len(t, result.Result, 2)
+	assert.Equal(t, 1, result.Result[0].Id)
+	assert.Nil(t, result.Highlights)
+	assert.NotNil(t, result.Facets)
 }
 
-func TestSearchCourse_EmptyQuery(t *testing.T) {
+func TestSearchCourse_FacetsRespectOtherFilters(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockCourseRepository)
-	service := courses.NewCourseService(mockRepo)
+	service := newIndexedCourseService(mockRepo,
+		domain.Course{Id: 1, Title: "Go inicial", Category: "Programación", Instructor: "Ana", Duration: 5},
+		domain.Course{Id: 2, Title: "Go web", Category: "programacion", Instructor: "Luis", Duration: 20},
+		domain.Course{Id: 3, Title: "Go y datos", Category: "Datos", Instructor: "Ana", Duration: 45},
+		domain.Course{Id: 4, Title: "Go para diseño", Category: "Diseño", Instructor: "Luis", Duration: 90},
+		domain.Course{Id: 5, Title: "Dibujo", Category: "Diseño", Instructor: "Ana", Duration: 10},
+	)
 
-	expectedCourses := []domain.Course{
-		{Id: 1, Title: "Course 1"},
-		{Id: 2, Title: "Course 2"},
-	}
+	// Act
+	result, err := service.SearchCourse(domain.CourseQuery{
+		Query:           "go",
+		Categories:      []string{"programacion", "Datos"},
+		DurationBuckets: []string{"0-9", "30-59"},
+	}, testActor)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), result.Total)
+	assert.Equal(t, 1, result.Result[0].Id)
+	assert.Equal(t, 3, result.Result[1].Id)
+
+	// La categoría cuenta con la duración seleccionada pero sin la selección de categoría
+	assert.Equal(t, []domain.FacetValue{
+		{Value: "Datos", Count: 1, Selected: true},
+		{Value: "Programación", Count: 1, Selected: true},
+	}, result.Facets.Category)
+
+	// El instructor cuenta con las dos selecciones
+	assert.Equal(t, []domain.FacetValue{{Value: "Ana", Count: 2}}, result.Facets.Instructor)
+
+	// La duración cuenta con la categoría seleccionada pero sin la selección de duración
+	assert.Equal(t, []domain.FacetValue{
+		{Value: "0-9", Count: 1, Selected: true},
+		{Value: "10-29", Count: 1},
+		{Value: "30-59", Count: 1, Selected: true},
+		{Value: "60+", Count: 0},
+	}, result.Facets.Duration)
+}
 
-	mockRepo.On("GetCoursewithQuery", "").Return(expectedCourses, nil)
+func TestSearchCourse_FiltersAndPaginates(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := newIndexedCourseService(mockRepo,
+		domain.Course{Id: 1, Title: "Go inicial", Category: "Programación", Duration: 10},
+		domain.Course{Id: 2, Title: "Go avanzado", Category: "programacion", Duration: 40},
+		domain.Course{Id: 3, Title: "Go concurrente", Category: "Programación", Duration: 60},
+		domain.Course{Id: 4, Title: "Go para diseñadores", Category: "Diseño", Duration: 30},
+	)
 
 	// Act
-	result, err := service.SearchCourse("   ")
+	result, err := service.SearchCourse(domain.CourseQuery{
+		Query:       "go",
+		Categories:  []string{"PROGRAMACIÓN"},
+		MinDuration: 20,
+		Sort:        "-duration",
+		PageSize:    1,
+		Page:        2,
+	}, testActor)
 
 	// Assert
 	assert.NoError(t, err)
-	assert.Len(t, result, 2)
+	assert.Equal(t, int64(2), result.Total)
+	assert.Len(t, result.Result, 1)
+	assert.Equal(t, 2, result.Result[0].Id)
+}
+
+func TestSearchCourse_SortByPopularity(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := newIndexedCourseService(mockRepo,
+		domain.Course{Id: 1, Title: "Python"},
+		domain.Course{Id: 2, Title: "Python para datos"},
+	)
+	mockRepo.On("GetSubscriptionCounts", mock.AnythingOfType("[]int64")).Return(map[int64]int64{2: 15, 1: 3}, nil)
+
+	// Act
+	result, err := service.SearchCourse(domain.CourseQuery{Query: "python", Sort: "-popularity"}, testActor)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Result[0].Id)
+	assert.Equal(t, 1, result.Result[1].Id)
 	mockRepo.AssertExpectations(t)
 }
 
 func TestSearchCourse_Error(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockCourseRepository)
-	service := courses.NewCourseService(mockRepo)
+	service := newIndexedCourseService(mockRepo, domain.Course{Id: 1, Title: "Go"})
 
-	mockRepo.On("GetCoursewithQuery", "invalid").Return(nil, errors.New("database error"))
+	mockRepo.On("GetSubscriptionCounts", mock.AnythingOfType("[]int64")).Return(nil, errors.New("database error"))
 
 	// Act
-	result, err := service.SearchCourse("invalid")
+	result, err := service.SearchCourse(domain.CourseQuery{Query: "go", Sort: "popularity"}, testActor)
 
 	// Assert
 	assert.Error(t, err)
-	assert.Nil(t, result)
-	assert.Contains(t, err.Error(), "error getting courses from DB")
-	mockRepo.AssertExpectations(t)
+	assert.Nil(t, result.Result)
+	assert.Contains(t, err.Error(), "error getting subscription counts from DB")
+}
+
+func TestSearchCourse_DidYouMean(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := newIndexedCourseService(mockRepo, domain.Course{Id: 1, Title: "Fotografía digital"})
+
+	// Act
+	result, err := service.SearchCourse(domain.CourseQuery{Query: "fotografia digitla"}, testActor)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), result.Total)
+	assert.Empty(t, result.DidYouMean)
+
+	result, err = service.SearchCourse(domain.CourseQuery{Query: "fotogarfia"}, testActor)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), result.Total)
+	assert.Equal(t, "fotografía", result.DidYouMean)
+}
+
+func TestSuggestCourses_LimitsResults(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	courseList := make([]domain.Course, 0)
+	for id := 1; id <= 30; id++ {
+		courseList = append(courseList, domain.Course{Id: id, Title: fmt.Sprintf("Curso %d", id)})
+	}
+	service := newIndexedCourseService(mockRepo, courseList...)
+
+	// Act
+	defaults, err := service.SuggestCourses("curso", 0, testActor)
+	assert.NoError(t, err)
+	capped, err := service.SuggestCourses("curso", 500, testActor)
+	assert.NoError(t, err)
+	_, missing := service.SuggestCourses("  ", 5, testActor)
+
+	// Assert
+	assert.Len(t, defaults, courses.DefaultSuggestions)
+	assert.Len(t, capped, courses.MaxSuggestions)
+	assert.Error(t, missing)
+}
+
+func TestSearchIndex_FollowsCourseChanges(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	created := domain.Course{Id: 9, Title: "Guitarra", Description: "Acordes", Category: "Música", Instructor: "Ana", Duration: 10, Requirement: "Ninguno"}
+	mockRepo.On("CreateCourse", mock.AnythingOfType("domain.Course"), testActor.UserID, mock.AnythingOfType("domain.CourseRevision")).Return(created, nil)
+	mockRepo.On("GetCourseById", int64(9)).Return(&created, nil)
+	mockRepo.On("UpdateCourse", int64(9), mock.AnythingOfType("domain.Course"), mock.AnythingOfType("domain.CourseRevision"), mock.AnythingOfType("domain.CourseRevision")).Return(nil)
+	mockRepo.On("TrashCourse", int64(9)).Return(nil)
+
+	count := func(text string) int64 {
+		result, err := service.SearchCourse(domain.CourseQuery{Query: text}, testActor)
+		assert.NoError(t, err)
+		return result.Total
+	}
+
+	// Act & Assert
+	_, err := service.CreateCourse("Guitarra", "Acordes", "Música", "Ana", 10, "Ninguno", testActor)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count("guitarras"))
+
+	err = service.UpdateCourse(9, "Piano", "Acordes", "Música", "Ana", 10, "Ninguno", testActor)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count("guitarra"))
+	assert.Equal(t, int64(1), count("piano"))
+
+	err = service.DeleteCourse(9, testActor)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count("piano"))
 }
 
 // Tests para GetCourse
@@ -182,7 +510,7 @@ func TestGetCourse_Success(t *testing.T) {
 	mockRepo.On("GetCourseById", int64(1)).Return(expectedCourse, nil)
 
 	// Act
-	result, err := service.GetCourse(1)
+	result, err := service.GetCourse(1, anonymousActor)
 
 	// Assert
 	assert.NoError(t, err)
@@ -200,7 +528,7 @@ func TestGetCourse_NotFound(t *testing.T) {
 	mockRepo.On("GetCourseById", int64(999)).Return(nil, errors.New("course not found"))
 
 	// Act
-	result, err := service.GetCourse(999)
+	result, err := service.GetCourse(999, anonymousActor)
 
 	// Assert
 	assert.Error(t, err)
@@ -220,16 +548,16 @@ func TestGetAllCourses_Success(t *testing.T) {
 		{Id: 2, Title: "Course 2", Description: "Description 2"},
 	}
 
-	mockRepo.On("GetCourses").Return(expectedCourses, nil)
+	mockRepo.On("GetCourses", domain.CourseQuery{Page: 1, PageSize: courses.DefaultCoursesPageSize}).Return(expectedCourses, int64(2), nil)
 
 	// Act
-	result, err := service.GetAllCourses()
+	result, err := service.GetAllCourses(domain.CourseQuery{}, anonymousActor)
 
 	// Assert
 	assert.NoError(t, err)
-	assert.Len(t, result, 2)
-	assert.Equal(t, "Course 1", result[0].Title)
-	assert.Equal(t, "Course 2", result[1].Title)
+	assert.Len(t, result.Result, 2)
+	assert.Equal(t, "Course 1", result.Result[0].Title)
+	assert.Equal(t, "Course 2", result.Result[1].Title)
 	mockRepo.AssertExpectations(t)
 }
 
@@ -238,18 +566,71 @@ func TestGetAllCourses_Error(t *testing.T) {
 	mockRepo := new(MockCourseRepository)
 	service := courses.NewCourseService(mockRepo)
 
-	mockRepo.On("GetCourses").Return(nil, errors.New("database error"))
+	mockRepo.On("GetCourses", mock.AnythingOfType("domain.CourseQuery")).Return(nil, int64(0), errors.New("database error"))
 
 	// Act
-	result, err := service.GetAllCourses()
+	result, err := service.GetAllCourses(domain.CourseQuery{}, anonymousActor)
 
 	// Assert
 	assert.Error(t, err)
-	assert.Nil(t, result)
+	assert.Nil(t, result.Result)
 	assert.Contains(t, err.Error(), "error getting courses from DB")
 	mockRepo.AssertExpectations(t)
 }
 
+func TestGetAllCourses_FiltersAndPageSize(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	expectedQuery := domain.CourseQuery{
+		Categories:      []string{"Programming", "Data"},
+		Instructors:     []string{"John Doe"},
+		DurationBuckets: []string{"10-29"},
+		MinDuration:     10,
+		MaxDuration:     60,
+		Sort:            "-popularity",
+		Page:            3,
+		PageSize:        courses.MaxCoursesPageSize,
+	}
+	mockRepo.On("GetCourses", expectedQuery).Return([]domain.Course{}, int64(250), nil)
+
+	// Act
+	result, err := service.GetAllCourses(domain.CourseQuery{
+		Query:           "ignored",
+		Categories:      []string{" Programming ", "Data", " "},
+		Instructors:     []string{"John Doe"},
+		DurationBuckets: []string{"10-29"},
+		MinDuration:     10,
+		MaxDuration:     60,
+		Sort:            "-popularity",
+		Page:            3,
+		PageSize:        500,
+	}, anonymousActor)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(250), result.Total)
+	assert.Equal(t, 3, result.Page)
+	assert.Equal(t, courses.MaxCoursesPageSize, result.PageSize)
+	assert.NotNil(t, result.Result)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetAllCourses_InvalidQuery(t *testing.T) {
+	service := courses.NewCourseService(new(MockCourseRepository))
+
+	for _, query := range []domain.CourseQuery{
+		{Sort: "nickname"},
+		{MinDuration: 60, MaxDuration: 10},
+		{MinDuration: -1},
+		{DurationBuckets: []string{"forever"}},
+	} {
+		_, err := service.GetAllCourses(query, anonymousActor)
+		assert.ErrorIs(t, err, domain.ErrInvalidCourseQuery)
+	}
+}
+
 // Tests para Subscription
 func TestSubscription_Success(t *testing.T) {
 	// Arrange
@@ -264,7 +645,7 @@ func TestSubscription_Success(t *testing.T) {
 	mockRepo.On("InsertSubscription", int64(1), int64(1)).Return(nil)
 
 	// Act
-	err := service.Subscription(1, 1)
+	err := service.Subscription(1, 1, testActor)
 
 	// Assert
 	assert.NoError(t, err)
@@ -279,7 +660,7 @@ func TestSubscription_UserNotFound(t *testing.T) {
 	mockRepo.On("GetUserById", int64(999)).Return(nil, errors.New("user not found"))
 
 	// Act
-	err := service.Subscription(999, 1)
+	err := service.Subscription(999, 1, testActor)
 
 	// Assert
 	assert.Error(t, err)
@@ -298,7 +679,7 @@ func TestSubscription_CourseNotFound(t *testing.T) {
 	mockRepo.On("GetCourseById", int64(999)).Return(nil, errors.New("course not found"))
 
 	// Act
-	err := service.Subscription(1, 999)
+	err := service.Subscription(1, 999, testActor)
 
 	// Assert
 	assert.Error(t, err)
@@ -319,7 +700,7 @@ func TestSubscription_InsertError(t *testing.T) {
 	mockRepo.On("InsertSubscription", int64(1), int64(1)).Return(errors.New("subscription exists"))
 
 	// Act
-	err := service.Subscription(1, 1)
+	err := service.Subscription(1, 1, testActor)
 
 	// Assert
 	assert.Error(t, err)
@@ -335,13 +716,16 @@ func TestCreateCourse_Success(t *testing.T) {
 
 	mockRepo.On("CreateCourse", mock.MatchedBy(func(course domain.Course) bool {
 		return course.Title == "New Course" && course.Description == "Course Description"
-	})).Return(nil)
+	}), int64(1), mock.MatchedBy(func(revision domain.CourseRevision) bool {
+		return revision.Title == "New Course" && revision.AuthorID == testActor.UserID
+	})).Return(domain.Course{Id: 3, Title: "New Course"}, nil)
 
 	// Act
-	err := service.CreateCourse("New Course", "Course Description", "Programming", "John Doe", 60, "Basic knowledge")
+	created, err := service.CreateCourse("New Course", "Course Description", "Programming", "John Doe", 60, "Basic knowledge", testActor)
 
 	// Assert
 	assert.NoError(t, err)
+	assert.Equal(t, 3, created.Id)
 	mockRepo.AssertExpectations(t)
 }
 
@@ -351,7 +735,7 @@ func TestCreateCourse_MissingTitle(t *testing.T) {
 	service := courses.NewCourseService(mockRepo)
 
 	// Act
-	err := service.CreateCourse("", "Description", "Category", "Instructor", 60, "Requirement")
+	_, err := service.CreateCourse("", "Description", "Category", "Instructor", 60, "Requirement", testActor)
 
 	// Assert
 	assert.Error(t, err)
@@ -365,7 +749,7 @@ func TestCreateCourse_MissingDescription(t *testing.T) {
 	service := courses.NewCourseService(mockRepo)
 
 	// Act
-	err := service.CreateCourse("Title", "", "Category", "Instructor", 60, "Requirement")
+	_, err := service.CreateCourse("Title", "", "Category", "Instructor", 60, "Requirement", testActor)
 
 	// Assert
 	assert.Error(t, err)
@@ -379,7 +763,7 @@ func TestCreateCourse_MissingCategory(t *testing.T) {
 	service := courses.NewCourseService(mockRepo)
 
 	// Act
-	err := service.CreateCourse("Title", "Description", "", "Instructor", 60, "Requirement")
+	_, err := service.CreateCourse("Title", "Description", "", "Instructor", 60, "Requirement", testActor)
 
 	// Assert
 	assert.Error(t, err)
@@ -393,7 +777,7 @@ func TestCreateCourse_MissingInstructor(t *testing.T) {
 	service := courses.NewCourseService(mockRepo)
 
 	// Act
-	err := service.CreateCourse("Title", "Description", "Category", "", 60, "Requirement")
+	_, err := service.CreateCourse("Title", "Description", "Category", "", 60, "Requirement", testActor)
 
 	// Assert
 	assert.Error(t, err)
@@ -407,7 +791,7 @@ func TestCreateCourse_ZeroDuration(t *testing.T) {
 	service := courses.NewCourseService(mockRepo)
 
 	// Act
-	err := service.CreateCourse("Title", "Description", "Category", "Instructor", 0, "Requirement")
+	_, err := service.CreateCourse("Title", "Description", "Category", "Instructor", 0, "Requirement", testActor)
 
 	// Assert
 	assert.Error(t, err)
@@ -421,7 +805,7 @@ func TestCreateCourse_MissingRequirement(t *testing.T) {
 	service := courses.NewCourseService(mockRepo)
 
 	// Act
-	err := service.CreateCourse("Title", "Description", "Category", "Instructor", 60, "")
+	_, err := service.CreateCourse("Title", "Description", "Category", "Instructor", 60, "", testActor)
 
 	// Assert
 	assert.Error(t, err)
@@ -434,10 +818,10 @@ func TestCreateCourse_DatabaseError(t *testing.T) {
 	mockRepo := new(MockCourseRepository)
 	service := courses.NewCourseService(mockRepo)
 
-	mockRepo.On("CreateCourse", mock.AnythingOfType("domain.Course")).Return(errors.New("database error"))
+	mockRepo.On("CreateCourse", mock.AnythingOfType("domain.Course"), int64(1), mock.AnythingOfType("domain.CourseRevision")).Return(domain.Course{}, errors.New("database error"))
 
 	// Act
-	err := service.CreateCourse("Title", "Description", "Category", "Instructor", 60, "Requirement")
+	_, err := service.CreateCourse("Title", "Description", "Category", "Instructor", 60, "Requirement", testActor)
 
 	// Assert
 	assert.Error(t, err)
@@ -451,12 +835,13 @@ func TestUpdateCourse_Success(t *testing.T) {
 	mockRepo := new(MockCourseRepository)
 	service := courses.NewCourseService(mockRepo)
 
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1, Title: "Old Course"}, nil)
 	mockRepo.On("UpdateCourse", int64(1), mock.MatchedBy(func(course domain.Course) bool {
 		return course.Title == "Updated Course" && course.Description == "Updated Description"
-	})).Return(nil)
+	}), mock.AnythingOfType("domain.CourseRevision"), mock.AnythingOfType("domain.CourseRevision")).Return(nil)
 
 	// Act
-	err := service.UpdateCourse(1, "Updated Course", "Updated Description", "Programming", "Jane Doe", 90, "Advanced knowledge")
+	err := service.UpdateCourse(1, "Updated Course", "Updated Description", "Programming", "Jane Doe", 90, "Advanced knowledge", testActor)
 
 	// Assert
 	assert.NoError(t, err)
@@ -469,7 +854,7 @@ func TestUpdateCourse_MissingTitle(t *testing.T) {
 	service := courses.NewCourseService(mockRepo)
 
 	// Act
-	err := service.UpdateCourse(1, "", "Description", "Category", "Instructor", 60, "Requirement")
+	err := service.UpdateCourse(1, "", "Description", "Category", "Instructor", 60, "Requirement", testActor)
 
 	// Assert
 	assert.Error(t, err)
@@ -482,10 +867,11 @@ func TestUpdateCourse_DatabaseError(t *testing.T) {
 	mockRepo := new(MockCourseRepository)
 	service := courses.NewCourseService(mockRepo)
 
-	mockRepo.On("UpdateCourse", int64(1), mock.AnythingOfType("domain.Course")).Return(errors.New("database error"))
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1, Title: "Old Course"}, nil)
+	mockRepo.On("UpdateCourse", int64(1), mock.AnythingOfType("domain.Course"), mock.AnythingOfType("domain.CourseRevision"), mock.AnythingOfType("domain.CourseRevision")).Return(errors.New("database error"))
 
 	// Act
-	err := service.UpdateCourse(1, "Title", "Description", "Category", "Instructor", 60, "Requirement")
+	err := service.UpdateCourse(1, "Title", "Description", "Category", "Instructor", 60, "Requirement", testActor)
 
 	// Assert
 	assert.Error(t, err)
@@ -499,11 +885,11 @@ func TestDeleteCourse_Success(t *testing.T) {
 	mockRepo := new(MockCourseRepository)
 	service := courses.NewCourseService(mockRepo)
 
-	mockRepo.On("DeleteCourseById", int64(1)).Return(nil)
-	mockRepo.On("DeleteSubscriptionById", int64(1)).Return(nil)
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1, Title: "Test Course"}, nil)
+	mockRepo.On("TrashCourse", int64(1)).Return(nil)
 
 	// Act
-	err := service.DeleteCourse(1)
+	err := service.DeleteCourse(1, testActor)
 
 	// Assert
 	assert.NoError(t, err)
@@ -515,10 +901,11 @@ func TestDeleteCourse_CourseDeleteError(t *testing.T) {
 	mockRepo := new(MockCourseRepository)
 	service := courses.NewCourseService(mockRepo)
 
-	mockRepo.On("DeleteCourseById", int64(1)).Return(errors.New("course not found"))
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1, Title: "Test Course"}, nil)
+	mockRepo.On("TrashCourse", int64(1)).Return(errors.New("course not found"))
 
 	// Act
-	err := service.DeleteCourse(1)
+	err := service.DeleteCourse(1, testActor)
 
 	// Assert
 	assert.Error(t, err)
@@ -526,23 +913,6 @@ func TestDeleteCourse_CourseDeleteError(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
-func TestDeleteCourse_SubscriptionDeleteError(t *testing.T) {
-	// Arrange
-	mockRepo := new(MockCourseRepository)
-	service := courses.NewCourseService(mockRepo)
-
-	mockRepo.On("DeleteCourseById", int64(1)).Return(nil)
-	mockRepo.On("DeleteSubscriptionById", int64(1)).Return(errors.New("subscription delete error"))
-
-	// Act
-	err := service.DeleteCourse(1)
-
-	// Assert
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "error deleting subscriptcion in DB")
-	mockRepo.AssertExpectations(t)
-}
-
 // Tests para CommentList
 func TestCommentList_Success(t *testing.T) {
 	// Arrange
@@ -553,12 +923,13 @@ func TestCommentList_Success(t *testing.T) {
 	comment1 := domain.Comment{Id: 1, UserID: 1, Comment: "Great course!"}
 	comment2 := domain.Comment{Id: 2, UserID: 2, Comment: "Very helpful"}
 
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1, Status: domain.CoursePublished}, nil)
 	mockRepo.On("GetCommentsByCourseId", int64(1)).Return(commentIDs, nil)
 	mockRepo.On("GetCommentById", int64(1)).Return(comment1, nil)
 	mockRepo.On("GetCommentById", int64(2)).Return(comment2, nil)
 
 	// Act
-	result, err := service.CommentList(1)
+	result, err := service.CommentList(1, anonymousActor)
 
 	// Assert
 	assert.NoError(t, err)
@@ -575,10 +946,11 @@ func TestCommentList_NoComments(t *testing.T) {
 	mockRepo := new(MockCourseRepository)
 	service := courses.NewCourseService(mockRepo)
 
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1, Status: domain.CoursePublished}, nil)
 	mockRepo.On("GetCommentsByCourseId", int64(1)).Return([]int64{}, nil)
 
 	// Act
-	result, err := service.CommentList(1)
+	result, err := service.CommentList(1, anonymousActor)
 
 	// Assert
 	assert.NoError(t, err)
@@ -591,10 +963,11 @@ func TestCommentList_GetCommentsError(t *testing.T) {
 	mockRepo := new(MockCourseRepository)
 	service := courses.NewCourseService(mockRepo)
 
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1, Status: domain.CoursePublished}, nil)
 	mockRepo.On("GetCommentsByCourseId", int64(1)).Return(nil, errors.New("database error"))
 
 	// Act
-	result, err := service.CommentList(1)
+	result, err := service.CommentList(1, anonymousActor)
 
 	// Assert
 	assert.Error(t, err)
@@ -609,11 +982,12 @@ func TestCommentList_GetCommentError(t *testing.T) {
 	service := courses.NewCourseService(mockRepo)
 
 	commentIDs := []int64{1}
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1, Status: domain.CoursePublished}, nil)
 	mockRepo.On("GetCommentsByCourseId", int64(1)).Return(commentIDs, nil)
 	mockRepo.On("GetCommentById", int64(1)).Return(domain.Comment{}, errors.New("comment not found"))
 
 	// Act
-	result, err := service.CommentList(1)
+	result, err := service.CommentList(1, anonymousActor)
 
 	// Assert
 	assert.Error(t, err)
@@ -622,6 +996,37 @@ func TestCommentList_GetCommentError(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestCommentList_DraftHiddenFromAnonymous(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1, Status: domain.CourseDraft}, nil)
+
+	// Act
+	_, err := service.CommentList(1, anonymousActor)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrCourseNotFound)
+	mockRepo.AssertNotCalled(t, "GetCommentsByCourseId", mock.Anything)
+}
+
+func TestListStaff_DraftHiddenFromStudent(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCourseRepository)
+	service := courses.NewCourseService(mockRepo)
+
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1, Status: domain.CourseDraft}, nil)
+	mockRepo.On("GetStaffCourseIDs", studentActor.UserID).Return([]int64{}, nil)
+
+	// Act
+	_, err := service.ListStaff(1, studentActor)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrCourseNotFound)
+	mockRepo.AssertNotCalled(t, "GetCourseStaff", mock.Anything)
+}
+
 // Tests para nuevos métodos
 
 func TestGetCoursesByInstructor_Success(t *testing.T) {
@@ -636,7 +1041,7 @@ func TestGetCoursesByInstructor_Success(t *testing.T) {
 	mockRepo.On("GetCoursesByInstructor", "profesor1").Return(expectedCourses, nil)
 
 	// Ejecutar
-	result, err := service.GetCoursesByInstructor("profesor1")
+	result, err := service.GetCoursesByInstructor("profesor1", testActor)
 
 	// Verificar
 	assert.NoError(t, err)
@@ -649,7 +1054,7 @@ func TestGetCoursesByInstructor_EmptyInstructor(t *testing.T) {
 	service := courses.NewCourseService(mockRepo)
 
 	// Ejecutar con instructor vacío
-	result, err := service.GetCoursesByInstructor("")
+	result, err := service.GetCoursesByInstructor("", testActor)
 
 	// Verificar
 	assert.Error(t, err)
@@ -665,7 +1070,7 @@ func TestGetCoursesByInstructor_RepositoryError(t *testing.T) {
 	mockRepo.On("GetCoursesByInstructor", "profesor1").Return(nil, errors.New("database error"))
 
 	// Ejecutar
-	result, err := service.GetCoursesByInstructor("profesor1")
+	result, err := service.GetCoursesByInstructor("profesor1", testActor)
 
 	// Verificar
 	assert.Error(t, err)
@@ -683,10 +1088,11 @@ func TestGetCourseImages_Success(t *testing.T) {
 		{Id: 1, Name: "imagen1.jpg", Course_Id: 1},
 		{Id: 2, Name: "documento.pdf", Course_Id: 1},
 	}
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1}, nil)
 	mockRepo.On("GetCourseImages", int64(1)).Return(expectedFiles, nil)
 
 	// Ejecutar
-	result, err := service.GetCourseImages(1)
+	result, err := service.GetCourseImages(1, testActor)
 
 	// Verificar
 	assert.NoError(t, err)
@@ -699,7 +1105,7 @@ func TestGetCourseImages_InvalidCourseID(t *testing.T) {
 	service := courses.NewCourseService(mockRepo)
 
 	// Ejecutar con ID inválido
-	result, err := service.GetCourseImages(0)
+	result, err := service.GetCourseImages(0, testActor)
 
 	// Verificar
 	assert.Error(t, err)
@@ -712,10 +1118,11 @@ func TestGetCourseImages_RepositoryError(t *testing.T) {
 	service := courses.NewCourseService(mockRepo)
 
 	// Configurar mock para error
+	mockRepo.On("GetCourseById", int64(1)).Return(&domain.Course{Id: 1}, nil)
 	mockRepo.On("GetCourseImages", int64(1)).Return(nil, errors.New("database error"))
 
 	// Ejecutar
-	result, err := service.GetCourseImages(1)
+	result, err := service.GetCourseImages(1, testActor)
 
 	// Verificar
 	assert.Error(t, err)
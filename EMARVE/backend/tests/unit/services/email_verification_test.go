@@ -0,0 +1,123 @@
+package services
+
+import (
+	"backend/domain"
+	"backend/services/users"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLogin_UnverifiedEmailIsRejected(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	hasher := newTestHasher(t)
+	service := users.NewUserService(mockRepo, users.WithPasswordHasher(hasher))
+
+	hash, err := hasher.Hash("password123")
+	assert.NoError(t, err)
+	mockRepo.On("GetUserByEmail", "new@example.com").Return(&domain.User{Id: 5, Email: "new@example.com", PasswordHash: hash}, nil)
+
+	// Act
+	response, err := service.Login("new@example.com", "password123", domain.ClientInfo{})
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrEmailNotVerified)
+	assert.Empty(t, response.Token)
+	mockRepo.AssertNotCalled(t, "CreateSession", mock.Anything)
+}
+
+func TestLogin_UnverifiedEmailWithWarnPolicy(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	hasher := newTestHasher(t)
+	service := users.NewUserService(mockRepo, users.WithPasswordHasher(hasher), users.WithVerificationPolicy(users.VerificationWarn))
+
+	hash, err := hasher.Hash("password123")
+	assert.NoError(t, err)
+	mockRepo.On("GetUserByEmail", "new@example.com").Return(&domain.User{Id: 5, Email: "new@example.com", PasswordHash: hash}, nil)
+	mockRepo.On("GetTwoFactor", mock.AnythingOfType("int64")).Return(nil, nil)
+	mockRepo.On("CreateSession", mock.AnythingOfType("domain.Session")).Return(domain.Session{Id: 10}, nil)
+
+	// Act
+	response, err := service.Login("new@example.com", "password123", domain.ClientInfo{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotEmpty(t, response.Token)
+	assert.False(t, response.EmailVerified)
+}
+
+func TestVerifyEmail_ActivatesAccount(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+	mockRepo.On("GetUserTokenByHash", sha256Hex("verify-token")).Return(&domain.UserToken{
+		Id:        8,
+		UserID:    5,
+		Purpose:   domain.TokenPurposeEmailVerification,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+	mockRepo.On("ConsumeUserToken", int64(8), mock.AnythingOfType("time.Time")).Return(nil)
+	mockRepo.On("SetEmailVerified", int64(5), mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Act
+	err := service.VerifyEmail("verify-token")
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestVerifyEmail_ResetTokenIsRejected(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+	mockRepo.On("GetUserTokenByHash", sha256Hex("reset-token")).Return(&domain.UserToken{
+		Id:        7,
+		UserID:    5,
+		Purpose:   domain.TokenPurposePasswordReset,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+
+	// Act
+	err := service.VerifyEmail("reset-token")
+
+	// Assert
+	assert.Error(t, err)
+	mockRepo.AssertNotCalled(t, "SetEmailVerified", mock.Anything, mock.Anything)
+}
+
+func TestResendVerification_AlreadyVerified(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockMailer := new(MockMailer)
+	service := users.NewUserService(mockRepo, users.WithMailer(mockMailer))
+	mockRepo.On("GetUserById", int64(5)).Return(&domain.User{Id: 5, EmailVerifiedAt: verifiedNow()}, nil)
+
+	// Act
+	err := service.ResendVerification(5)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "already verified")
+	mockMailer.AssertNotCalled(t, "Send", mock.Anything)
+}
+
+func TestMarkEmailVerified_InvalidatesPendingLinks(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+	mockRepo.On("GetUserById", int64(5)).Return(&domain.User{Id: 5}, nil)
+	mockRepo.On("SetEmailVerified", int64(5), mock.AnythingOfType("time.Time")).Return(nil)
+	mockRepo.On("InvalidateUserTokens", int64(5), domain.TokenPurposeEmailVerification, mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Act
+	err := service.MarkEmailVerified(5, testActor)
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"backend/domain"
+	"backend/services/throttle"
+	"backend/services/users"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func testLimiterConfig() throttle.Config {
+	config := throttle.DefaultConfig()
+	config.Account.FreeAttempts = 2
+	config.Account.BaseDelay = time.Second
+	config.Account.MaxDelay = 4 * time.Second
+	config.Account.LockoutThreshold = 7
+	return config
+}
+
+func TestLoginLimiter_ExponentialBackoffThenLockout(t *testing.T) {
+	limiter := throttle.NewLoginLimiter(throttle.NewMemoryStore(), testLimiterConfig())
+
+	// Los primeros intentos fallidos no demoran el siguiente
+	limiter.RecordFailure("Student@Example.com", "10.0.0.1")
+	limiter.RecordFailure("student@example.com", "10.0.0.1")
+	assert.NoError(t, limiter.Check("student@example.com", "10.0.0.1"))
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second}
+	for _, delay := range expected {
+		limiter.RecordFailure("student@example.com", "10.0.0.1")
+
+		var throttled *domain.LoginThrottledError
+		assert.True(t, errors.As(limiter.Check("student@example.com", "10.0.0.1"), &throttled))
+		assert.False(t, throttled.Locked)
+		assert.InDelta(t, delay.Seconds(), throttled.RetryAfter.Seconds(), 0.1)
+	}
+
+	limiter.RecordFailure("student@example.com", "10.0.0.1")
+
+	var throttled *domain.LoginThrottledError
+	assert.True(t, errors.As(limiter.Check("student@example.com", "10.0.0.2"), &throttled))
+	assert.True(t, throttled.Locked)
+	assert.Equal(t, 900, throttled.RetryAfterSeconds())
+
+	// Otra cuenta desde la misma IP todavía puede intentar
+	assert.NoError(t, limiter.Check("other@example.com", "10.0.0.1"))
+
+	limiter.Unlock("student@example.com")
+	assert.NoError(t, limiter.Check("student@example.com", "10.0.0.2"))
+}
+
+func TestLoginLimiter_ThrottlesByIP(t *testing.T) {
+	config := testLimiterConfig()
+	config.IP.FreeAttempts = 3
+	limiter := throttle.NewLoginLimiter(throttle.NewMemoryStore(), config)
+
+	// Un intento por cuenta, muchas cuentas desde la misma IP
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com"} {
+		limiter.RecordFailure(email, "10.0.0.1")
+	}
+
+	var throttled *domain.LoginThrottledError
+	assert.True(t, errors.As(limiter.Check("e@example.com", "10.0.0.1"), &throttled))
+	assert.False(t, throttled.Locked)
+	assert.NoError(t, limiter.Check("e@example.com", "10.0.0.2"))
+}
+
+func TestLoginLimiter_SuccessResetsAccountOnly(t *testing.T) {
+	config := testLimiterConfig()
+	config.IP.FreeAttempts = 2
+	limiter := throttle.NewLoginLimiter(throttle.NewMemoryStore(), config)
+
+	for i := 0; i < 3; i++ {
+		limiter.RecordFailure("student@example.com", "10.0.0.1")
+	}
+	limiter.RecordSuccess("student@example.com")
+
+	assert.NoError(t, limiter.Check("student@example.com", "10.0.0.2"))
+	assert.Error(t, limiter.Check("student@example.com", "10.0.0.1"))
+}
+
+func TestMemoryStore_EntriesExpire(t *testing.T) {
+	store := throttle.NewMemoryStore()
+	store.Set("key", domain.LoginAttempt{Failures: 1}, 20*time.Millisecond)
+
+	_, ok := store.Get("key")
+	assert.True(t, ok)
+
+	time.Sleep(30 * time.Millisecond)
+	_, ok = store.Get("key")
+	assert.False(t, ok)
+}
+
+func TestLogin_ThrottledSkipsPasswordCheck(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	limiter := throttle.NewLoginLimiter(throttle.NewMemoryStore(), testLimiterConfig())
+	service := users.NewUserService(mockRepo, users.WithLoginLimiter(limiter))
+	mockRepo.On("GetUserByEmail", "test@example.com").Return(&domain.User{Id: 1, Email: "test@example.com", PasswordHash: "wrong-hash"}, nil)
+
+	client := domain.ClientInfo{IP: "10.0.0.1"}
+	for i := 0; i < 3; i++ {
+		_, err := service.Login("test@example.com", "password123", client)
+		assert.Contains(t, err.Error(), "invalid credentials")
+	}
+
+	// Act
+	_, err := service.Login("test@example.com", "password123", client)
+
+	// Assert
+	var throttled *domain.LoginThrottledError
+	assert.True(t, errors.As(err, &throttled))
+	mockRepo.AssertNumberOfCalls(t, "GetUserByEmail", 3)
+}
+
+func TestUnlockUser_ClearsLockout(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	limiter := throttle.NewLoginLimiter(throttle.NewMemoryStore(), testLimiterConfig())
+	service := users.NewUserService(mockRepo, users.WithLoginLimiter(limiter))
+	mockRepo.On("GetUserById", int64(1)).Return(&domain.User{Id: 1, Email: "test@example.com"}, nil)
+	for i := 0; i < 7; i++ {
+		limiter.RecordFailure("test@example.com", "")
+	}
+	assert.Error(t, limiter.Check("test@example.com", ""))
+
+	// Act
+	err := service.UnlockUser(1, testActor)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, limiter.Check("test@example.com", ""))
+	mockRepo.AssertNotCalled(t, "UpdatePasswordHash", mock.Anything, mock.Anything)
+}
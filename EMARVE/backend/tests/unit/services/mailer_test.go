@@ -0,0 +1,54 @@
+package services
+
+import (
+	"backend/domain"
+	"backend/services/mail"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockOutboxRepository simula la tabla outbox
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *MockOutboxRepository) SaveOutboxEmail(email domain.OutboxEmail) error {
+	args := m.Called(email)
+	return args.Error(0)
+}
+
+func TestOutboxMailer_SavesEmail(t *testing.T) {
+	mockRepo := new(MockOutboxRepository)
+	mailer := mail.NewOutboxMailer(mockRepo)
+	mockRepo.On("SaveOutboxEmail", mock.MatchedBy(func(email domain.OutboxEmail) bool {
+		return email.To == "student@example.com" && email.Subject == "Hola" && email.Body == "Cuerpo" && !email.CreatedAt.IsZero()
+	})).Return(nil)
+
+	err := mailer.Send(domain.Email{To: "student@example.com", Subject: "Hola", Body: "Cuerpo"})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOutboxMailer_RejectsHeaderInjection(t *testing.T) {
+	mockRepo := new(MockOutboxRepository)
+	mailer := mail.NewOutboxMailer(mockRepo)
+
+	err := mailer.Send(domain.Email{To: "student@example.com\r\nBcc: victim@example.com", Subject: "Hola"})
+
+	assert.Error(t, err)
+	mockRepo.AssertNotCalled(t, "SaveOutboxEmail", mock.Anything)
+}
+
+func TestNewMailerFromEnv_UsesOutboxWithoutSMTPHost(t *testing.T) {
+	t.Setenv("SMTP_HOST", "")
+	mockRepo := new(MockOutboxRepository)
+	mockRepo.On("SaveOutboxEmail", mock.AnythingOfType("domain.OutboxEmail")).Return(nil)
+
+	err := mail.NewMailerFromEnv(mockRepo).Send(domain.Email{To: "student@example.com", Subject: "Hola"})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
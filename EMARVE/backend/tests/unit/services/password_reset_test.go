@@ -0,0 +1,161 @@
+package services
+
+import (
+	"backend/domain"
+	"backend/services/users"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockMailer simula el envío de emails
+type MockMailer struct {
+	mock.Mock
+}
+
+func (m *MockMailer) Send(email domain.Email) error {
+	args := m.Called(email)
+	return args.Error(0)
+}
+
+func sha256Hex(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestForgotPassword_SendsSingleUseLink(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockMailer := new(MockMailer)
+	service := users.NewUserService(mockRepo, users.WithMailer(mockMailer), users.WithAppBaseURL("https://emarve.test/"))
+
+	mockRepo.On("GetUserByEmail", "student@example.com").Return(&domain.User{Id: 3, Nickname: "student", Email: "student@example.com"}, nil)
+	mockRepo.On("InvalidateUserTokens", int64(3), domain.TokenPurposePasswordReset, mock.AnythingOfType("time.Time")).Return(nil)
+
+	var stored domain.UserToken
+	mockRepo.On("CreateUserToken", mock.AnythingOfType("domain.UserToken")).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(domain.UserToken)
+	}).Return(nil)
+
+	var sent domain.Email
+	mockMailer.On("Send", mock.AnythingOfType("domain.Email")).Run(func(args mock.Arguments) {
+		sent = args.Get(0).(domain.Email)
+	}).Return(nil)
+
+	// Act
+	err := service.ForgotPassword("student@example.com")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "student@example.com", sent.To)
+	assert.Equal(t, int64(3), stored.UserID)
+	assert.Equal(t, domain.TokenPurposePasswordReset, stored.Purpose)
+	assert.WithinDuration(t, time.Now().Add(30*time.Minute), stored.ExpiresAt, time.Minute)
+
+	// El email lleva el token en claro y la base de datos solo su hash
+	match := regexp.MustCompile(`https://emarve\.test/reset-password\?token=(\S+)`).FindStringSubmatch(sent.Body)
+	assert.Len(t, match, 2)
+	token, err := url.QueryUnescape(match[1])
+	assert.NoError(t, err)
+	assert.NotContains(t, stored.TokenHash, token)
+	assert.Equal(t, sha256Hex(token), stored.TokenHash)
+	mockRepo.AssertExpectations(t)
+	mockMailer.AssertExpectations(t)
+}
+
+func TestForgotPassword_UnknownEmailDoesNotFail(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockMailer := new(MockMailer)
+	service := users.NewUserService(mockRepo, users.WithMailer(mockMailer))
+	mockRepo.On("GetUserByEmail", "unknown@example.com").Return(nil, errors.New("record not found"))
+
+	// Act
+	err := service.ForgotPassword("unknown@example.com")
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "CreateUserToken", mock.Anything)
+	mockMailer.AssertNotCalled(t, "Send", mock.Anything)
+}
+
+func TestResetPassword_UpdatesPasswordAndRevokesSessions(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	hasher := newTestHasher(t)
+	service := users.NewUserService(mockRepo, users.WithPasswordHasher(hasher))
+
+	mockRepo.On("GetUserTokenByHash", sha256Hex("reset-token")).Return(&domain.UserToken{
+		Id:        7,
+		UserID:    3,
+		Purpose:   domain.TokenPurposePasswordReset,
+		ExpiresAt: time.Now().Add(10 * time.Minute),
+	}, nil)
+	mockRepo.On("ConsumeUserToken", int64(7), mock.AnythingOfType("time.Time")).Return(nil)
+
+	var newHash string
+	mockRepo.On("UpdatePasswordHash", int64(3), mock.AnythingOfType("string")).Run(func(args mock.Arguments) {
+		newHash = args.String(1)
+	}).Return(nil)
+	mockRepo.On("RevokeUserSessions", int64(3), mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Act
+	err := service.ResetPassword("reset-token", "newPassword123")
+
+	// Assert
+	assert.NoError(t, err)
+	valid, err := hasher.Verify("newPassword123", newHash)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestResetPassword_RejectsUsedExpiredOrForeignTokens(t *testing.T) {
+	usedAt := time.Now().Add(-time.Minute)
+	tokens := map[string]*domain.UserToken{
+		"used":    {Id: 1, UserID: 3, Purpose: domain.TokenPurposePasswordReset, ExpiresAt: time.Now().Add(time.Hour), UsedAt: &usedAt},
+		"expired": {Id: 2, UserID: 3, Purpose: domain.TokenPurposePasswordReset, ExpiresAt: time.Now().Add(-time.Minute)},
+		"other":   {Id: 3, UserID: 3, Purpose: "email_verification", ExpiresAt: time.Now().Add(time.Hour)},
+	}
+
+	for name, token := range tokens {
+		t.Run(name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			service := users.NewUserService(mockRepo)
+			mockRepo.On("GetUserTokenByHash", sha256Hex(name)).Return(token, nil)
+
+			err := service.ResetPassword(name, "newPassword123")
+
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid or expired token")
+			mockRepo.AssertNotCalled(t, "UpdatePasswordHash", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestResetPassword_ConcurrentUseIsRejected(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+	mockRepo.On("GetUserTokenByHash", sha256Hex("reset-token")).Return(&domain.UserToken{
+		Id:        7,
+		UserID:    3,
+		Purpose:   domain.TokenPurposePasswordReset,
+		ExpiresAt: time.Now().Add(10 * time.Minute),
+	}, nil)
+	mockRepo.On("ConsumeUserToken", int64(7), mock.AnythingOfType("time.Time")).Return(errors.New("token 7 was already used"))
+
+	// Act
+	err := service.ResetPassword("reset-token", "newPassword123")
+
+	// Assert
+	assert.Error(t, err)
+	mockRepo.AssertNotCalled(t, "UpdatePasswordHash", mock.Anything, mock.Anything)
+}
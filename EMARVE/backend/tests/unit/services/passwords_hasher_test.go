@@ -0,0 +1,123 @@
+package services
+
+import (
+	"backend/services/passwords"
+	"crypto/md5"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newArgon2idConfig() passwords.Config {
+	config := passwords.DefaultConfig()
+	config.Algorithm = passwords.AlgorithmArgon2id
+	config.Argon2Memory = 8 * 1024
+	config.Argon2Time = 1
+	config.Argon2Threads = 1
+	return config
+}
+
+func TestPasswordHasher_BcryptHashAndVerify(t *testing.T) {
+	config := passwords.DefaultConfig()
+	config.BcryptCost = 4
+	hasher, err := passwords.NewHasher(config)
+	assert.NoError(t, err)
+
+	hash, err := hasher.Hash("password123")
+
+	assert.NoError(t, err)
+	assert.Equal(t, passwords.AlgorithmBcrypt, passwords.Algorithm(hash))
+	valid, err := hasher.Verify("password123", hash)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+	valid, err = hasher.Verify("wrong-password", hash)
+	assert.NoError(t, err)
+	assert.False(t, valid)
+	assert.False(t, hasher.NeedsRehash(hash))
+}
+
+func TestPasswordHasher_Argon2idHashAndVerify(t *testing.T) {
+	hasher, err := passwords.NewHasher(newArgon2idConfig())
+	assert.NoError(t, err)
+
+	hash, err := hasher.Hash("password123")
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=8192,t=1,p=1$"))
+	valid, err := hasher.Verify("password123", hash)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+	valid, err = hasher.Verify("wrong-password", hash)
+	assert.NoError(t, err)
+	assert.False(t, valid)
+	assert.False(t, hasher.NeedsRehash(hash))
+}
+
+func TestPasswordHasher_VerifiesLegacyMD5AndRequestsRehash(t *testing.T) {
+	hasher := passwords.NewDefaultHasher()
+	legacy := fmt.Sprintf("%x", md5.Sum([]byte("password123")))
+
+	valid, err := hasher.Verify("password123", legacy)
+
+	assert.NoError(t, err)
+	assert.True(t, valid)
+	assert.Equal(t, passwords.AlgorithmMD5, passwords.Algorithm(legacy))
+	assert.True(t, hasher.NeedsRehash(legacy))
+}
+
+func TestPasswordHasher_NeedsRehashWhenAlgorithmOrCostChanges(t *testing.T) {
+	bcryptConfig := passwords.DefaultConfig()
+	bcryptConfig.BcryptCost = 4
+	bcryptHasher, err := passwords.NewHasher(bcryptConfig)
+	assert.NoError(t, err)
+	argonHasher, err := passwords.NewHasher(newArgon2idConfig())
+	assert.NoError(t, err)
+
+	bcryptHash, err := bcryptHasher.Hash("password123")
+	assert.NoError(t, err)
+
+	bcryptConfig.BcryptCost = 5
+	strongerHasher, err := passwords.NewHasher(bcryptConfig)
+	assert.NoError(t, err)
+
+	assert.True(t, argonHasher.NeedsRehash(bcryptHash))
+	assert.True(t, strongerHasher.NeedsRehash(bcryptHash))
+	// El hasher argon2id sigue verificando hashes bcrypt existentes
+	valid, err := argonHasher.Verify("password123", bcryptHash)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+}
+
+func TestPasswordHasher_VerifiesSeedBcryptHash(t *testing.T) {
+	hasher := passwords.NewDefaultHasher()
+
+	// Hash sembrado en init.sql
+	valid, err := hasher.Verify("password", "$2a$10$92IXUNpkjO0rOQ5byMi.Ye4oKoEa3Ro9llC/.og/at2.uheWG/igi")
+
+	assert.NoError(t, err)
+	assert.True(t, valid)
+}
+
+func TestPasswordHasher_UnknownFormat(t *testing.T) {
+	hasher := passwords.NewDefaultHasher()
+
+	valid, err := hasher.Verify("password123", "plaintext")
+
+	assert.Error(t, err)
+	assert.False(t, valid)
+	assert.True(t, hasher.NeedsRehash("plaintext"))
+}
+
+func TestNewHasher_InvalidConfig(t *testing.T) {
+	config := passwords.DefaultConfig()
+	config.Algorithm = "sha1"
+	_, err := passwords.NewHasher(config)
+	assert.Error(t, err)
+
+	config = passwords.DefaultConfig()
+	config.BcryptCost = 50
+	_, err = passwords.NewHasher(config)
+	assert.Error(t, err)
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"archive/zip"
+	"backend/domain"
+	"backend/services/users"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestExportUserData_CollectsPersonalData(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	recorder := &MockAuditRecorder{}
+	service := users.NewUserService(mockRepo, users.WithAuditRecorder(recorder))
+
+	mockRepo.On("GetUserById", int64(5)).Return(&domain.User{Id: 5, Nickname: "student", Email: "student@example.com", Roles: []string{domain.RoleStudent}}, nil)
+	mockRepo.On("GetTwoFactor", int64(5)).Return(&domain.TwoFactor{UserID: 5, EnabledAt: verifiedNow()}, nil)
+	mockRepo.On("GetCourseIdsByUserId", int64(5)).Return([]int64{2}, nil)
+	mockRepo.On("GetCourseById", int64(2)).Return(&domain.Course{Id: 2, Title: "Go"}, nil)
+	mockRepo.On("GetCommentsByUserId", int64(5)).Return([]domain.Comment{{Id: 1, UserID: 5, CourseID: 2, Comment: "Great"}}, nil)
+	mockRepo.On("GetFilesByUserId", int64(5)).Return(nil, nil)
+	mockRepo.On("GetActiveSessionsByUserId", int64(5)).Return([]domain.Session{{Id: 4, UserID: 5, IP: "10.0.0.1"}}, nil)
+
+	// Act
+	export, err := service.ExportUserData(5, ownerActor)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "student@example.com", export.Profile.Email)
+	assert.True(t, export.Profile.TwoFactorEnabled)
+	assert.Len(t, export.Subscriptions, 1)
+	assert.Len(t, export.Comments, 1)
+	assert.NotNil(t, export.Files)
+	assert.Len(t, export.Sessions, 1)
+	assert.Len(t, recorder.events, 1)
+	assert.Equal(t, domain.AuditUserExported, recorder.events[0].Action)
+}
+
+func TestExportUserData_OtherUserForbidden(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+
+	// Act
+	_, err := service.ExportUserData(6, ownerActor)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrAccountForbidden)
+}
+
+func TestExportUserData_UserManagerAllowed(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+	support := domain.Actor{UserID: 8, Role: "support", Permissions: []string{domain.PermUserManage}}
+
+	mockRepo.On("GetUserById", int64(5)).Return(&domain.User{Id: 5, Email: "student@example.com"}, nil)
+	mockRepo.On("GetTwoFactor", int64(5)).Return(nil, nil)
+	mockRepo.On("GetCourseIdsByUserId", int64(5)).Return([]int64{}, nil)
+	mockRepo.On("GetCommentsByUserId", int64(5)).Return(nil, nil)
+	mockRepo.On("GetFilesByUserId", int64(5)).Return(nil, nil)
+	mockRepo.On("GetActiveSessionsByUserId", int64(5)).Return(nil, nil)
+
+	// Act
+	export, err := service.ExportUserData(5, support)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "student@example.com", export.Profile.Email)
+}
+
+func TestExportUserData_AdminRoleWithoutPermissionForbidden(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+
+	// Act
+	_, err := service.ExportUserData(5, domain.Actor{UserID: 1, Role: domain.RoleAdmin})
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrAccountForbidden)
+}
+
+func TestWriteExportArchive_IncludesUploadedFiles(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "notes.pdf"), []byte("pdf content"), 0o644))
+	service := users.NewUserService(new(MockUserRepository), users.WithUploadsDir(dir))
+
+	export := domain.UserExport{
+		Profile: domain.UserExportProfile{Id: 5},
+		Files: []domain.File{
+			{Id: 3, Name: "notes.pdf", Url: "uploads/notes.pdf"},
+			{Id: 4, Name: "missing.pdf", Url: "uploads/missing.pdf"},
+		},
+	}
+
+	// Act
+	var buffer bytes.Buffer
+	err := service.WriteExportArchive(export, &buffer)
+
+	// Assert
+	assert.NoError(t, err)
+	archive, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	assert.NoError(t, err)
+
+	contents := make(map[string]string)
+	for _, entry := range archive.File {
+		reader, err := entry.Open()
+		assert.NoError(t, err)
+		data, err := io.ReadAll(reader)
+		assert.NoError(t, err)
+		reader.Close()
+		contents[entry.Name] = string(data)
+	}
+
+	assert.Len(t, contents, 2)
+	assert.Equal(t, "pdf content", contents["files/3-notes.pdf"])
+	var decoded domain.UserExport
+	assert.NoError(t, json.Unmarshal([]byte(contents["export.json"]), &decoded))
+	assert.Equal(t, int64(5), decoded.Profile.Id)
+}
+
+func TestDeleteUser_SelfRequiresPassword(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	hasher := newTestHasher(t)
+	service := users.NewUserService(mockRepo, users.WithPasswordHasher(hasher))
+
+	hash, err := hasher.Hash("password123")
+	assert.NoError(t, err)
+	mockRepo.On("GetUserById", int64(5)).Return(&domain.User{Id: 5, PasswordHash: hash}, nil)
+
+	// Act
+	err = service.DeleteUser(5, "wrong", ownerActor)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrInvalidCurrentPassword)
+	mockRepo.AssertNotCalled(t, "DeleteUser", mock.Anything)
+}
+
+func TestDeleteUser_KeepsCourseMaterial(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	uploaded := filepath.Join(dir, "notes.pdf")
+	assert.NoError(t, os.WriteFile(uploaded, []byte("pdf content"), 0o644))
+
+	mockRepo := new(MockUserRepository)
+	recorder := &MockAuditRecorder{}
+	service := users.NewUserService(mockRepo, users.WithUploadsDir(dir), users.WithAuditRecorder(recorder))
+
+	mockRepo.On("GetUserById", int64(5)).Return(&domain.User{Id: 5, Email: "student@example.com"}, nil)
+	mockRepo.On("GetSoleOwnedCourseIDs", int64(5)).Return([]int64{}, nil)
+	mockRepo.On("DeleteUser", int64(5)).Return(nil)
+
+	// Act
+	err := service.DeleteUser(5, "", testActor)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.FileExists(t, uploaded)
+	mockRepo.AssertExpectations(t)
+
+	// El log de auditoría no conserva el email de la cuenta eliminada
+	assert.Len(t, recorder.events, 1)
+	assert.Equal(t, domain.AuditUserDeleted, recorder.events[0].Action)
+	assert.Empty(t, recorder.events[0].Before)
+}
+
+func TestDeleteUser_LastCourseOwner(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+
+	mockRepo.On("GetUserById", int64(5)).Return(&domain.User{Id: 5}, nil)
+	mockRepo.On("GetSoleOwnedCourseIDs", int64(5)).Return([]int64{3}, nil)
+
+	// Act
+	err := service.DeleteUser(5, "", testActor)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrLastCourseOwner)
+	mockRepo.AssertNotCalled(t, "DeleteUser", mock.Anything)
+}
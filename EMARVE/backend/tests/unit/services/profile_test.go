@@ -0,0 +1,216 @@
+package services
+
+import (
+	"backend/domain"
+	"backend/services/users"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// ownerActor es el dueño de la cuenta 5 en los tests del perfil
+var ownerActor = domain.Actor{UserID: 5, Role: domain.RoleStudent, IP: "127.0.0.1"}
+
+func TestUpdateUser_OtherUserForbidden(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+
+	// Act
+	_, err := service.UpdateUser(6, domain.UpdateUserRequest{Nickname: "other"}, ownerActor)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrAccountForbidden)
+	mockRepo.AssertNotCalled(t, "GetUserById", mock.Anything)
+}
+
+func TestUpdateUser_ChangesNickname(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	recorder := &MockAuditRecorder{}
+	service := users.NewUserService(mockRepo, users.WithAuditRecorder(recorder))
+
+	mockRepo.On("GetUserById", int64(5)).Return(&domain.User{Id: 5, Nickname: "old", Email: "student@example.com"}, nil)
+	mockRepo.On("IsNicknameTaken", "new", int64(5)).Return(false, nil)
+	mockRepo.On("UpdateNickname", int64(5), "new").Return(nil)
+
+	// Act
+	user, err := service.UpdateUser(5, domain.UpdateUserRequest{Nickname: " new "}, ownerActor)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "new", user.Nickname)
+	mockRepo.AssertExpectations(t)
+	assert.Len(t, recorder.events, 1)
+	assert.Equal(t, domain.AuditUserUpdated, recorder.events[0].Action)
+	assert.JSONEq(t, `{"nickname":"old","email":"student@example.com"}`, string(recorder.events[0].Before))
+}
+
+func TestUpdateUser_NicknameTaken(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+
+	mockRepo.On("GetUserById", int64(5)).Return(&domain.User{Id: 5, Nickname: "old", Email: "student@example.com"}, nil)
+	mockRepo.On("IsNicknameTaken", "taken", int64(5)).Return(true, nil)
+
+	// Act
+	_, err := service.UpdateUser(5, domain.UpdateUserRequest{Nickname: "taken"}, ownerActor)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrNicknameTaken)
+	mockRepo.AssertNotCalled(t, "UpdateNickname", mock.Anything, mock.Anything)
+}
+
+func TestUpdateUser_PasswordRequiresCurrentPassword(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	hasher := newTestHasher(t)
+	service := users.NewUserService(mockRepo, users.WithPasswordHasher(hasher))
+
+	hash, err := hasher.Hash("password123")
+	assert.NoError(t, err)
+	mockRepo.On("GetUserById", int64(5)).Return(&domain.User{Id: 5, Nickname: "student", PasswordHash: hash}, nil)
+
+	// Act
+	_, missingErr := service.UpdateUser(5, domain.UpdateUserRequest{Password: "new-password"}, ownerActor)
+	_, wrongErr := service.UpdateUser(5, domain.UpdateUserRequest{Password: "new-password", CurrentPassword: "wrong"}, ownerActor)
+
+	// Assert
+	assert.Error(t, missingErr)
+	assert.ErrorIs(t, wrongErr, domain.ErrInvalidCurrentPassword)
+	mockRepo.AssertNotCalled(t, "UpdatePasswordHash", mock.Anything, mock.Anything)
+}
+
+func TestUpdateUser_ChangesPasswordAndRevokesSessions(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	hasher := newTestHasher(t)
+	service := users.NewUserService(mockRepo, users.WithPasswordHasher(hasher))
+
+	hash, err := hasher.Hash("password123")
+	assert.NoError(t, err)
+	mockRepo.On("GetUserById", int64(5)).Return(&domain.User{Id: 5, Nickname: "student", PasswordHash: hash}, nil)
+
+	var newHash string
+	mockRepo.On("UpdatePasswordHash", int64(5), mock.AnythingOfType("string")).Run(func(args mock.Arguments) {
+		newHash = args.String(1)
+	}).Return(nil)
+	mockRepo.On("RevokeUserSessions", int64(5), mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Act
+	_, err = service.UpdateUser(5, domain.UpdateUserRequest{Password: "new-password", CurrentPassword: "password123"}, ownerActor)
+
+	// Assert
+	assert.NoError(t, err)
+	valid, err := hasher.Verify("new-password", newHash)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateUser_EmailChangeNeedsConfirmation(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockMailer := new(MockMailer)
+	service := users.NewUserService(mockRepo, users.WithMailer(mockMailer), users.WithAPIBaseURL("https://api.emarve.test"))
+
+	mockRepo.On("GetUserById", int64(5)).Return(&domain.User{Id: 5, Nickname: "student", Email: "old@example.com"}, nil)
+	mockRepo.On("IsEmailTaken", "new@example.com", int64(5)).Return(false, nil)
+	mockRepo.On("SetPendingEmail", int64(5), "new@example.com").Return(nil)
+	mockRepo.On("InvalidateUserTokens", int64(5), domain.TokenPurposeEmailChange, mock.AnythingOfType("time.Time")).Return(nil)
+
+	var stored domain.UserToken
+	mockRepo.On("CreateUserToken", mock.AnythingOfType("domain.UserToken")).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(domain.UserToken)
+	}).Return(nil)
+
+	sent := make([]domain.Email, 0)
+	mockMailer.On("Send", mock.AnythingOfType("domain.Email")).Run(func(args mock.Arguments) {
+		sent = append(sent, args.Get(0).(domain.Email))
+	}).Return(nil)
+
+	// Act
+	user, err := service.UpdateUser(5, domain.UpdateUserRequest{Email: "new@example.com"}, ownerActor)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "old@example.com", user.Email)
+	assert.Equal(t, "new@example.com", *user.PendingEmail)
+	assert.Equal(t, domain.TokenPurposeEmailChange, stored.Purpose)
+
+	// El link va a la dirección nueva y la actual recibe un aviso
+	assert.Len(t, sent, 2)
+	assert.Equal(t, "new@example.com", sent[0].To)
+	assert.Equal(t, "old@example.com", sent[1].To)
+	match := regexp.MustCompile(`https://api\.emarve\.test/users/email/confirm\?token=(\S+)`).FindStringSubmatch(sent[0].Body)
+	assert.Len(t, match, 2)
+	token, err := url.QueryUnescape(match[1])
+	assert.NoError(t, err)
+	assert.Equal(t, sha256Hex(token), stored.TokenHash)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateUser_EmailTaken(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+
+	mockRepo.On("GetUserById", int64(5)).Return(&domain.User{Id: 5, Email: "old@example.com"}, nil)
+	mockRepo.On("IsEmailTaken", "taken@example.com", int64(5)).Return(true, nil)
+
+	// Act
+	_, err := service.UpdateUser(5, domain.UpdateUserRequest{Email: "taken@example.com"}, testActor)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrEmailTaken)
+	mockRepo.AssertNotCalled(t, "SetPendingEmail", mock.Anything, mock.Anything)
+}
+
+func TestConfirmEmailChange_AppliesPendingEmail(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+
+	pending := "new@example.com"
+	mockRepo.On("GetUserTokenByHash", sha256Hex("change-token")).Return(&domain.UserToken{
+		Id:        9,
+		UserID:    5,
+		Purpose:   domain.TokenPurposeEmailChange,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+	mockRepo.On("ConsumeUserToken", int64(9), mock.AnythingOfType("time.Time")).Return(nil)
+	mockRepo.On("GetUserById", int64(5)).Return(&domain.User{Id: 5, Email: "old@example.com", PendingEmail: &pending}, nil)
+	mockRepo.On("IsEmailTaken", "new@example.com", int64(5)).Return(false, nil)
+	mockRepo.On("ConfirmEmailChange", int64(5), "new@example.com", mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Act
+	err := service.ConfirmEmailChange("change-token")
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestConfirmEmailChange_VerificationTokenIsRejected(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+
+	mockRepo.On("GetUserTokenByHash", sha256Hex("verify-token")).Return(&domain.UserToken{
+		Id:      8,
+		UserID:  5,
+		Purpose: domain.TokenPurposeEmailVerification,
+	}, nil)
+
+	// Act
+	err := service.ConfirmEmailChange("verify-token")
+
+	// Assert
+	assert.Error(t, err)
+	mockRepo.AssertNotCalled(t, "ConfirmEmailChange", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"backend/domain"
+	"backend/services/users"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPrimaryRole(t *testing.T) {
+	assert.Equal(t, domain.RoleAdmin, domain.PrimaryRole([]string{domain.RoleStudent, domain.RoleAdmin}))
+	assert.Equal(t, domain.RoleInstructor, domain.PrimaryRole([]string{domain.RoleTeachingAssistant, domain.RoleInstructor}))
+	assert.Equal(t, "", domain.PrimaryRole(nil))
+}
+
+func TestLogin_TokenCarriesRolesAndPermissions(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	hasher := newTestHasher(t)
+	service := users.NewUserService(mockRepo, users.WithPasswordHasher(hasher))
+
+	hash, err := hasher.Hash("password123")
+	assert.NoError(t, err)
+	mockRepo.On("GetUserByEmail", "instructor@example.com").Return(&domain.User{
+		Id:              4,
+		Email:           "instructor@example.com",
+		PasswordHash:    hash,
+		EmailVerifiedAt: verifiedNow(),
+		Roles:           []string{domain.RoleInstructor, domain.RoleTeachingAssistant},
+		Permissions:     []string{domain.PermCourseCreate, domain.PermCommentModerate},
+	}, nil)
+	mockRepo.On("GetTwoFactor", int64(4)).Return(nil, nil)
+	mockRepo.On("CreateSession", mock.AnythingOfType("domain.Session")).Return(domain.Session{Id: 30}, nil)
+	mockRepo.On("GetSessionById", int64(30)).Return(&domain.Session{Id: 30, UserID: 4, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+
+	response, err := service.Login("instructor@example.com", "password123", domain.ClientInfo{})
+	assert.NoError(t, err)
+
+	// Act
+	claims, err := service.ValidateToken("Bearer " + response.Token)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, domain.RoleInstructor, claims.Role)
+	assert.Equal(t, []string{domain.RoleInstructor, domain.RoleTeachingAssistant}, claims.Roles)
+	assert.Equal(t, []string{domain.PermCourseCreate, domain.PermCommentModerate}, claims.Permissions)
+}
+
+func TestSetUserRoles_RemovingRoleRevokesSessions(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	recorder := &MockAuditRecorder{}
+	service := users.NewUserService(mockRepo, users.WithAuditRecorder(recorder))
+	mockRepo.On("GetUserById", int64(5)).Return(&domain.User{Id: 5, Roles: []string{domain.RoleAdmin}}, nil)
+	mockRepo.On("SetUserRoles", int64(5), []string{domain.RoleInstructor}).Return(nil)
+	mockRepo.On("RevokeUserSessions", int64(5), mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Act
+	err := service.SetUserRoles(5, []string{" Instructor ", "instructor"}, testActor)
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	assert.Len(t, recorder.events, 1)
+	assert.Equal(t, domain.AuditUserRolesChanged, recorder.events[0].Action)
+	assert.JSONEq(t, `["admin"]`, string(recorder.events[0].Before))
+	assert.JSONEq(t, `["instructor"]`, string(recorder.events[0].After))
+}
+
+func TestSetUserRoles_AddingRoleKeepsSessions(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+	mockRepo.On("GetUserById", int64(5)).Return(&domain.User{Id: 5, Roles: []string{domain.RoleStudent}}, nil)
+	mockRepo.On("SetUserRoles", int64(5), []string{domain.RoleStudent, domain.RoleTeachingAssistant}).Return(nil)
+
+	// Act
+	err := service.SetUserRoles(5, []string{domain.RoleTeachingAssistant, domain.RoleStudent}, testActor)
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "RevokeUserSessions", mock.Anything, mock.Anything)
+}
+
+func TestSetUserRoles_AdminCannotDemoteItself(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+	mockRepo.On("GetUserById", testActor.UserID).Return(&domain.User{Id: testActor.UserID, Roles: []string{domain.RoleAdmin}}, nil)
+
+	// Act
+	err := service.SetUserRoles(testActor.UserID, []string{domain.RoleStudent}, testActor)
+
+	// Assert
+	assert.Error(t, err)
+	mockRepo.AssertNotCalled(t, "SetUserRoles", mock.Anything, mock.Anything)
+}
+
+func TestSetUserRoles_EmptyRoles(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+
+	err := service.SetUserRoles(5, []string{" "}, testActor)
+
+	assert.EqualError(t, err, "at least one role is required")
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"backend/domain"
+	"backend/services/search"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyze_FoldsAccentsAndStems(t *testing.T) {
+	assert.Equal(t, search.Analyze("Programación"), search.Analyze("programacion"))
+	assert.Equal(t, search.Analyze("programas"), search.Analyze("PROGRAMA"))
+	assert.Equal(t, search.Analyze("Diseño"), search.Analyze("diseno"))
+	assert.Equal(t, search.Analyze("redes"), search.Analyze("red"))
+	assert.Equal(t, []string{"curs", "python"}, search.Analyze("Curso de Python"))
+	assert.Empty(t, search.Analyze("de la y en"))
+}
+
+func TestCourseIndex_RanksTitleOverDescriptionOverRequirement(t *testing.T) {
+	// Arrange
+	index := search.NewCourseIndex()
+	index.Index(domain.Course{Id: 1, Title: "Cocina", Requirement: "Saber sobre bases de datos"})
+	index.Index(domain.Course{Id: 2, Title: "Análisis", Description: "Trabajamos con bases de datos"})
+	index.Index(domain.Course{Id: 3, Title: "Bases de datos"})
+
+	// Act
+	hits := index.Search("base de dato")
+
+	// Assert
+	assert.Len(t, hits, 3)
+	assert.Equal(t, 3, hits[0].Course.Id)
+	assert.Equal(t, 2, hits[1].Course.Id)
+	assert.Equal(t, 1, hits[2].Course.Id)
+}
+
+func TestCourseIndex_MoreMatchingTermsRankHigher(t *testing.T) {
+	// Arrange
+	index := search.NewCourseIndex()
+	index.Index(domain.Course{Id: 1, Title: "Python"})
+	index.Index(domain.Course{Id: 2, Title: "Python para ciencia de datos"})
+	index.Index(domain.Course{Id: 3, Title: "Ciencia política"})
+
+	// Act
+	hits := index.Search("python ciencia")
+
+	// Assert
+	assert.Len(t, hits, 3)
+	assert.Equal(t, 2, hits[0].Course.Id)
+}
+
+func TestCourseIndex_ReplaceAndRemove(t *testing.T) {
+	// Arrange
+	index := search.NewCourseIndex()
+	index.Index(domain.Course{Id: 1, Title: "Guitarra"})
+
+	// Act
+	index.Index(domain.Course{Id: 1, Title: "Piano"})
+
+	// Assert
+	assert.Equal(t, 1, index.Len())
+	assert.Empty(t, index.Search("guitarra"))
+	assert.Len(t, index.Search("piano"), 1)
+
+	index.Remove(1)
+	assert.Equal(t, 0, index.Len())
+	assert.Empty(t, index.Search("piano"))
+}
+
+func TestCourseIndex_HighlightsEscapedSnippets(t *testing.T) {
+	// Arrange
+	index := search.NewCourseIndex()
+	description := strings.Repeat("texto de relleno ", 20) + "usamos <script> y canciones en la clase de guitarra " + strings.Repeat("más relleno ", 20)
+	index.Index(domain.Course{Id: 1, Title: "Guitarra & voz", Description: description})
+
+	// Act
+	hits := index.Search("canción guitarras")
+
+	// Assert
+	assert.Len(t, hits, 1)
+	assert.Equal(t, "<em>Guitarra</em> &amp; voz", hits[0].Highlights[domain.SearchFieldTitle])
+
+	snippet := hits[0].Highlights[domain.SearchFieldDescription]
+	assert.True(t, strings.HasPrefix(snippet, "…"))
+	assert.True(t, strings.HasSuffix(snippet, "…"))
+	assert.Contains(t, snippet, "&lt;script&gt; y <em>canciones</em> en la clase de <em>guitarra</em>")
+	assert.NotContains(t, hits[0].Highlights, domain.SearchFieldRequirement)
+}
+
+func TestCourseIndex_SuggestTitlesCategoriesAndInstructors(t *testing.T) {
+	// Arrange
+	index := search.NewCourseIndex()
+	index.Index(domain.Course{Id: 1, Title: "Programación en Go", Category: "Programación", Instructor: "Pablo Pérez"})
+	index.Index(domain.Course{Id: 2, Title: "Python inicial", Category: "programacion", Instructor: "Ana Paz"})
+	index.Index(domain.Course{Id: 3, Title: "Introducción a la programación", Category: "Diseño", Instructor: "Ana Paz"})
+
+	// Act
+	suggestions := index.Suggest("progra", 10, allCourses)
+
+	// Assert
+	assert.Equal(t, []domain.Suggestion{
+		{Text: "Programación", Field: domain.SearchFieldCategory, Count: 2},
+		{Text: "Programación en Go", Field: domain.SearchFieldTitle, CourseID: 1},
+		{Text: "Introducción a la programación", Field: domain.SearchFieldTitle, CourseID: 3},
+	}, suggestions)
+
+	instructors := index.Suggest("paz", 10, allCourses)
+	assert.Equal(t, []domain.Suggestion{{Text: "Ana Paz", Field: domain.SearchFieldInstructor, Count: 2}}, instructors)
+
+	assert.Len(t, index.Suggest("p", 2, allCourses), 2)
+	assert.Empty(t, index.Suggest("  ", 10, allCourses))
+}
+
+func TestCourseIndex_SuggestSkipsHiddenCourses(t *testing.T) {
+	// Arrange
+	index := search.NewCourseIndex()
+	index.Index(domain.Course{Id: 1, Title: "Programación en Go", Category: "Programación", Status: domain.CoursePublished})
+	index.Index(domain.Course{Id: 2, Title: "Programación en Rust", Category: "Programación", Status: domain.CourseDraft})
+
+	// Act
+	suggestions := index.Suggest("progra", 10, func(course domain.Course) bool {
+		return course.Status == domain.CoursePublished
+	})
+
+	// Assert
+	assert.Equal(t, []domain.Suggestion{
+		{Text: "Programación", Field: domain.SearchFieldCategory, Count: 1},
+		{Text: "Programación en Go", Field: domain.SearchFieldTitle, CourseID: 1},
+	}, suggestions)
+}
+
+func TestCourseIndex_CorrectsMisspelledWords(t *testing.T) {
+	// Arrange
+	index := search.NewCourseIndex()
+	index.Index(domain.Course{Id: 1, Title: "Python para ciencia de datos", Description: "Programación con Python"})
+	index.Index(domain.Course{Id: 2, Title: "Guitarra", Description: "Acordes y canciones"})
+
+	// Act & Assert
+	assert.Equal(t, "curso de python", index.Correct("curso de pyhton", allCourses))
+	assert.Equal(t, "programación y guitarra", index.Correct("programacoin y guitara", allCourses))
+	assert.Equal(t, "", index.Correct("python", allCourses))
+	assert.Equal(t, "", index.Correct("astronomía", allCourses))
+}
+
+func TestCourseIndex_CorrectionsSkipHiddenCourses(t *testing.T) {
+	// Arrange
+	index := search.NewCourseIndex()
+	index.Index(domain.Course{Id: 1, Title: "Guitarra", Status: domain.CoursePublished})
+	index.Index(domain.Course{Id: 2, Title: "Criptografía secreta", Status: domain.CourseDraft})
+	published := func(course domain.Course) bool {
+		return course.Status == domain.CoursePublished
+	}
+
+	// Act & Assert
+	assert.Equal(t, "", index.Correct("criptografai secretaa", published))
+	assert.Equal(t, "guitarra", index.Correct("guitara", published))
+	assert.Equal(t, "criptografía secreta", index.Correct("criptografai secretaa", allCourses))
+}
+
+// allCourses no oculta ningún curso de las sugerencias
+func allCourses(domain.Course) bool {
+	return true
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"backend/services/signing"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+const testHMACSecret = "0123456789abcdef0123456789abcdef"
+
+func newRSAPrivatePEM(t *testing.T) (string, *rsa.PrivateKey) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	block := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	return string(pem.EncodeToMemory(block)), key
+}
+
+func newEd25519PEMs(t *testing.T) (string, string) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	assert.NoError(t, err)
+	publicDER, err := x509.MarshalPKIXPublicKey(publicKey)
+	assert.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+}
+
+func testClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"userID": 1,
+		"exp":    time.Now().Add(time.Minute).Unix(),
+	}
+}
+
+func TestKeySet_RS256SignVerifyAndJWKS(t *testing.T) {
+	privatePEM, privateKey := newRSAPrivatePEM(t)
+	keySet, err := signing.NewKeySet(signing.Config{
+		ActiveKid: "rsa-1",
+		Keys:      []signing.KeyConfig{{Kid: "rsa-1", Alg: signing.AlgorithmRS256, PrivateKey: privatePEM}},
+	})
+	assert.NoError(t, err)
+
+	tokenString, err := keySet.Sign(testClaims())
+	assert.NoError(t, err)
+
+	// El header lleva el kid y el algoritmo de la clave activa
+	parsed, _, err := new(jwt.Parser).ParseUnverified(tokenString, jwt.MapClaims{})
+	assert.NoError(t, err)
+	assert.Equal(t, "rsa-1", parsed.Header["kid"])
+	assert.Equal(t, "RS256", parsed.Header["alg"])
+
+	claims, err := keySet.Verify(tokenString)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), claims["userID"])
+
+	// Un tercero puede verificar con la clave pública publicada
+	_, err = jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return &privateKey.PublicKey, nil
+	})
+	assert.NoError(t, err)
+
+	jwks := keySet.JWKS()
+	assert.Len(t, jwks.Keys, 1)
+	assert.Equal(t, "RSA", jwks.Keys[0].Kty)
+	assert.Equal(t, "rsa-1", jwks.Keys[0].Kid)
+	assert.Equal(t, "AQAB", jwks.Keys[0].E)
+	assert.NotEmpty(t, jwks.Keys[0].N)
+}
+
+func TestKeySet_RotationKeepsOldTokensValid(t *testing.T) {
+	newPrivatePEM, _ := newEd25519PEMs(t)
+	oldPrivatePEM, _ := newEd25519PEMs(t)
+
+	oldKeySet, err := signing.NewKeySet(signing.Config{
+		ActiveKid: "2025-01",
+		Keys:      []signing.KeyConfig{{Kid: "2025-01", Alg: signing.AlgorithmHS256, Secret: testHMACSecret}},
+	})
+	assert.NoError(t, err)
+	oldToken, err := oldKeySet.Sign(testClaims())
+	assert.NoError(t, err)
+
+	rotated, err := signing.NewKeySet(signing.Config{
+		ActiveKid: "2025-10",
+		Keys: []signing.KeyConfig{
+			{Kid: "2025-01", Alg: signing.AlgorithmHS256, Secret: testHMACSecret},
+			{Kid: "2025-10", Alg: signing.AlgorithmEdDSA, PrivateKey: newPrivatePEM},
+			{Kid: "2024-06", Alg: signing.AlgorithmEdDSA, PrivateKey: oldPrivatePEM},
+		},
+	})
+	assert.NoError(t, err)
+
+	_, err = rotated.Verify(oldToken)
+	assert.NoError(t, err)
+
+	newToken, err := rotated.Sign(testClaims())
+	assert.NoError(t, err)
+	_, err = rotated.Verify(newToken)
+	assert.NoError(t, err)
+
+	// La clave HMAC no se publica en el JWKS
+	jwks := rotated.JWKS()
+	assert.Len(t, jwks.Keys, 2)
+	assert.Equal(t, "2024-06", jwks.Keys[0].Kid)
+	assert.Equal(t, "2025-10", jwks.Keys[1].Kid)
+	assert.Equal(t, "Ed25519", jwks.Keys[1].Crv)
+}
+
+func TestKeySet_PublicOnlyKeyVerifiesButCannotBeActive(t *testing.T) {
+	privatePEM, publicPEM := newEd25519PEMs(t)
+	signer, err := signing.NewKeySet(signing.Config{
+		ActiveKid: "old",
+		Keys:      []signing.KeyConfig{{Kid: "old", Alg: signing.AlgorithmEdDSA, PrivateKey: privatePEM}},
+	})
+	assert.NoError(t, err)
+	tokenString, err := signer.Sign(testClaims())
+	assert.NoError(t, err)
+
+	_, err = signing.NewKeySet(signing.Config{
+		ActiveKid: "old",
+		Keys:      []signing.KeyConfig{{Kid: "old", Alg: signing.AlgorithmEdDSA, PublicKey: publicPEM}},
+	})
+	assert.Error(t, err)
+
+	verifier, err := signing.NewKeySet(signing.Config{
+		ActiveKid: "new",
+		Keys: []signing.KeyConfig{
+			{Kid: "old", Alg: signing.AlgorithmEdDSA, PublicKey: publicPEM},
+			{Kid: "new", Alg: signing.AlgorithmHS256, Secret: testHMACSecret},
+		},
+	})
+	assert.NoError(t, err)
+	_, err = verifier.Verify(tokenString)
+	assert.NoError(t, err)
+}
+
+func TestKeySet_RejectsUnknownKidAndAlgorithmMismatch(t *testing.T) {
+	_, publicPEM := newEd25519PEMs(t)
+	keySet, err := signing.NewKeySet(signing.Config{
+		ActiveKid: "hmac",
+		Keys: []signing.KeyConfig{
+			{Kid: "hmac", Alg: signing.AlgorithmHS256, Secret: testHMACSecret},
+			{Kid: "ed", Alg: signing.AlgorithmEdDSA, PublicKey: publicPEM},
+		},
+	})
+	assert.NoError(t, err)
+
+	unknown := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims(testClaims()))
+	unknown.Header["kid"] = "missing"
+	unknownString, err := unknown.SignedString([]byte(testHMACSecret))
+	assert.NoError(t, err)
+	_, err = keySet.Verify(unknownString)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown signing key")
+
+	// Un token HS256 que apunta a la clave Ed25519 no debe aceptarse
+	mismatch := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims(testClaims()))
+	mismatch.Header["kid"] = "ed"
+	mismatchString, err := mismatch.SignedString([]byte(publicPEM))
+	assert.NoError(t, err)
+	_, err = keySet.Verify(mismatchString)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected signing method")
+}
+
+func TestNewKeySet_InvalidConfig(t *testing.T) {
+	_, err := signing.NewKeySet(signing.Config{})
+	assert.Error(t, err)
+
+	_, err = signing.NewKeySet(signing.Config{
+		ActiveKid: "short",
+		Keys:      []signing.KeyConfig{{Kid: "short", Alg: signing.AlgorithmHS256, Secret: "secret_key"}},
+	})
+	assert.Error(t, err)
+
+	_, err = signing.NewKeySet(signing.Config{
+		ActiveKid: "missing",
+		Keys:      []signing.KeyConfig{{Kid: "hmac", Alg: signing.AlgorithmHS256, Secret: testHMACSecret}},
+	})
+	assert.Error(t, err)
+}
+
+func TestLoadFromEnv_RequiresConfiguredKeys(t *testing.T) {
+	t.Setenv("JWT_KEYS_FILE", "")
+	t.Setenv("JWT_SECRET", "")
+	t.Setenv("JWT_EPHEMERAL_KEY", "")
+
+	_, err := signing.LoadFromEnv()
+	assert.Error(t, err)
+
+	t.Setenv("JWT_EPHEMERAL_KEY", "true")
+	signer, err := signing.LoadFromEnv()
+	assert.NoError(t, err)
+	assert.NotNil(t, signer)
+}
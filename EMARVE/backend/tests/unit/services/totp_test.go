@@ -0,0 +1,73 @@
+package services
+
+import (
+	"backend/services/totp"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Secreto de los vectores de prueba de RFC 6238 ("12345678901234567890" en base32)
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode_RFC6238Vectors(t *testing.T) {
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+
+	for unix, expected := range vectors {
+		code, err := totp.Code(rfcSecret, time.Unix(unix, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, code, "t=%d", unix)
+	}
+}
+
+func TestTOTPValidate_AllowsClockSkewAndRejectsReplay(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	previous, err := totp.Code(rfcSecret, now.Add(-30*time.Second))
+	assert.NoError(t, err)
+
+	counter, ok := totp.Validate(rfcSecret, previous, now, 0)
+	assert.True(t, ok)
+	assert.Equal(t, totp.Counter(now)-1, counter)
+
+	// El mismo código no vale dos veces
+	_, ok = totp.Validate(rfcSecret, previous, now, counter)
+	assert.False(t, ok)
+
+	old, err := totp.Code(rfcSecret, now.Add(-2*time.Minute))
+	assert.NoError(t, err)
+	_, ok = totp.Validate(rfcSecret, old, now, 0)
+	assert.False(t, ok)
+
+	_, ok = totp.Validate(rfcSecret, "12345", now, 0)
+	assert.False(t, ok)
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := totp.URI("EMARVE", "admin@emarve.com", rfcSecret)
+
+	parsed, err := url.Parse(uri)
+	assert.NoError(t, err)
+	assert.Equal(t, "otpauth", parsed.Scheme)
+	assert.Equal(t, "totp", parsed.Host)
+	assert.True(t, strings.HasSuffix(parsed.Path, "EMARVE:admin@emarve.com"))
+	assert.Equal(t, rfcSecret, parsed.Query().Get("secret"))
+	assert.Equal(t, "EMARVE", parsed.Query().Get("issuer"))
+}
+
+func TestTOTPGenerateSecret(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+	assert.NoError(t, err)
+	assert.Len(t, secret, 32)
+
+	_, err = totp.Code(secret, time.Now())
+	assert.NoError(t, err)
+}
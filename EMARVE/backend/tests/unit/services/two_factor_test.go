@@ -0,0 +1,200 @@
+package services
+
+import (
+	"backend/domain"
+	"backend/interfaces"
+	"backend/services/totp"
+	"backend/services/users"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newAdminForTest registra en el mock un admin verificado con contraseña "password123"
+func newAdminForTest(t *testing.T, mockRepo *MockUserRepository, hasher interfaces.PasswordHasherInterface) *domain.User {
+	hash, err := hasher.Hash("password123")
+	assert.NoError(t, err)
+	admin := &domain.User{Id: 1, Email: "admin@example.com", PasswordHash: hash, Roles: []string{domain.RoleAdmin}, EmailVerifiedAt: verifiedNow()}
+	mockRepo.On("GetUserByEmail", "admin@example.com").Return(admin, nil)
+	mockRepo.On("GetUserById", int64(1)).Return(admin, nil)
+	return admin
+}
+
+func TestLogin_WithTOTPRequiresSecondStep(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	hasher := newTestHasher(t)
+	service := users.NewUserService(mockRepo, users.WithPasswordHasher(hasher))
+	newAdminForTest(t, mockRepo, hasher)
+
+	secret, err := totp.GenerateSecret()
+	assert.NoError(t, err)
+	mockRepo.On("GetTwoFactor", int64(1)).Return(&domain.TwoFactor{UserID: 1, Secret: secret, EnabledAt: verifiedNow()}, nil)
+
+	// Act
+	challenge, err := service.Login("admin@example.com", "password123", domain.ClientInfo{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, challenge.MFARequired)
+	assert.False(t, challenge.MFAEnrollmentRequired)
+	assert.Empty(t, challenge.Token)
+	assert.NotEmpty(t, challenge.MFAToken)
+	mockRepo.AssertNotCalled(t, "CreateSession", mock.Anything)
+
+	// El mfa_token no sirve como access token
+	_, err = service.ValidateToken("Bearer " + challenge.MFAToken)
+	assert.Error(t, err)
+
+	// Segundo paso
+	code, err := totp.Code(secret, time.Now())
+	assert.NoError(t, err)
+	mockRepo.On("UpdateTwoFactorCounter", int64(1), mock.AnythingOfType("int64")).Return(nil)
+	mockRepo.On("CreateSession", mock.AnythingOfType("domain.Session")).Return(domain.Session{Id: 10}, nil)
+
+	response, err := service.CompleteMFALogin(challenge.MFAToken, code, domain.ClientInfo{})
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, response.Token)
+	assert.NotEmpty(t, response.RefreshToken)
+	assert.Empty(t, response.RecoveryCodes)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCompleteMFALogin_InvalidCodeIsRejected(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	hasher := newTestHasher(t)
+	service := users.NewUserService(mockRepo, users.WithPasswordHasher(hasher))
+	newAdminForTest(t, mockRepo, hasher)
+
+	secret, err := totp.GenerateSecret()
+	assert.NoError(t, err)
+	mockRepo.On("GetTwoFactor", int64(1)).Return(&domain.TwoFactor{UserID: 1, Secret: secret, EnabledAt: verifiedNow()}, nil)
+	mockRepo.On("UseRecoveryCode", int64(1), mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(errors.New("invalid recovery code"))
+
+	challenge, err := service.Login("admin@example.com", "password123", domain.ClientInfo{})
+	assert.NoError(t, err)
+
+	// Act
+	_, err = service.CompleteMFALogin(challenge.MFAToken, "0000-0000-0000-0000", domain.ClientInfo{})
+	_, tokenErr := service.CompleteMFALogin("not-a-token", "123456", domain.ClientInfo{})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid authentication code")
+	assert.Error(t, tokenErr)
+	assert.Contains(t, tokenErr.Error(), "invalid or expired MFA token")
+	mockRepo.AssertNotCalled(t, "CreateSession", mock.Anything)
+}
+
+func TestCompleteMFALogin_RecoveryCode(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	hasher := newTestHasher(t)
+	service := users.NewUserService(mockRepo, users.WithPasswordHasher(hasher))
+	newAdminForTest(t, mockRepo, hasher)
+
+	secret, err := totp.GenerateSecret()
+	assert.NoError(t, err)
+	mockRepo.On("GetTwoFactor", int64(1)).Return(&domain.TwoFactor{UserID: 1, Secret: secret, EnabledAt: verifiedNow()}, nil)
+	mockRepo.On("UseRecoveryCode", int64(1), sha256Hex("abcdefghijklmnop"), mock.AnythingOfType("time.Time")).Return(nil)
+	mockRepo.On("CreateSession", mock.AnythingOfType("domain.Session")).Return(domain.Session{Id: 10}, nil)
+
+	challenge, err := service.Login("admin@example.com", "password123", domain.ClientInfo{})
+	assert.NoError(t, err)
+
+	// Act
+	response, err := service.CompleteMFALogin(challenge.MFAToken, "ABCD-EFGH-IJKL-MNOP", domain.ClientInfo{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotEmpty(t, response.Token)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLogin_AdminPolicyForcesEnrollment(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	hasher := newTestHasher(t)
+	service := users.NewUserService(mockRepo, users.WithPasswordHasher(hasher), users.WithAdmin2FARequired(true))
+	newAdminForTest(t, mockRepo, hasher)
+	mockRepo.On("GetTwoFactor", int64(1)).Return(nil, nil).Once()
+
+	// Act
+	challenge, err := service.Login("admin@example.com", "password123", domain.ClientInfo{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, challenge.MFARequired)
+	assert.True(t, challenge.MFAEnrollmentRequired)
+	assert.Empty(t, challenge.Token)
+
+	// Inscripción durante el login
+	var saved domain.TwoFactor
+	mockRepo.On("GetTwoFactor", int64(1)).Return(nil, nil).Once()
+	mockRepo.On("SaveTwoFactor", mock.AnythingOfType("domain.TwoFactor")).Run(func(args mock.Arguments) {
+		saved = args.Get(0).(domain.TwoFactor)
+	}).Return(nil)
+
+	enrollment, err := service.BeginMFAEnrollment(challenge.MFAToken)
+	assert.NoError(t, err)
+	assert.Equal(t, saved.Secret, enrollment.Secret)
+	assert.Contains(t, enrollment.OtpauthURI, "otpauth://totp/")
+	assert.Nil(t, saved.EnabledAt)
+
+	// El primer código confirma la inscripción y devuelve los códigos de recuperación
+	code, err := totp.Code(saved.Secret, time.Now())
+	assert.NoError(t, err)
+	mockRepo.On("GetTwoFactor", int64(1)).Return(&saved, nil)
+	mockRepo.On("EnableTwoFactor", int64(1), mock.AnythingOfType("int64"), mock.AnythingOfType("time.Time")).Return(nil)
+
+	var storedHashes []string
+	mockRepo.On("ReplaceRecoveryCodes", int64(1), mock.AnythingOfType("[]string")).Run(func(args mock.Arguments) {
+		storedHashes = args.Get(1).([]string)
+	}).Return(nil)
+	mockRepo.On("CreateSession", mock.AnythingOfType("domain.Session")).Return(domain.Session{Id: 10}, nil)
+
+	response, err := service.CompleteMFALogin(challenge.MFAToken, code, domain.ClientInfo{})
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, response.Token)
+	assert.Len(t, response.RecoveryCodes, 10)
+	assert.Len(t, storedHashes, 10)
+	assert.NotContains(t, storedHashes, response.RecoveryCodes[0])
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDisableTOTP_RequiredForAdmins(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo, users.WithAdmin2FARequired(true))
+	mockRepo.On("GetUserById", int64(1)).Return(&domain.User{Id: 1, Roles: []string{domain.RoleAdmin}}, nil)
+
+	// Act
+	err := service.DisableTOTP(1, "123456")
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "required for admin accounts")
+	mockRepo.AssertNotCalled(t, "DeleteTwoFactor", mock.Anything)
+}
+
+func TestBeginTOTPEnrollment_AlreadyEnabled(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+	mockRepo.On("GetUserById", int64(3)).Return(&domain.User{Id: 3, Email: "student@example.com"}, nil)
+	mockRepo.On("GetTwoFactor", int64(3)).Return(&domain.TwoFactor{UserID: 3, EnabledAt: verifiedNow()}, nil)
+
+	// Act
+	_, err := service.BeginTOTPEnrollment(3)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "already enabled")
+	mockRepo.AssertNotCalled(t, "SaveTwoFactor", mock.Anything)
+}
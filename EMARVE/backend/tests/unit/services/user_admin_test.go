@@ -0,0 +1,163 @@
+package services
+
+import (
+	"backend/domain"
+	"backend/services/users"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestListUsers_DefaultsAndHidesHash(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+
+	mockRepo.On("ListUsers", domain.UserQuery{Email: "example.com", Sort: "-nickname", Page: 1, PageSize: users.DefaultUsersPageSize}).
+		Return([]domain.User{{Id: 3, Nickname: "student", Email: "student@example.com", PasswordHash: "secret-hash", Roles: []string{domain.RoleStudent}}}, int64(41), nil)
+
+	// Act
+	page, err := service.ListUsers(domain.UserQuery{Email: " example.com ", Sort: "-nickname"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(41), page.Total)
+	assert.Equal(t, 1, page.Page)
+	assert.Len(t, page.Result, 1)
+	body, err := json.Marshal(page)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(body), "secret-hash")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestListUsers_CapsPageSizeAndRejectsUnknownSort(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+
+	mockRepo.On("ListUsers", domain.UserQuery{Page: 2, PageSize: users.MaxUsersPageSize}).Return([]domain.User{}, int64(0), nil)
+
+	// Act
+	_, capErr := service.ListUsers(domain.UserQuery{Page: 2, PageSize: 5000})
+	_, sortErr := service.ListUsers(domain.UserQuery{Sort: "password_hash"})
+
+	// Assert
+	assert.NoError(t, capErr)
+	assert.Error(t, sortErr)
+	mockRepo.AssertNumberOfCalls(t, "ListUsers", 1)
+}
+
+func TestPromoteUser_AddsAdminRole(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+
+	mockRepo.On("GetUserById", int64(5)).Return(&domain.User{Id: 5, Roles: []string{domain.RoleInstructor}}, nil)
+	mockRepo.On("SetUserRoles", int64(5), []string{domain.RoleAdmin, domain.RoleInstructor}).Return(nil)
+
+	// Act
+	err := service.PromoteUser(5, "", testActor)
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDemoteUser_FallsBackToStudent(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+
+	mockRepo.On("GetUserById", int64(5)).Return(&domain.User{Id: 5, Roles: []string{domain.RoleInstructor}}, nil)
+	mockRepo.On("SetUserRoles", int64(5), []string{domain.RoleStudent}).Return(nil)
+	mockRepo.On("RevokeUserSessions", int64(5), mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Act
+	err := service.DemoteUser(5, domain.RoleInstructor, testActor)
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDisableUser_RevokesSessions(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	recorder := &MockAuditRecorder{}
+	service := users.NewUserService(mockRepo, users.WithAuditRecorder(recorder))
+
+	mockRepo.On("GetUserById", int64(5)).Return(&domain.User{Id: 5}, nil)
+	mockRepo.On("SetUserDisabled", int64(5), mock.AnythingOfType("*time.Time")).Return(nil)
+	mockRepo.On("RevokeUserSessions", int64(5), mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Act
+	err := service.DisableUser(5, testActor)
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	assert.Equal(t, domain.AuditUserDisabled, recorder.events[0].Action)
+}
+
+func TestDisableUser_CannotDisableSelf(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+
+	// Act
+	err := service.DisableUser(testActor.UserID, testActor)
+
+	// Assert
+	assert.Error(t, err)
+	mockRepo.AssertNotCalled(t, "SetUserDisabled", mock.Anything, mock.Anything)
+}
+
+func TestLogin_DisabledAccountIsRejected(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	hasher := newTestHasher(t)
+	service := users.NewUserService(mockRepo, users.WithPasswordHasher(hasher))
+
+	hash, err := hasher.Hash("password123")
+	assert.NoError(t, err)
+	mockRepo.On("GetUserByEmail", "test@example.com").Return(&domain.User{
+		Id:              1,
+		Email:           "test@example.com",
+		PasswordHash:    hash,
+		EmailVerifiedAt: verifiedNow(),
+		DisabledAt:      verifiedNow(),
+	}, nil)
+
+	// Act
+	_, err = service.Login("test@example.com", "password123", domain.ClientInfo{IP: "127.0.0.1"})
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrAccountDisabled)
+	mockRepo.AssertNotCalled(t, "CreateSession", mock.Anything)
+}
+
+func TestForcePasswordReset_BlocksLoginAndSendsLink(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockMailer := new(MockMailer)
+	service := users.NewUserService(mockRepo, users.WithMailer(mockMailer))
+
+	mockRepo.On("GetUserById", int64(5)).Return(&domain.User{Id: 5, Nickname: "student", Email: "student@example.com"}, nil)
+	mockRepo.On("SetPasswordResetRequired", int64(5), true).Return(nil)
+	mockRepo.On("RevokeUserSessions", int64(5), mock.AnythingOfType("time.Time")).Return(nil)
+	mockRepo.On("InvalidateUserTokens", int64(5), domain.TokenPurposePasswordReset, mock.AnythingOfType("time.Time")).Return(nil)
+	mockRepo.On("CreateUserToken", mock.AnythingOfType("domain.UserToken")).Return(nil)
+	mockMailer.On("Send", mock.MatchedBy(func(email domain.Email) bool {
+		return email.To == "student@example.com"
+	})).Return(nil)
+
+	// Act
+	err := service.ForcePasswordReset(5, testActor)
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockMailer.AssertExpectations(t)
+}
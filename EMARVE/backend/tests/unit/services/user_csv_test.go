@@ -0,0 +1,160 @@
+package services
+
+import (
+	"backend/domain"
+	"backend/services/users"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func mockImportLookups(mockRepo *MockUserRepository) {
+	mockRepo.On("GetRoles").Return([]domain.Role{{Name: domain.RoleStudent}, {Name: domain.RoleInstructor}}, nil)
+	mockRepo.On("IsEmailTaken", "taken@example.com", int64(0)).Return(true, nil)
+	mockRepo.On("IsEmailTaken", mock.AnythingOfType("string"), int64(0)).Return(false, nil)
+	mockRepo.On("IsNicknameTaken", mock.AnythingOfType("string"), int64(0)).Return(false, nil)
+}
+
+func TestImportUsers_DryRunReportsRowErrors(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+	mockImportLookups(mockRepo)
+
+	csv := "Email,Nickname,Password,Roles\n" +
+		"ana@example.com,ana,secret1,instructor\n" +
+		"not-an-email,beto,,\n" +
+		"ANA@example.com,ana2,,\n" +
+		"taken@example.com,carla,,janitor\n" +
+		",,,\n"
+
+	// Act
+	report, err := service.ImportUsers(strings.NewReader(csv), domain.UserImportOptions{DryRun: true}, testActor)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 4, report.Total)
+	assert.Equal(t, 1, report.Valid)
+	assert.Equal(t, domain.ImportRowValid, report.Rows[0].Status)
+	assert.Equal(t, []string{domain.RoleInstructor}, report.Rows[0].Roles)
+	assert.Equal(t, 3, report.Rows[1].Line)
+	assert.Contains(t, report.Rows[1].Errors, "invalid email address")
+	assert.Contains(t, report.Rows[2].Errors, "email repeated from line 2")
+	assert.Contains(t, report.Rows[3].Errors, domain.ErrEmailTaken.Error())
+	assert.Contains(t, report.Rows[3].Errors, `unknown role "janitor"`)
+	mockRepo.AssertNotCalled(t, "CreateUser", mock.Anything)
+}
+
+func TestImportUsers_InvalidRowsBlockTheImport(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+	mockImportLookups(mockRepo)
+
+	csv := "nickname,email\nana,ana@example.com\nbeto,taken@example.com\n"
+
+	// Act
+	report, err := service.ImportUsers(strings.NewReader(csv), domain.UserImportOptions{}, testActor)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrImportInvalid)
+	assert.Equal(t, 0, report.Created)
+	mockRepo.AssertNotCalled(t, "CreateUser", mock.Anything)
+}
+
+func TestImportUsers_CreatesAndSubscribes(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockMailer := new(MockMailer)
+	hasher := newTestHasher(t)
+	recorder := &MockAuditRecorder{}
+	service := users.NewUserService(mockRepo, users.WithPasswordHasher(hasher), users.WithMailer(mockMailer), users.WithAuditRecorder(recorder))
+	mockImportLookups(mockRepo)
+
+	mockRepo.On("GetCourseById", int64(7)).Return(&domain.Course{Id: 7}, nil)
+	mockRepo.On("CreateUser", mock.MatchedBy(func(user domain.User) bool {
+		valid, err := hasher.Verify("secret1", user.PasswordHash)
+		return user.Email == "ana@example.com" && err == nil && valid
+	})).Return(nil)
+	mockRepo.On("GetUserByEmail", "ana@example.com").Return(&domain.User{Id: 30, Nickname: "ana", Email: "ana@example.com"}, nil)
+	mockRepo.On("InsertSubscription", int64(30), int64(7)).Return(nil)
+	mockRepo.On("InvalidateUserTokens", int64(30), domain.TokenPurposeEmailVerification, mock.AnythingOfType("time.Time")).Return(nil)
+	mockRepo.On("CreateUserToken", mock.AnythingOfType("domain.UserToken")).Return(nil)
+	mockMailer.On("Send", mock.AnythingOfType("domain.Email")).Return(nil)
+
+	csv := "nickname,email,password\nana,ana@example.com,secret1\n"
+
+	// Act
+	report, err := service.ImportUsers(strings.NewReader(csv), domain.UserImportOptions{CourseIDs: []int64{7}}, testActor)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, domain.ImportRowCreated, report.Rows[0].Status)
+	assert.Equal(t, int64(30), report.Rows[0].UserID)
+	assert.Equal(t, domain.AuditUserImported, recorder.events[0].Action)
+	mockRepo.AssertCalled(t, "InsertSubscription", int64(30), int64(7))
+	mockMailer.AssertExpectations(t)
+}
+
+func TestImportUsers_RolesRequireRoleAssign(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+	mockImportLookups(mockRepo)
+	userManager := domain.Actor{UserID: 8, Role: "support", Permissions: []string{domain.PermUserManage}}
+
+	csv := "nickname,email,roles\nana,ana@example.com,instructor\nbeto,beto@example.com,student\n"
+
+	// Act
+	report, err := service.ImportUsers(strings.NewReader(csv), domain.UserImportOptions{}, userManager)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrImportInvalid)
+	assert.Contains(t, report.Rows[0].Errors, `assigning role "instructor" requires the role:assign permission`)
+	assert.Empty(t, report.Rows[1].Errors)
+	mockRepo.AssertNotCalled(t, "CreateUser", mock.Anything)
+}
+
+func TestImportUsers_MissingColumn(t *testing.T) {
+	// Arrange
+	service := users.NewUserService(new(MockUserRepository))
+
+	// Act
+	_, err := service.ImportUsers(strings.NewReader("nickname,password\nana,secret\n"), domain.UserImportOptions{DryRun: true}, testActor)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `"email"`)
+}
+
+func TestExportUsersCSV_WritesAllPages(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+
+	firstPage := make([]domain.User, users.MaxUsersPageSize)
+	for i := range firstPage {
+		firstPage[i] = domain.User{Id: int64(i + 1), Nickname: "user", Email: "user@example.com", Roles: []string{domain.RoleStudent}}
+	}
+	total := int64(users.MaxUsersPageSize + 1)
+	mockRepo.On("ListUsers", domain.UserQuery{Page: 1, PageSize: users.MaxUsersPageSize}).Return(firstPage, total, nil)
+	mockRepo.On("ListUsers", domain.UserQuery{Page: 2, PageSize: users.MaxUsersPageSize}).
+		Return([]domain.User{{Id: 999, Nickname: "=HYPERLINK()", Email: "last@example.com", Roles: []string{domain.RoleAdmin, domain.RoleInstructor}}}, total, nil)
+
+	// Act
+	var buffer bytes.Buffer
+	err := service.ExportUsersCSV(domain.UserQuery{}, &buffer)
+
+	// Assert
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	assert.Len(t, lines, users.MaxUsersPageSize+2)
+	assert.Equal(t, "id,nickname,email,roles,email_verified_at,disabled_at", lines[0])
+	assert.Equal(t, "999,'=HYPERLINK(),last@example.com,admin;instructor,,", lines[len(lines)-1])
+	mockRepo.AssertExpectations(t)
+}
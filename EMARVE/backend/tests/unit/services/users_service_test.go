@@ -2,13 +2,20 @@ package services
 
 import (
 	"backend/domain"
+	"backend/interfaces"
+	"backend/services/passwords"
+	"backend/services/signing"
 	"backend/services/users"
 	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
+	jwt "github.com/golang-jwt/jwt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -39,6 +46,167 @@ func (m *MockUserRepository) GetUserById(id int64) (*domain.User, error) {
 	return args.Get(0).(*domain.User), args.Error(1)
 }
 
+func (m *MockUserRepository) UpdatePasswordHash(userID int64, passwordHash string) error {
+	args := m.Called(userID, passwordHash)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) SetEmailVerified(userID int64, verifiedAt time.Time) error {
+	args := m.Called(userID, verifiedAt)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) IsNicknameTaken(nickname string, excludeUserID int64) (bool, error) {
+	args := m.Called(nickname, excludeUserID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) IsEmailTaken(email string, excludeUserID int64) (bool, error) {
+	args := m.Called(email, excludeUserID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdateNickname(userID int64, nickname string) error {
+	args := m.Called(userID, nickname)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) SetPendingEmail(userID int64, email string) error {
+	args := m.Called(userID, email)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) ConfirmEmailChange(userID int64, email string, verifiedAt time.Time) error {
+	args := m.Called(userID, email, verifiedAt)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetCourseStaffMember(courseID int64, userID int64) (*domain.CourseStaff, error) {
+	args := m.Called(courseID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CourseStaff), args.Error(1)
+}
+
+func (m *MockUserRepository) GetRoles() ([]domain.Role, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Role), args.Error(1)
+}
+
+func (m *MockUserRepository) SetUserRoles(userID int64, roleNames []string) error {
+	args := m.Called(userID, roleNames)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) CreateSession(session domain.Session) (domain.Session, error) {
+	args := m.Called(session)
+	return args.Get(0).(domain.Session), args.Error(1)
+}
+
+func (m *MockUserRepository) GetSessionById(id int64) (*domain.Session, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Session), args.Error(1)
+}
+
+func (m *MockUserRepository) GetSessionByRefreshHash(refreshTokenHash string) (*domain.Session, error) {
+	args := m.Called(refreshTokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Session), args.Error(1)
+}
+
+func (m *MockUserRepository) GetActiveSessionsByUserId(userID int64) ([]domain.Session, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Session), args.Error(1)
+}
+
+func (m *MockUserRepository) RotateSessionRefresh(id int64, currentHash, newHash string, lastUsedAt time.Time) error {
+	args := m.Called(id, currentHash, newHash, lastUsedAt)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) RevokeSession(id int64, revokedAt time.Time) error {
+	args := m.Called(id, revokedAt)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) RevokeUserSessions(userID int64, revokedAt time.Time) error {
+	args := m.Called(userID, revokedAt)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) CreateUserToken(token domain.UserToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetUserTokenByHash(tokenHash string) (*domain.UserToken, error) {
+	args := m.Called(tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.UserToken), args.Error(1)
+}
+
+func (m *MockUserRepository) ConsumeUserToken(id int64, usedAt time.Time) error {
+	args := m.Called(id, usedAt)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) InvalidateUserTokens(userID int64, purpose string, usedAt time.Time) error {
+	args := m.Called(userID, purpose, usedAt)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetTwoFactor(userID int64) (*domain.TwoFactor, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TwoFactor), args.Error(1)
+}
+
+func (m *MockUserRepository) SaveTwoFactor(twoFactor domain.TwoFactor) error {
+	args := m.Called(twoFactor)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) EnableTwoFactor(userID int64, counter int64, enabledAt time.Time) error {
+	args := m.Called(userID, counter, enabledAt)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateTwoFactorCounter(userID int64, counter int64) error {
+	args := m.Called(userID, counter)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) DeleteTwoFactor(userID int64) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) ReplaceRecoveryCodes(userID int64, codeHashes []string) error {
+	args := m.Called(userID, codeHashes)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UseRecoveryCode(userID int64, codeHash string, usedAt time.Time) error {
+	args := m.Called(userID, codeHash, usedAt)
+	return args.Error(0)
+}
+
 func (m *MockUserRepository) AddComment(userID, courseID int64, comment string) error {
 	args := m.Called(userID, courseID, comment)
 	return args.Error(0)
@@ -60,6 +228,58 @@ func (m *MockUserRepository) GetCourseById(courseID int64) (*domain.Course, erro
 	return args.Get(0).(*domain.Course), args.Error(1)
 }
 
+func (m *MockUserRepository) DeleteUser(userID int64) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) ListUsers(query domain.UserQuery) ([]domain.User, int64, error) {
+	args := m.Called(query)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]domain.User), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockUserRepository) SetUserDisabled(userID int64, disabledAt *time.Time) error {
+	args := m.Called(userID, disabledAt)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) SetPasswordResetRequired(userID int64, required bool) error {
+	args := m.Called(userID, required)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetCommentsByUserId(userID int64) ([]domain.Comment, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Comment), args.Error(1)
+}
+
+func (m *MockUserRepository) GetSoleOwnedCourseIDs(userID int64) ([]int64, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int64), args.Error(1)
+}
+
+func (m *MockUserRepository) GetFilesByUserId(userID int64) ([]domain.File, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.File), args.Error(1)
+}
+
+func (m *MockUserRepository) InsertSubscription(userID, courseID int64) error {
+	args := m.Called(userID, courseID)
+	return args.Error(0)
+}
+
 func (m *MockUserRepository) InsertComment(userID, courseID int64, comment string) error {
 	args := m.Called(userID, courseID, comment)
 	return args.Error(0)
@@ -70,22 +290,98 @@ func (m *MockUserRepository) SaveFile(file domain.File) error {
 	return args.Error(0)
 }
 
+// newTestHasher crea un hasher bcrypt de costo mínimo para que los tests sean rápidos
+// verifiedNow devuelve una fecha de verificación para los usuarios que pueden hacer login
+func verifiedNow() *time.Time {
+	now := time.Now()
+	return &now
+}
+
+func newTestHasher(t *testing.T) interfaces.PasswordHasherInterface {
+	config := passwords.DefaultConfig()
+	config.BcryptCost = 4
+	hasher, err := passwords.NewHasher(config)
+	assert.NoError(t, err)
+	return hasher
+}
+
 func TestLogin_ValidCredentials(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockUserRepository)
-	service := users.NewUserService(mockRepo)
+	hasher := newTestHasher(t)
+	service := users.NewUserService(mockRepo, users.WithPasswordHasher(hasher))
+
+	hash, err := hasher.Hash("password123")
+	assert.NoError(t, err)
 
 	expectedUser := &domain.User{
-		Id:           1,
-		Email:        "test@example.com",
-		PasswordHash: fmt.Sprintf("%x", md5.Sum([]byte("password123"))),
-		Type:         true,
+		Id:              1,
+		Email:           "test@example.com",
+		PasswordHash:    hash,
+		EmailVerifiedAt: verifiedNow(),
+		Roles:           []string{domain.RoleAdmin},
+	}
+	mockRepo.On("GetUserByEmail", "test@example.com").Return(expectedUser, nil)
+	mockRepo.On("GetTwoFactor", mock.AnythingOfType("int64")).Return(nil, nil)
+	mockRepo.On("CreateSession", mock.AnythingOfType("domain.Session")).Return(domain.Session{Id: 10}, nil)
+	response, err := service.Login("test@example.com", "password123", domain.ClientInfo{})
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, response.Token)
+	mockRepo.AssertNotCalled(t, "UpdatePasswordHash", mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLogin_LegacyMD5HashIsMigrated(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo, users.WithPasswordHasher(newTestHasher(t)))
+
+	expectedUser := &domain.User{
+		Id:              1,
+		Email:           "test@example.com",
+		PasswordHash:    fmt.Sprintf("%x", md5.Sum([]byte("password123"))),
+		EmailVerifiedAt: verifiedNow(),
+		Roles:           []string{domain.RoleAdmin},
 	}
 	mockRepo.On("GetUserByEmail", "test@example.com").Return(expectedUser, nil)
-	token, err := service.Login("test@example.com", "password123")
+	mockRepo.On("UpdatePasswordHash", int64(1), mock.MatchedBy(func(hash string) bool {
+		return passwords.Algorithm(hash) == passwords.AlgorithmBcrypt
+	})).Return(nil)
+
+	// Act
+	mockRepo.On("GetTwoFactor", mock.AnythingOfType("int64")).Return(nil, nil)
+	mockRepo.On("CreateSession", mock.AnythingOfType("domain.Session")).Return(domain.Session{Id: 10}, nil)
+	response, err := service.Login("test@example.com", "password123", domain.ClientInfo{})
 
+	// Assert
 	assert.NoError(t, err)
-	assert.NotEmpty(t, token)
+	assert.NotEmpty(t, response.Token)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLogin_RehashErrorDoesNotBlockLogin(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo, users.WithPasswordHasher(newTestHasher(t)))
+
+	expectedUser := &domain.User{
+		Id:              1,
+		Email:           "test@example.com",
+		PasswordHash:    fmt.Sprintf("%x", md5.Sum([]byte("password123"))),
+		EmailVerifiedAt: verifiedNow(),
+	}
+	mockRepo.On("GetUserByEmail", "test@example.com").Return(expectedUser, nil)
+	mockRepo.On("UpdatePasswordHash", int64(1), mock.Anything).Return(errors.New("database error"))
+
+	// Act
+	mockRepo.On("GetTwoFactor", mock.AnythingOfType("int64")).Return(nil, nil)
+	mockRepo.On("CreateSession", mock.AnythingOfType("domain.Session")).Return(domain.Session{Id: 10}, nil)
+	response, err := service.Login("test@example.com", "password123", domain.ClientInfo{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotEmpty(t, response.Token)
 	mockRepo.AssertExpectations(t)
 }
 
@@ -98,17 +394,17 @@ func TestLogin_InvalidCredentials(t *testing.T) {
 		Id:           1,
 		Email:        "test@example.com",
 		PasswordHash: "wrong-hash",
-		Type:         false,
+		Roles:        []string{domain.RoleStudent},
 	}
 
 	mockRepo.On("GetUserByEmail", "test@example.com").Return(expectedUser, nil)
 
 	// Act
-	token, err := service.Login("test@example.com", "password123")
+	response, err := service.Login("test@example.com", "password123", domain.ClientInfo{})
 
 	// Assert
 	assert.Error(t, err)
-	assert.Empty(t, token)
+	assert.Empty(t, response.Token)
 	assert.Contains(t, err.Error(), "invalid credentials")
 	mockRepo.AssertExpectations(t)
 }
@@ -121,11 +417,11 @@ func TestLogin_UserNotFound(t *testing.T) {
 	mockRepo.On("GetUserByEmail", "nonexistent@example.com").Return(nil, errors.New("user not found"))
 
 	// Act
-	token, err := service.Login("nonexistent@example.com", "password123")
+	response, err := service.Login("nonexistent@example.com", "password123", domain.ClientInfo{})
 
 	// Assert
 	assert.Error(t, err)
-	assert.Empty(t, token)
+	assert.Empty(t, response.Token)
 	assert.Contains(t, err.Error(), "error getting user from DB")
 	mockRepo.AssertExpectations(t)
 }
@@ -136,11 +432,11 @@ func TestLogin_EmptyEmail(t *testing.T) {
 	service := users.NewUserService(mockRepo)
 
 	// Act
-	token, err := service.Login("", "password123")
+	response, err := service.Login("", "password123", domain.ClientInfo{})
 
 	// Assert
 	assert.Error(t, err)
-	assert.Empty(t, token)
+	assert.Empty(t, response.Token)
 	assert.Contains(t, err.Error(), "email is required")
 }
 
@@ -150,30 +446,65 @@ func TestLogin_EmptyPassword(t *testing.T) {
 	service := users.NewUserService(mockRepo)
 
 	// Act
-	token, err := service.Login("test@example.com", "")
+	response, err := service.Login("test@example.com", "", domain.ClientInfo{})
 
 	// Assert
 	assert.Error(t, err)
-	assert.Empty(t, token)
+	assert.Empty(t, response.Token)
 	assert.Contains(t, err.Error(), "password is required")
 }
 
 func TestUserRegister_ValidData(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockUserRepository)
-	service := users.NewUserService(mockRepo)
+	mockMailer := new(MockMailer)
+	hasher := newTestHasher(t)
+	service := users.NewUserService(mockRepo, users.WithPasswordHasher(hasher), users.WithMailer(mockMailer))
+
+	mockRepo.On("CreateUser", mock.MatchedBy(func(user domain.User) bool {
+		valid, err := hasher.Verify("password123", user.PasswordHash)
+		return user.Nickname == "testuser" &&
+			user.Email == "test@example.com" &&
+			len(user.Roles) == 1 && user.Roles[0] == domain.RoleStudent &&
+			user.EmailVerifiedAt == nil &&
+			passwords.Algorithm(user.PasswordHash) == passwords.AlgorithmBcrypt &&
+			err == nil && valid
+	})).Return(nil)
+	mockRepo.On("GetUserByEmail", "test@example.com").Return(&domain.User{Id: 5, Email: "test@example.com"}, nil)
+	mockRepo.On("InvalidateUserTokens", int64(5), domain.TokenPurposeEmailVerification, mock.AnythingOfType("time.Time")).Return(nil)
+	mockRepo.On("CreateUserToken", mock.MatchedBy(func(token domain.UserToken) bool {
+		return token.UserID == 5 && token.Purpose == domain.TokenPurposeEmailVerification
+	})).Return(nil)
+	mockMailer.On("Send", mock.MatchedBy(func(email domain.Email) bool {
+		return email.To == "test@example.com" && strings.Contains(email.Body, "/users/verify?token=")
+	})).Return(nil)
 
-	expectedUser := domain.User{
-		Nickname:     "testuser",
-		Email:        "test@example.com",
-		PasswordHash: fmt.Sprintf("%x", md5.Sum([]byte("password123"))),
-		Type:         false,
-	}
+	// Act
+	result, err := service.UserRegister("testuser", "test@example.com", "password123", false)
 
-	mockRepo.On("CreateUser", expectedUser).Return(nil)
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, result)
+	mockRepo.AssertExpectations(t)
+	mockMailer.AssertExpectations(t)
+}
+
+func TestUserRegister_TypeDoesNotGrantAdmin(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockMailer := new(MockMailer)
+	service := users.NewUserService(mockRepo, users.WithPasswordHasher(newTestHasher(t)), users.WithMailer(mockMailer))
+
+	mockRepo.On("CreateUser", mock.MatchedBy(func(user domain.User) bool {
+		return len(user.Roles) == 1 && user.Roles[0] == domain.RoleStudent
+	})).Return(nil)
+	mockRepo.On("GetUserByEmail", "test@example.com").Return(&domain.User{Id: 5, Email: "test@example.com"}, nil)
+	mockRepo.On("InvalidateUserTokens", int64(5), domain.TokenPurposeEmailVerification, mock.AnythingOfType("time.Time")).Return(nil)
+	mockRepo.On("CreateUserToken", mock.AnythingOfType("domain.UserToken")).Return(nil)
+	mockMailer.On("Send", mock.AnythingOfType("domain.Email")).Return(nil)
 
 	// Act
-	result, err := service.UserRegister("testuser", "test@example.com", "password123", false)
+	result, err := service.UserRegister("testuser", "test@example.com", "password123", true)
 
 	// Assert
 	assert.NoError(t, err)
@@ -181,6 +512,20 @@ func TestUserRegister_ValidData(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestUserRegister_InvalidEmail(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+
+	// Act
+	_, err := service.UserRegister("testuser", "not-an-email", "password123", false)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid email address")
+	mockRepo.AssertNotCalled(t, "CreateUser", mock.Anything)
+}
+
 func TestUserRegister_EmptyNickname(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockUserRepository)
@@ -386,6 +731,58 @@ func TestUserAuthentication_EmptyToken(t *testing.T) {
 	assert.Contains(t, err.Error(), "bearer token is required")
 }
 
+func TestValidateToken_LoginTokenRoundTrip(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	hasher := newTestHasher(t)
+	service := users.NewUserService(mockRepo, users.WithPasswordHasher(hasher))
+
+	hash, err := hasher.Hash("password123")
+	assert.NoError(t, err)
+	mockRepo.On("GetUserByEmail", "admin@example.com").Return(&domain.User{
+		Id:              7,
+		Email:           "admin@example.com",
+		PasswordHash:    hash,
+		EmailVerifiedAt: verifiedNow(),
+		Roles:           []string{domain.RoleAdmin},
+	}, nil)
+
+	mockRepo.On("GetTwoFactor", mock.AnythingOfType("int64")).Return(nil, nil)
+	mockRepo.On("CreateSession", mock.MatchedBy(func(session domain.Session) bool {
+		return session.UserID == 7 && session.IP == "10.0.0.1" && len(session.RefreshTokenHash) == 64
+	})).Return(domain.Session{Id: 10}, nil)
+	mockRepo.On("GetSessionById", int64(10)).Return(&domain.Session{
+		Id:        10,
+		UserID:    7,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+
+	response, err := service.Login("admin@example.com", "password123", domain.ClientInfo{IP: "10.0.0.1"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, response.RefreshToken)
+	assert.Equal(t, "Bearer", response.TokenType)
+	assert.Equal(t, int64(900), response.ExpiresIn)
+	token := response.Token
+
+	// Act
+	claims, err := service.ValidateToken("Bearer " + token)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), claims.UserID)
+	assert.Equal(t, domain.RoleAdmin, claims.Role)
+	assert.Equal(t, int64(10), claims.SessionID)
+	assert.NotEmpty(t, claims.TokenID)
+
+	userType, err := service.UserAuthentication("Bearer " + token)
+	assert.NoError(t, err)
+	assert.Equal(t, "admin", userType)
+
+	userID, err := service.GetUserID("Bearer " + token)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, userID)
+}
+
 // Tests para GetUserID
 func TestGetUserID_ValidToken(t *testing.T) {
 	// Arrange
@@ -426,7 +823,7 @@ func TestGetUserById_Success(t *testing.T) {
 		Id:       1,
 		Nickname: "usuario1",
 		Email:    "usuario1@test.com",
-		Type:     false,
+		Roles:    []string{domain.RoleStudent},
 	}
 	mockRepo.On("GetUserById", int64(1)).Return(expectedUser, nil)
 
@@ -478,7 +875,7 @@ func TestAddComment_Success(t *testing.T) {
 		Id:       1,
 		Nickname: "usuario1",
 		Email:    "usuario1@test.com",
-		Type:     false,
+		Roles:    []string{domain.RoleStudent},
 	}
 	expectedCourse := &domain.Course{
 		Id:          1,
@@ -510,7 +907,7 @@ func TestAddComment_RepositoryError(t *testing.T) {
 		Id:       1,
 		Nickname: "usuario1",
 		Email:    "usuario1@test.com",
-		Type:     false,
+		Roles:    []string{domain.RoleStudent},
 	}
 	expectedCourse := &domain.Course{
 		Id:          1,
@@ -533,3 +930,252 @@ func TestAddComment_RepositoryError(t *testing.T) {
 	assert.Contains(t, err.Error(), "error inserting comment into DB")
 	mockRepo.AssertExpectations(t)
 }
+
+// loginForTest hace login con un usuario estudiante y devuelve la respuesta de tokens
+func loginForTest(t *testing.T, mockRepo *MockUserRepository, service interfaces.UserServiceInterface, hasher interfaces.PasswordHasherInterface) domain.LoginResponse {
+	hash, err := hasher.Hash("password123")
+	assert.NoError(t, err)
+	mockRepo.On("GetUserByEmail", "student@example.com").Return(&domain.User{
+		Id:              3,
+		Email:           "student@example.com",
+		PasswordHash:    hash,
+		EmailVerifiedAt: verifiedNow(),
+	}, nil)
+	mockRepo.On("GetTwoFactor", mock.AnythingOfType("int64")).Return(nil, nil)
+	mockRepo.On("CreateSession", mock.AnythingOfType("domain.Session")).Return(domain.Session{Id: 20}, nil).Once()
+
+	response, err := service.Login("student@example.com", "password123", domain.ClientInfo{})
+	assert.NoError(t, err)
+	return response
+}
+
+func TestValidateToken_RevokedSession(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	hasher := newTestHasher(t)
+	service := users.NewUserService(mockRepo, users.WithPasswordHasher(hasher))
+	response := loginForTest(t, mockRepo, service, hasher)
+
+	revokedAt := time.Now()
+	mockRepo.On("GetSessionById", int64(20)).Return(&domain.Session{
+		Id:        20,
+		UserID:    3,
+		ExpiresAt: time.Now().Add(time.Hour),
+		RevokedAt: &revokedAt,
+	}, nil)
+
+	// Act
+	_, err := service.ValidateToken("Bearer " + response.Token)
+	_, authErr := service.UserAuthentication("Bearer " + response.Token)
+	_, idErr := service.GetUserID("Bearer " + response.Token)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "session has been revoked")
+	assert.Error(t, authErr)
+	assert.Error(t, idErr)
+}
+
+func TestValidateToken_ExpiredAccessToken(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	hasher := newTestHasher(t)
+	config := users.DefaultTokenConfig()
+	config.AccessTTL = -time.Minute
+	service := users.NewUserService(mockRepo, users.WithPasswordHasher(hasher), users.WithTokenConfig(config))
+	response := loginForTest(t, mockRepo, service, hasher)
+
+	// Act
+	_, err := service.ValidateToken("Bearer " + response.Token)
+
+	// Assert
+	assert.Error(t, err)
+	mockRepo.AssertNotCalled(t, "GetSessionById", mock.Anything)
+}
+
+func TestValidateToken_TokenWithoutExpirationIsRejected(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	signer, err := signing.NewKeySet(signing.Config{
+		ActiveKid: "test",
+		Keys:      []signing.KeyConfig{{Kid: "test", Alg: signing.AlgorithmHS256, Secret: "0123456789abcdef0123456789abcdef"}},
+	})
+	assert.NoError(t, err)
+	service := users.NewUserService(mockRepo, users.WithTokenSigner(signer))
+
+	// Token con el formato anterior: solo userID y type, sin exp ni sesión
+	tokenString, err := signer.Sign(map[string]interface{}{"userID": 1, "type": true})
+	assert.NoError(t, err)
+
+	// Act
+	_, err = service.ValidateToken("Bearer " + tokenString)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "token has no valid expiration")
+}
+
+func TestValidateToken_TokenWithoutKidIsRejected(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+
+	// Token firmado con la antigua clave fija compartida por todos los despliegues
+	legacy := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"userID": 1, "type": true})
+	tokenString, err := legacy.SignedString([]byte("secret_key"))
+	assert.NoError(t, err)
+
+	// Act
+	_, err = service.ValidateToken("Bearer " + tokenString)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown signing key")
+}
+
+func TestRefreshToken_RotatesRefreshToken(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	hasher := newTestHasher(t)
+	service := users.NewUserService(mockRepo, users.WithPasswordHasher(hasher))
+	response := loginForTest(t, mockRepo, service, hasher)
+
+	currentHash := refreshHash(response.RefreshToken)
+	session := &domain.Session{Id: 20, UserID: 3, RefreshTokenHash: currentHash, ExpiresAt: time.Now().Add(time.Hour)}
+	mockRepo.On("GetSessionByRefreshHash", currentHash).Return(session, nil)
+	mockRepo.On("GetUserById", int64(3)).Return(&domain.User{Id: 3}, nil)
+	mockRepo.On("RotateSessionRefresh", int64(20), currentHash, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Act
+	refreshed, err := service.RefreshToken(response.RefreshToken, domain.ClientInfo{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotEmpty(t, refreshed.Token)
+	assert.NotEqual(t, response.RefreshToken, refreshed.RefreshToken)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRefreshToken_UnknownToken(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+	mockRepo.On("GetSessionByRefreshHash", mock.AnythingOfType("string")).Return(nil, errors.New("record not found"))
+
+	// Act
+	_, err := service.RefreshToken("unknown", domain.ClientInfo{})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid refresh token")
+}
+
+func TestRefreshToken_RevokedSession(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+	revokedAt := time.Now()
+	mockRepo.On("GetSessionByRefreshHash", mock.AnythingOfType("string")).Return(&domain.Session{
+		Id:        20,
+		UserID:    3,
+		ExpiresAt: time.Now().Add(time.Hour),
+		RevokedAt: &revokedAt,
+	}, nil)
+
+	// Act
+	_, err := service.RefreshToken("revoked", domain.ClientInfo{})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "session has been revoked")
+	mockRepo.AssertNotCalled(t, "RotateSessionRefresh", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRefreshToken_ReusedTokenRevokesSession(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+	mockRepo.On("GetSessionByRefreshHash", refreshHash("rotated")).Return(&domain.Session{
+		Id:                  20,
+		UserID:              3,
+		RefreshTokenHash:    refreshHash("current"),
+		PreviousRefreshHash: refreshHash("rotated"),
+		ExpiresAt:           time.Now().Add(time.Hour),
+	}, nil)
+	mockRepo.On("RevokeSession", int64(20), mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Act
+	_, err := service.RefreshToken("rotated", domain.ClientInfo{})
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrRefreshTokenReused)
+	mockRepo.AssertNotCalled(t, "RotateSessionRefresh", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRefreshToken_ConcurrentRotationRevokesSession(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+	mockRepo.On("GetSessionByRefreshHash", refreshHash("current")).Return(&domain.Session{
+		Id:               20,
+		UserID:           3,
+		RefreshTokenHash: refreshHash("current"),
+		ExpiresAt:        time.Now().Add(time.Hour),
+	}, nil)
+	mockRepo.On("GetUserById", int64(3)).Return(&domain.User{Id: 3}, nil)
+	mockRepo.On("RotateSessionRefresh", int64(20), refreshHash("current"), mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(domain.ErrRefreshTokenReused)
+	mockRepo.On("RevokeSession", int64(20), mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Act
+	_, err := service.RefreshToken("current", domain.ClientInfo{})
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrRefreshTokenReused)
+	mockRepo.AssertExpectations(t)
+}
+
+// refreshHash calcula el hash con el que el servicio guarda los refresh tokens
+func refreshHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestLogout_RevokesSession(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+	mockRepo.On("RevokeSession", int64(20), mock.AnythingOfType("time.Time")).Return(nil)
+
+	err := service.Logout(20)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestListSessions_MarksCurrent(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+	mockRepo.On("GetActiveSessionsByUserId", int64(3)).Return([]domain.Session{
+		{Id: 20, UserID: 3, IP: "10.0.0.1"},
+		{Id: 21, UserID: 3, IP: "10.0.0.2"},
+	}, nil)
+
+	sessions, err := service.ListSessions(3, 21)
+
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 2)
+	assert.False(t, sessions[0].Current)
+	assert.True(t, sessions[1].Current)
+}
+
+func TestRevokeSession_OtherUsersSession(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := users.NewUserService(mockRepo)
+	mockRepo.On("GetSessionById", int64(30)).Return(&domain.Session{Id: 30, UserID: 99}, nil)
+
+	err := service.RevokeSession(3, 30)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "session 30 not found")
+	mockRepo.AssertNotCalled(t, "RevokeSession", mock.Anything, mock.Anything)
+}
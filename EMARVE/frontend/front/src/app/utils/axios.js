@@ -20,6 +20,89 @@ const api = axios.create({
   baseURL: getApiBaseURL(),
 });
 
+// Adjuntar el token JWT a cada request si el usuario inició sesión
+api.interceptors.request.use(function (config) {
+  const token =
+    typeof window !== "undefined" ? localStorage.getItem("tokenId") : null;
+  if (token && !config.headers.Authorization) {
+    config.headers.Authorization = `Bearer ${token}`;
+  }
+  return config;
+});
+
+// Guardar los tokens de una sesión: el access token dura poco y el refresh token se usa para renovarlo
+function storeSession(session) {
+  if (!session.token) {
+    return;
+  }
+  localStorage.setItem("tokenType", session.token_type || "Bearer");
+  localStorage.setItem("tokenId", session.token);
+  if (session.refresh_token) {
+    localStorage.setItem("refreshToken", session.refresh_token);
+  }
+}
+
+function clearSession() {
+  localStorage.removeItem("tokenType");
+  localStorage.removeItem("tokenId");
+  localStorage.removeItem("refreshToken");
+  localStorage.removeItem("userId");
+}
+
+// Un solo refresh en curso: si varios requests reciben 401 a la vez esperan el mismo
+let refreshing = null;
+
+function refreshSession() {
+  if (!refreshing) {
+    const refreshToken = localStorage.getItem("refreshToken");
+    refreshing = axios
+      .post(`${getApiBaseURL()}/users/refresh`, { refresh_token: refreshToken })
+      .then(function (response) {
+        storeSession(response.data);
+        return response.data.token;
+      })
+      .finally(function () {
+        refreshing = null;
+      });
+  }
+  return refreshing;
+}
+
+// Cuando el access token vence, renovarlo con el refresh token y repetir el request una vez.
+// Si el refresh falla la sesión terminó y se limpian los tokens guardados.
+api.interceptors.response.use(
+  function (response) {
+    return response;
+  },
+  function (error) {
+    const original = error.config;
+    const canRefresh =
+      typeof window !== "undefined" &&
+      error.response &&
+      error.response.status === 401 &&
+      original &&
+      !original._retry &&
+      !original.url.startsWith("/users/login") &&
+      localStorage.getItem("refreshToken");
+
+    if (!canRefresh) {
+      return Promise.reject(error);
+    }
+
+    original._retry = true;
+    return refreshSession().then(
+      function (token) {
+        original.headers.Authorization = `Bearer ${token}`;
+        return api(original);
+      },
+      function (refreshError) {
+        clearSession();
+        return Promise.reject(refreshError);
+      }
+    );
+  }
+);
+
 export function search(query) {
   return api
     .get("/courses/search", {
@@ -62,11 +145,7 @@ export function login(loginRequest) {
   return api
     .post("/users/login", loginRequest)
     .then(function (loginResponse) {
-      console.log("Token: ", loginResponse.data.token);
-      const tokenType = loginResponse.data.token;
-      const tokenId = loginResponse.data.token;
-      localStorage.setItem("tokenType", tokenType);
-      localStorage.setItem("tokenId", tokenId);
+      storeSession(loginResponse.data);
       return loginResponse.data.token;
     })
     .catch(function (error) {
@@ -270,11 +349,17 @@ export function getCoursesByInstructor(instructor) {
 }
 
 export function logout() {
-  // Limpiar localStorage
-  localStorage.removeItem("tokenType");
-  localStorage.removeItem("tokenId");
-  localStorage.removeItem("userId");
+  // Revocar la sesión en el servidor; aunque falle se limpian los tokens locales
+  return api
+    .post("/users/logout")
+    .catch(function (error) {
+      console.error("Hubo un Error en el cierre de sesion:", error);
+    })
+    .finally(function () {
+      // Limpiar localStorage
+      clearSession();
 
-  // Redirigir al login
-  window.location.href = "/";
+      // Redirigir al login
+      window.location.href = "/";
+    });
 }